@@ -0,0 +1,531 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/constants"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/config"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/events"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/policy"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/repository"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/services"
+)
+
+// Exit codes of the command line, relied on by the ops runbooks
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitNotFound = 3
+	exitConflict = 4
+)
+
+const usage = `Usage: app <command> [flags]
+
+Commands:
+  serve                      start the HTTP server (default)
+  migrate                    apply, revert or create database migrations
+  user create-admin          create an admin account
+  user set-role              replace the roles of a user
+  user activate              let a deactivated user log in again
+  user deactivate            deactivate a user and revoke their sessions
+  user backfill-identities   fill canonical emails and usernames, report collisions
+  sessions purge-expired     delete sessions whose refresh token expired
+  keys rotate                re-encrypt personal data with the active master key
+  config validate            check the configuration without starting the server
+  config print               print the configuration with secrets redacted
+  i18n check                 report messages missing an Indonesian translation
+
+Every command takes --config FILE and a flag per setting (e.g. --http.port 8080)
+overriding the config file and environment. Run "app <command> -h" for the flags.
+
+Exit codes: 0 success, 1 error, 2 invalid usage, 3 not found, 4 conflict
+`
+
+// Execute runs the command given on the command line and returns the
+// process exit code
+func Execute(args []string) int {
+	if len(args) == 0 {
+		return runServe(nil)
+	}
+
+	command, rest := args[0], args[1:]
+	switch command {
+	case "serve":
+		return runServe(rest)
+	case "migrate":
+		return RunMigrate(rest)
+	case "user":
+		return runSubcommand("user", rest, map[string]func([]string) int{
+			"create-admin":        runCreateAdmin,
+			"set-role":            runSetRole,
+			"activate":            runActivate,
+			"deactivate":          runDeactivate,
+			"backfill-identities": runBackfillIdentities,
+		})
+	case "sessions":
+		return runSubcommand("sessions", rest, map[string]func([]string) int{
+			"purge-expired": runPurgeExpiredSessions,
+		})
+	case "keys":
+		return runSubcommand("keys", rest, map[string]func([]string) int{
+			"rotate": runRotateKeys,
+		})
+	case "config":
+		return runSubcommand("config", rest, map[string]func([]string) int{
+			"validate": runValidateConfig,
+			"print":    runPrintConfig,
+		})
+	case "i18n":
+		return runSubcommand("i18n", rest, map[string]func([]string) int{
+			"check": runCheckTranslations,
+		})
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return exitOK
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		return exitUsage
+	}
+}
+
+func runSubcommand(group string, args []string, commands map[string]func([]string) int) int {
+	if len(args) > 0 {
+		if run, ok := commands[args[0]]; ok {
+			return run(args[1:])
+		}
+	}
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, group+" "+name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "usage: app %s <command>, one of: %s\n", group, strings.Join(names, ", "))
+	return exitUsage
+}
+
+func runServe(args []string) int {
+	flags := newFlagSet("serve")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if !flags.setupConfig() {
+		return exitError
+	}
+
+	setupDatabase()
+	if err := ServeHTTP(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return exitError
+	}
+	return exitOK
+}
+
+func runCreateAdmin(args []string) int {
+	flags := newFlagSet("user create-admin")
+	req := dto.CreateAdminRequest{}
+	flags.IntVar(&req.TenantID, "tenant", constants.DefaultTenantID, "tenant ID")
+	flags.StringVar(&req.Username, "username", "", "username (required)")
+	flags.StringVar(&req.Email, "email", "", "email (required)")
+	flags.StringVar(&req.PhoneNumber, "phone", "", "phone number (required)")
+	flags.StringVar(&req.FullName, "name", "", "full name (required)")
+	flags.StringVar(&req.Role, "role", constants.RoleAdmin, "admin or superadmin")
+	passwordStdin := flags.Bool("password-stdin", false, "read the password from the first line of stdin")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if !flags.setupConfig() {
+		return exitError
+	}
+
+	// The password is never taken from a flag so it does not end up in the
+	// shell history or the process list
+	password, err := readPassword(*passwordStdin)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "read password:", err)
+		return exitUsage
+	}
+	req.Password = password
+
+	setupDatabase()
+	user, err := maintenanceService().CreateAdmin(context.Background(), &req)
+	if err != nil {
+		return fail(err)
+	}
+
+	fmt.Printf("created %s user %s (id %d) in tenant %d\n", user.Role, user.Username, user.ID, user.TenantID)
+	return exitOK
+}
+
+func runSetRole(args []string) int {
+	flags := newFlagSet("user set-role")
+	tenantID := flags.Int("tenant", constants.DefaultTenantID, "tenant ID")
+	login := flags.String("user", "", "user ID, email or username (required)")
+	role := flags.String("role", "", "role name (required)")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if !flags.setupConfig() {
+		return exitError
+	}
+	if *login == "" || *role == "" {
+		flags.Usage()
+		return exitUsage
+	}
+
+	setupDatabase()
+	user, err := maintenanceService().SetRole(context.Background(), *tenantID, *login, *role)
+	if err != nil {
+		return fail(err)
+	}
+
+	fmt.Printf("user %s (id %d) now has role %s\n", user.Username, user.ID, user.Role)
+	return exitOK
+}
+
+func runActivate(args []string) int {
+	flags := newFlagSet("user activate")
+	tenantID := flags.Int("tenant", constants.DefaultTenantID, "tenant ID")
+	login := flags.String("user", "", "user ID, email or username (required)")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if !flags.setupConfig() {
+		return exitError
+	}
+	if *login == "" {
+		flags.Usage()
+		return exitUsage
+	}
+
+	setupDatabase()
+	user, err := maintenanceService().ActivateUser(context.Background(), *tenantID, *login)
+	if err != nil {
+		return fail(err)
+	}
+
+	fmt.Printf("user %s (id %d) activated\n", user.Username, user.ID)
+	return exitOK
+}
+
+func runDeactivate(args []string) int {
+	flags := newFlagSet("user deactivate")
+	tenantID := flags.Int("tenant", constants.DefaultTenantID, "tenant ID")
+	login := flags.String("user", "", "user ID, email or username (required)")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if !flags.setupConfig() {
+		return exitError
+	}
+	if *login == "" {
+		flags.Usage()
+		return exitUsage
+	}
+
+	setupDatabase()
+	user, err := maintenanceService().DeactivateUser(context.Background(), *tenantID, *login)
+	if err != nil {
+		return fail(err)
+	}
+
+	fmt.Printf("user %s (id %d) deactivated, sessions revoked\n", user.Username, user.ID)
+	return exitOK
+}
+
+// runBackfillIdentities fills the canonical email and username of users from
+// before they existed. It fails while collisions are unresolved, so they are
+// not missed in a deploy log.
+func runBackfillIdentities(args []string) int {
+	flags := newFlagSet("user backfill-identities")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if !flags.setupConfig() {
+		return exitError
+	}
+
+	// The server refuses to start until this ran, so only connect
+	helpers.ConnectPostgreSQL()
+	collisions, err := helpers.BackfillCanonicalIdentities(helpers.DB)
+	if err != nil {
+		return fail(err)
+	}
+
+	for _, collision := range collisions {
+		fmt.Printf("tenant %d: %s %q of user %d collides with user %d\n",
+			collision.TenantID, collision.Field, collision.CanonicalValue, collision.UserID, collision.ConflictingUserID)
+	}
+	if len(collisions) > 0 {
+		fmt.Fprintf(os.Stderr, "%d unresolved collisions, resolve them and set identity_collisions.resolved_at\n", len(collisions))
+		return exitConflict
+	}
+	fmt.Println("every email and username has a canonical value")
+	return exitOK
+}
+
+func runPurgeExpiredSessions(args []string) int {
+	flags := newFlagSet("sessions purge-expired")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if !flags.setupConfig() {
+		return exitError
+	}
+
+	setupDatabase()
+	deleted, err := maintenanceService().PurgeExpiredSessions(context.Background())
+	if err != nil {
+		return fail(err)
+	}
+
+	fmt.Printf("deleted %d expired sessions\n", deleted)
+	return exitOK
+}
+
+func runRotateKeys(args []string) int {
+	flags := newFlagSet("keys rotate")
+	timeout := flags.Duration("timeout", 0, "stop after this long, rotation continues on the next run (0 = no limit)")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if !flags.setupConfig() {
+		return exitError
+	}
+
+	// Pending migrations are fine here, 017 waits for the blind indexes this fills
+	helpers.SetupPIIKeyring()
+	helpers.ConnectPostgreSQL()
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	keyRotationService := services.NewKeyRotationService(repository.NewKeyRotationRepository(helpers.DB))
+	result, err := keyRotationService.Rotate(ctx)
+	if result != nil {
+		fmt.Printf("re-encrypted %d users\n", result.Rotated)
+		for _, failure := range result.Failed {
+			fmt.Printf("user %d: %v\n", failure.UserID, failure.Err)
+		}
+	}
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return fail(err)
+	}
+	// Skipped users keep 017_drop_plaintext_phone_index from applying
+	if result != nil && len(result.Failed) > 0 {
+		fmt.Fprintf(os.Stderr, "%d users could not be re-encrypted, fix them (e.g. list the retired key in PII_MASTER_KEYS again) and run keys rotate again\n", len(result.Failed))
+		return exitError
+	}
+	return exitOK
+}
+
+func runValidateConfig(args []string) int {
+	flags := newFlagSet("config validate")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	cfg, err := helpers.LoadConfig(flags.configFile, flags.overrides)
+	if err != nil {
+		return printConfigErrors(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return printConfigErrors(err)
+	}
+
+	helpers.Config = cfg
+	var errs []error
+	if _, err := helpers.LoadPIIKeyring(); err != nil {
+		errs = append(errs, fmt.Errorf("pii: %w", err))
+	}
+	if _, err := policy.LoadFile(cfg.App.PolicyFile); err != nil {
+		errs = append(errs, fmt.Errorf("app.policy_file (POLICY_FILE): %w", err))
+	}
+	if len(errs) > 0 {
+		return printConfigErrors(errors.Join(errs...))
+	}
+
+	fmt.Println("configuration is valid")
+	return exitOK
+}
+
+func runPrintConfig(args []string) int {
+	flags := newFlagSet("config print")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	cfg, err := helpers.LoadConfig(flags.configFile, flags.overrides)
+	if err != nil {
+		return printConfigErrors(err)
+	}
+	cfg.Print(os.Stdout)
+	return exitOK
+}
+
+// runCheckTranslations scans the source for messages and reports those
+// without a translation, so CI fails before an untranslated message ships
+func runCheckTranslations(args []string) int {
+	flags := flag.NewFlagSet("i18n check", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "source directory to scan")
+	unused := flags.Bool("unused", false, "also list translations no message uses anymore")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	result, err := i18n.Scan(*dir)
+	if err != nil {
+		return fail(err)
+	}
+
+	failed := false
+	for _, problem := range i18n.Verify() {
+		fmt.Println(problem)
+		failed = true
+	}
+	for _, locale := range i18n.Supported[1:] {
+		for _, message := range result.Missing(locale) {
+			kind := "message"
+			if message.Field {
+				kind = "field label"
+			}
+			fmt.Printf("%s: %s: missing %s %q\n", message.Position, locale, kind, message.Key)
+			failed = true
+		}
+		if *unused {
+			for _, key := range result.Unused(locale) {
+				fmt.Printf("%s: unused translation %q\n", locale, key)
+			}
+		}
+	}
+	for _, position := range result.Dynamic {
+		fmt.Printf("%s: warning: message built at runtime cannot be translated\n", position)
+	}
+
+	if failed {
+		return exitError
+	}
+	fmt.Printf("All %d messages are translated into %s\n", len(result.Messages), joinLocales(i18n.Supported[1:]))
+	return exitOK
+}
+
+// commandFlags is a flag set that also takes --config and a flag for every
+// configuration setting, such as --http.port
+type commandFlags struct {
+	*flag.FlagSet
+	configFile string
+	overrides  map[string]string
+}
+
+func newFlagSet(name string) *commandFlags {
+	flags := &commandFlags{
+		FlagSet:   flag.NewFlagSet(name, flag.ContinueOnError),
+		overrides: map[string]string{},
+	}
+	flags.StringVar(&flags.configFile, "config", "", "YAML config file (default $CONFIG_FILE)")
+	config.RegisterFlags(flags.FlagSet, flags.overrides)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: app %s [flags]\n", name)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// setupConfig activates the configuration and reports invalid settings
+func (f *commandFlags) setupConfig() bool {
+	if err := helpers.SetupConfig(f.configFile, f.overrides); err != nil {
+		printConfigErrors(err)
+		return false
+	}
+	return true
+}
+
+func printConfigErrors(err error) int {
+	for _, line := range strings.Split(err.Error(), "\n") {
+		fmt.Fprintln(os.Stderr, "invalid:", line)
+	}
+	return exitError
+}
+
+// setupDatabase prepares everything commands touching user data need
+func setupDatabase() {
+	helpers.SetupPIIKeyring()
+	helpers.SetupPostgreSQL()
+}
+
+func maintenanceService() interfaces.IMaintenanceService {
+	authRepo := repository.NewAuthRepository(helpers.DB)
+	return services.NewMaintenanceService(
+		authRepo,
+		repository.NewTenantRepository(helpers.DB),
+		services.NewUserLifecycleService(
+			authRepo,
+			repository.NewRBACRepository(helpers.DB),
+			repository.NewAccountDeletionRepository(helpers.DB),
+			events.NewLogPublisher(helpers.Logger),
+		),
+	)
+}
+
+func readPassword(fromStdin bool) (string, error) {
+	if !fromStdin {
+		fmt.Fprint(os.Stderr, "Password: ")
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// fail prints err and maps it to an exit code
+func fail(err error) int {
+	var appErr *helpers.AppError
+	if !errors.As(err, &appErr) {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return exitError
+	}
+
+	// The operator may see the cause, API clients only get the message
+	if appErr.Cause != nil {
+		fmt.Fprintf(os.Stderr, "error: %s: %v\n", appErr.Message, appErr.Cause)
+	} else if appErr.Details != "" {
+		fmt.Fprintf(os.Stderr, "error: %s: %s\n", appErr.Message, appErr.Details)
+	} else {
+		fmt.Fprintln(os.Stderr, "error:", appErr.Message)
+	}
+	switch appErr.Code {
+	case http.StatusBadRequest:
+		return exitUsage
+	case http.StatusNotFound:
+		return exitNotFound
+	case http.StatusConflict:
+		return exitConflict
+	default:
+		return exitError
+	}
+}
+
+func joinLocales(locales []i18n.Locale) string {
+	names := make([]string, len(locales))
+	for i, locale := range locales {
+		names[i] = string(locale)
+	}
+	return strings.Join(names, ", ")
+}
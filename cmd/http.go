@@ -1,12 +1,29 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/constants"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/api"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/events"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/mailer"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/metrics"
 	appMiddleware "github.com/ibnuzaman/auth-simple-ecommerce.git/internal/middleware"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/policy"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/repository"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/server"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/services"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/sms"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/storage"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/worker"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/sirupsen/logrus"
@@ -15,82 +32,363 @@ import (
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
-func ServeHTTP() {
+// ServeHTTP serves the API until SIGINT or SIGTERM, then drains in-flight
+// requests and background jobs before closing the database pool
+func ServeHTTP() error {
+	shutdownTracing, err := helpers.SetupTracing()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			helpers.Logger.WithError(err).Warn("Failed to flush trace spans")
+		}
+	}()
+
+	// Broken translations only degrade messages, the check command fails on them
+	for _, problem := range i18n.Verify() {
+		helpers.Logger.WithField("problem", problem).Warn("Invalid translation")
+	}
+
 	var dependency = dependencyIjection()
 
 	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
 
 	// Custom error handler
 	e.HTTPErrorHandler = appMiddleware.ErrorHandler
 
 	// Middleware
-	e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
-		Format: "method=${method}, uri=${uri}, status=${status}\n",
-	}))
+	e.Use(appMiddleware.RequestID())
+	e.Use(appMiddleware.Locale())
+	e.Use(appMiddleware.Tracing(helpers.Config.Tracing.ServiceName))
+	e.Use(appMiddleware.AccessLog())
+	e.Use(appMiddleware.Metrics())
 	e.Use(middleware.Recover())
+	e.Use(middleware.BodyLimit(helpers.Config.HTTP.BodyLimit))
 	e.Use(middleware.CORS())
 
+	// Docs and health probes skip the tenant middleware, which needs the
+	// database, so liveness keeps passing while Postgres is down
 	api := e.Group("/api")
 	api.GET("/swagger/*", echoSwagger.WrapHandler)
 
 	// Healthcheck
 	api.GET("/health", dependency.HealthcheckAPI.HealthCheck)
+	api.GET("/health/live", dependency.HealthcheckAPI.Live)
+	api.GET("/health/ready", dependency.HealthcheckAPI.Ready)
+
+	// Every API route belongs to a tenant
+	v1 := e.Group("/api/v1")
+	v1.Use(appMiddleware.TenantMiddleware(dependency.TenantService))
+
+	// Retried by mobile clients, an Idempotency-Key replays the first response
+	idempotent := appMiddleware.Idempotency(dependency.Idempotency)
 
 	// Auth routes (public)
-	auth := api.Group("/v1/auth")
-	auth.POST("/register", dependency.AuthAPI.Register)
+	auth := v1.Group("/auth")
+	auth.POST("/register", dependency.AuthAPI.Register, idempotent)
 	auth.POST("/login", dependency.AuthAPI.Login)
 	auth.POST("/refresh", dependency.AuthAPI.RefreshToken)
-	auth.POST("/forgot-password", dependency.AuthAPI.ForgotPassword)
-	auth.POST("/reset-password", dependency.AuthAPI.ResetPassword)
+	auth.POST("/forgot-password", dependency.AuthAPI.ForgotPassword, idempotent)
+	auth.POST("/reset-password", dependency.AuthAPI.ResetPassword, idempotent)
+	auth.POST("/email-change/confirm", dependency.EmailChangeAPI.ConfirmChange)
+	auth.POST("/email-change/cancel", dependency.EmailChangeAPI.CancelChange)
 
 	// Auth routes (protected)
-	authProtected := api.Group("/v1/auth")
+	authProtected := v1.Group("/auth")
 	authProtected.Use(appMiddleware.JWTMiddleware())
 	authProtected.POST("/logout", dependency.AuthAPI.Logout)
-	authProtected.POST("/change-password", dependency.AuthAPI.ChangePassword)
+	authProtected.POST("/change-password", dependency.AuthAPI.ChangePassword, idempotent)
 	authProtected.GET("/profile", dependency.AuthAPI.GetProfile)
+	authProtected.PATCH("/profile", dependency.AuthAPI.UpdateProfile)
+	authProtected.POST("/email-change", dependency.EmailChangeAPI.RequestChange, idempotent)
+	authProtected.POST("/phone-change", dependency.PhoneChangeAPI.RequestChange, idempotent)
+	authProtected.POST("/phone-change/verify", dependency.PhoneChangeAPI.VerifyChange)
+	authProtected.DELETE("/account", dependency.AccountAPI.DeleteAccount)
+	authProtected.GET("/consents", dependency.ConsentAPI.GetConsents)
+	authProtected.POST("/consents", dependency.ConsentAPI.UpdateConsents)
+
+	// Legal documents (public)
+	v1.GET("/legal/documents", dependency.ConsentAPI.ListDocuments)
+
+	// Seller onboarding routes (protected)
+	seller := v1.Group("/seller")
+	seller.Use(appMiddleware.JWTMiddleware())
+	seller.POST("/applications", dependency.SellerAPI.Apply)
+	seller.GET("/applications/me", dependency.SellerAPI.GetMyApplication)
+
+	// Seller review routes (protected)
+	sellerReview := v1.Group("/admin/seller-applications")
+	sellerReview.Use(appMiddleware.JWTMiddleware())
+	sellerReview.Use(appMiddleware.RequirePermission(constants.PermSellersReview))
+	sellerReview.GET("", dependency.SellerAPI.ListApplications)
+	sellerReview.GET("/:id", dependency.SellerAPI.GetApplication)
+	sellerReview.POST("/:id/approve", dependency.SellerAPI.Approve)
+	sellerReview.POST("/:id/reject", dependency.SellerAPI.Reject)
+	sellerReview.GET("/:id/documents/:documentId", dependency.SellerAPI.GetDocument)
+
+	// Legal document admin routes (protected)
+	legalAdmin := v1.Group("/admin/legal")
+	legalAdmin.Use(appMiddleware.JWTMiddleware())
+	legalAdmin.Use(appMiddleware.RequirePermission(constants.PermLegalManage))
+	legalAdmin.POST("/documents", dependency.ConsentAPI.PublishDocument)
 
-	// User routes (protected) - example
-	users := api.Group("/v1/users")
+	// Consent export routes for CRM sync (protected)
+	consentAdmin := v1.Group("/admin/consents")
+	consentAdmin.Use(appMiddleware.JWTMiddleware())
+	consentAdmin.Use(appMiddleware.RequirePermission(constants.PermConsentsRead))
+	consentAdmin.GET("/marketing", dependency.ConsentAPI.ListMarketingConsents)
+
+	// Admin routes (protected)
+	admin := v1.Group("/admin")
+	admin.Use(appMiddleware.JWTMiddleware())
+	admin.Use(appMiddleware.RequirePermission(constants.PermRolesManage))
+	admin.Use(appMiddleware.Authorize(dependency.AuthzService, "admin:manage_roles", "role", appMiddleware.PathParams))
+	admin.GET("/roles", dependency.RBACAPI.ListRoles)
+	admin.PUT("/roles/:role/permissions", dependency.RBACAPI.SetRolePermissions)
+	admin.POST("/roles/:role/permissions", dependency.RBACAPI.GrantPermission)
+	admin.DELETE("/roles/:role/permissions/:permission", dependency.RBACAPI.RevokePermission)
+	admin.GET("/permissions", dependency.RBACAPI.ListPermissions)
+	admin.POST("/permissions", dependency.RBACAPI.CreatePermission)
+	admin.GET("/users/:id/roles", dependency.RBACAPI.GetUserRoles)
+	admin.POST("/users/:id/roles", dependency.RBACAPI.AssignUserRole)
+	admin.DELETE("/users/:id/roles/:role", dependency.RBACAPI.RevokeUserRole)
+
+	// User administration routes (protected)
+	userAdmin := v1.Group("/admin/users")
+	userAdmin.Use(appMiddleware.JWTMiddleware())
+	userAdmin.Use(appMiddleware.RequirePermission(constants.PermUsersWrite))
+	userAdmin.POST("/:id/activate", dependency.UserAdminAPI.Activate)
+	userAdmin.POST("/:id/deactivate", dependency.UserAdminAPI.Deactivate)
+
+	// Authorization policy routes (protected)
+	authz := v1.Group("/authz")
+	authz.Use(appMiddleware.JWTMiddleware())
+	authz.POST("/check", dependency.AuthzAPI.Check)
+
+	// User routes (protected)
+	users := v1.Group("/users")
 	users.Use(appMiddleware.JWTMiddleware())
-	// Add user routes here if needed
+	users.GET("/me/addresses", dependency.AddressAPI.ListAddresses)
+	users.POST("/me/addresses", dependency.AddressAPI.CreateAddress)
+	users.GET("/me/addresses/:id", dependency.AddressAPI.GetAddress)
+	users.PATCH("/me/addresses/:id", dependency.AddressAPI.UpdateAddress)
+	users.DELETE("/me/addresses/:id", dependency.AddressAPI.DeleteAddress)
+	users.POST("/me/exports", dependency.DataExportAPI.RequestExport)
+	users.GET("/me/exports", dependency.DataExportAPI.ListExports)
+
+	// Data export download (signed link)
+	v1.GET("/exports/:id/download", dependency.DataExportAPI.Download)
 
-	if err := e.Start(":" + helpers.GetEnv("PORT", "9000")); err != nil {
-		logrus.Info("Failed to connect app", err)
-		return
+	// Metrics
+	if sqlDB, err := helpers.DB.DB(); err == nil {
+		metrics.RegisterDBStats(sqlDB, helpers.Config.Database.Name)
+	}
+	servers := []*server.Server{server.New(helpers.Config.HTTP, e, dependency.Workers, helpers.Logger)}
+	if helpers.Config.HTTP.AdminPort == 0 {
+		helpers.Logger.Warn("Serving /metrics on the public port, set ADMIN_PORT in production")
+		e.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+	} else {
+		servers = append(servers, adminServer(dependency))
+	}
+	servers[0].OnShutdown(dependency.HealthService.SetShuttingDown)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// A server failing to start stops the other one
+	errs := make(chan error, len(servers))
+	for _, srv := range servers {
+		go func(srv *server.Server) {
+			errs <- srv.Run(ctx)
+		}(srv)
+	}
+	for range servers {
+		if runErr := <-errs; runErr != nil && err == nil {
+			err = runErr
+			stop()
+		}
 	}
 
+	helpers.ClosePostgreSQL()
+	return err
+}
+
+// adminServer serves metrics and health probes on the admin port, which is
+// kept off the public load balancer
+func adminServer(dependency Dependency) *server.Server {
+	admin := echo.New()
+	admin.HideBanner = true
+	admin.HidePort = true
+	admin.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+	admin.GET("/health/live", dependency.HealthcheckAPI.Live)
+	admin.GET("/health/ready", dependency.HealthcheckAPI.Ready)
+
+	cfg := helpers.Config.HTTP
+	cfg.Port = cfg.AdminPort
+	cfg.TLSCertFile, cfg.TLSKeyFile = "", ""
+	return server.New(cfg, admin, nil, helpers.Logger)
 }
 
 type Dependency struct {
 	HealthcheckAPI *api.HealthCheckAPI
+	HealthService  interfaces.IHealthService
 	AuthAPI        *api.AuthHandler
-	UserAPI        interfaces.IUserAPI
+	RBACAPI        *api.RBACHandler
+	AuthzAPI       *api.AuthzHandler
+	AuthzService   interfaces.IAuthzService
+	TenantService  interfaces.ITenantService
+	Idempotency    interfaces.IIdempotencyService
+	SellerAPI      *api.SellerHandler
+	AddressAPI     *api.AddressHandler
+	EmailChangeAPI *api.EmailChangeHandler
+	PhoneChangeAPI *api.PhoneChangeHandler
+	AccountAPI     *api.AccountDeletionHandler
+	DataExportAPI  *api.DataExportHandler
+	ConsentAPI     *api.ConsentHandler
+	UserAdminAPI   *api.UserAdminHandler
+	Workers        []*worker.Worker
 }
 
 func dependencyIjection() Dependency {
-	// Auth dependencies
+	// Tenant dependencies
+	tenantRepo := repository.NewTenantRepository(helpers.DB)
+	tenantService := services.NewTenantService(tenantRepo)
+
+	// User lifecycle dependencies, every account change goes through them
 	authRepo := repository.NewAuthRepository(helpers.DB)
-	authService := services.NewAuthService(authRepo)
+	rbacRepo := repository.NewRBACRepository(helpers.DB)
+	accountDeletionRepo := repository.NewAccountDeletionRepository(helpers.DB)
+	eventPublisher := events.NewLogPublisher(helpers.Logger)
+	userLifecycle := services.NewUserLifecycleService(authRepo, rbacRepo, accountDeletionRepo, eventPublisher)
+	userAdminAPI := api.NewUserAdminHandler(userLifecycle)
+
+	// Auth dependencies
+	consentRepo := repository.NewConsentRepository(helpers.DB)
+	authService := services.NewTracedAuthService(services.NewAuthService(authRepo, rbacRepo, consentRepo, userLifecycle))
 	authAPI := api.NewAuthHandler(authService)
 
-	// User dependencies
-	userRepo := &repository.UserRepository{
-		DB: helpers.DB,
+	// RBAC dependencies
+	rbacService := services.NewRBACService(rbacRepo, userLifecycle)
+	rbacAPI := api.NewRBACHandler(rbacService)
+
+	// Authorization policy dependencies
+	policyEngine, err := policy.LoadFile(helpers.Config.App.PolicyFile)
+	if err != nil {
+		logrus.Fatal("Failed to load authorization policies: ", err)
 	}
+	authzService := services.NewAuthzService(policyEngine)
+	authzAPI := api.NewAuthzHandler(authzService)
 
-	userService := &services.UserService{
-		UserRepo: userRepo,
+	// Seller onboarding dependencies
+	blobStore, err := storage.NewLocalStore(helpers.Config.Storage.BlobDir)
+	if err != nil {
+		logrus.Fatal("Failed to setup blob storage: ", err)
 	}
+	sellerRepo := repository.NewSellerRepository(helpers.DB)
+	sellerService := services.NewSellerService(sellerRepo, rbacRepo, userLifecycle, blobStore, eventPublisher)
+	sellerAPI := api.NewSellerHandler(sellerService)
+
+	// Address book dependencies
+	addressRepo := repository.NewAddressRepository(helpers.DB)
+	addressService := services.NewAddressService(addressRepo)
+	addressAPI := api.NewAddressHandler(addressService)
 
-	userAPI := &api.UserAPI{
-		UserService: *userService,
+	// Notification dependencies
+	var emailSender interfaces.IMailer = mailer.NewLogMailer(helpers.Logger)
+	if smtp := helpers.Config.SMTP; smtp.Host != "" {
+		emailSender = mailer.NewSMTPMailer(smtp.Host, strconv.Itoa(smtp.Port), smtp.Username, smtp.Password, smtp.From)
 	}
 
+	// Email change dependencies
+	emailChangeRepo := repository.NewEmailChangeRepository(helpers.DB)
+	emailChangeService := services.NewEmailChangeService(authRepo, emailChangeRepo, emailSender)
+	emailChangeAPI := api.NewEmailChangeHandler(emailChangeService)
+
+	// Phone change dependencies
+	smsSender := sms.NewLogSender(helpers.Logger)
+	phoneChangeRepo := repository.NewPhoneChangeRepository(helpers.DB)
+	phoneChangeService := services.NewPhoneChangeService(authRepo, phoneChangeRepo, smsSender)
+	phoneChangeAPI := api.NewPhoneChangeHandler(phoneChangeService)
+
+	// Account deletion dependencies
+	accountDeletionService := services.NewAccountDeletionService(authRepo, accountDeletionRepo, userLifecycle, emailSender, helpers.Config.Jobs.AccountDeletionGracePeriod)
+	accountDeletionAPI := api.NewAccountDeletionHandler(accountDeletionService)
+
+	// Data export dependencies
+	dataExportRepo := repository.NewDataExportRepository(helpers.DB)
+	dataExportService := services.NewDataExportService(dataExportRepo, blobStore, emailSender)
+	dataExportAPI := api.NewDataExportHandler(dataExportService)
+
+	// PII key rotation dependencies
+	keyRotationRepo := repository.NewKeyRotationRepository(helpers.DB)
+	keyRotationService := services.NewKeyRotationService(keyRotationRepo)
+
+	// Idempotency key dependencies
+	idempotencyRepo := repository.NewIdempotencyRepository(helpers.DB)
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo, helpers.Config.HTTP.IdempotencyTTL, helpers.Config.HTTP.WriteTimeout)
+
+	// Session metrics dependencies
+	sessionMetricsService := services.NewSessionMetricsService(authRepo)
+
+	// Background workers
+	workers := []*worker.Worker{
+		worker.New("account_deletion", helpers.Config.Jobs.AccountDeletionInterval, accountDeletionService.PurgeDue, helpers.Logger),
+		worker.New("data_export", helpers.Config.Jobs.DataExportInterval, dataExportService.ProcessPending, helpers.Logger),
+		worker.New("data_export_cleanup", time.Hour, dataExportService.PurgeExpired, helpers.Logger),
+		worker.New("seller_document_cleanup", time.Hour, sellerService.PurgeDeletedDocuments, helpers.Logger),
+		worker.New("idempotency_cleanup", time.Hour, idempotencyService.PurgeExpired, helpers.Logger),
+		worker.New("pii_key_rotation", helpers.Config.PII.RotationInterval, keyRotationService.RotateAll, helpers.Logger),
+		worker.New("session_metrics", time.Minute, sessionMetricsService.Collect, helpers.Logger),
+	}
+
+	// Health check dependencies
+	migrator, err := helpers.NewMigrator()
+	if err != nil {
+		logrus.Fatal("Failed to load migrations: ", err)
+	}
+	healthRepo := repository.NewHealthRepository(helpers.DB)
+	healthService := services.NewHealthService(helpers.Config.HTTP.ReadinessTimeout,
+		services.HealthCheck{Name: "database", Check: healthRepo.Ping},
+		services.HealthCheck{Name: "migrations", Check: func(ctx context.Context) error {
+			pending, err := migrator.Pending(ctx)
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				return fmt.Errorf("%d migrations pending", len(pending))
+			}
+			return nil
+		}},
+		services.HealthCheck{Name: "mailer", Check: emailSender.Ping, Optional: true},
+	)
+	healthCheckAPI := api.NewHealthCheckAPI(healthService)
+
+	// Consent dependencies
+	consentService := services.NewConsentService(consentRepo)
+	consentAPI := api.NewConsentHandler(consentService)
+
 	return Dependency{
-		HealthcheckAPI: &api.HealthCheckAPI{},
+		HealthcheckAPI: healthCheckAPI,
+		HealthService:  healthService,
 		AuthAPI:        authAPI,
-		UserAPI:        userAPI,
+		RBACAPI:        rbacAPI,
+		AuthzAPI:       authzAPI,
+		AuthzService:   authzService,
+		TenantService:  tenantService,
+		Idempotency:    idempotencyService,
+		SellerAPI:      sellerAPI,
+		AddressAPI:     addressAPI,
+		EmailChangeAPI: emailChangeAPI,
+		PhoneChangeAPI: phoneChangeAPI,
+		AccountAPI:     accountDeletionAPI,
+		DataExportAPI:  dataExportAPI,
+		ConsentAPI:     consentAPI,
+		UserAdminAPI:   userAdminAPI,
+		Workers:        workers,
 	}
 }
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/migrator"
+)
+
+const migrateUsage = `Usage: app migrate <command>
+
+Commands:
+  up [N]         apply all pending migrations, or the next N
+  down [N]       revert the last N migrations (default 1)
+  status         list migrations and when they were applied
+  create NAME    create empty up/down files for a new migration
+`
+
+// RunMigrate runs the migrate subcommand and returns the process exit code
+func RunMigrate(args []string) int {
+	flags := newFlagSet("migrate")
+	dir := flags.String("dir", "migrations", "directory new migrations are created in")
+	flags.Usage = func() { fmt.Fprint(os.Stderr, migrateUsage) }
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if !flags.setupConfig() {
+		return exitError
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	command, rest := flags.Arg(0), flags.Args()[1:]
+	if command == "create" {
+		if len(rest) != 1 {
+			flags.Usage()
+			return exitUsage
+		}
+		paths, err := migrator.Create(*dir, rest[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "create migration:", err)
+			return exitError
+		}
+		for _, path := range paths {
+			fmt.Println("created", path)
+		}
+		return exitOK
+	}
+
+	steps := 0
+	if command == "down" {
+		steps = 1
+	}
+	if len(rest) > 0 {
+		n, err := strconv.Atoi(rest[0])
+		if err != nil || n < 1 {
+			fmt.Fprintln(os.Stderr, "steps must be a positive number")
+			return exitUsage
+		}
+		steps = n
+	}
+
+	helpers.ConnectPostgreSQL()
+	m, err := helpers.NewMigrator()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "load migrations:", err)
+		return exitError
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		applied, err := m.Up(ctx, steps)
+		for _, migration := range applied {
+			fmt.Printf("applied  %03d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		reverted, err := m.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %03d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%03d_%-40s %s\n", status.Version, status.Name, state)
+		}
+	default:
+		flags.Usage()
+		return exitUsage
+	}
+	return exitOK
+}
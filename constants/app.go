@@ -1,9 +1,16 @@
 package constants
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 var (
-	RoleCustomer = "Customer"
+	RoleCustomer   = "customer"
+	RoleSeller     = "seller"
+	RoleSupport    = "support"
+	RoleAdmin      = "admin"
+	RoleSuperAdmin = "superadmin"
 )
 var (
 	// ErrServerError      = errors.New("internal server error")
@@ -13,9 +20,20 @@ var (
 	UniqueViolation = "23505"
 )
 
+// ConflictError lists every field of a unique constraint violation, in the
+// order of UniqueUserFields
 type ConflictError struct {
-	Field string // "email" / "username" / "phone_number"
+	Fields []string // "email" / "username" / "phone_number"
 }
 
-func (e *ConflictError) Error() string        { return "conflict on " + e.Field }
+// UniqueUserFields are the user fields that are unique within a tenant
+var UniqueUserFields = []string{"email", "username", "phone_number"}
+
+func (e *ConflictError) Error() string        { return "conflict on " + strings.Join(e.Fields, ", ") }
 func (e *ConflictError) Is(target error) bool { return target == ErrConflict }
+
+var (
+	DefaultTenantID   = 1
+	DefaultTenantSlug = "default"
+	TenantHeader      = "X-Tenant-ID"
+)
@@ -0,0 +1,15 @@
+package constants
+
+// Event types published through the event publisher
+const (
+	EventSellerApproved        = "seller.approved"
+	EventSellerRejected        = "seller.rejected"
+	EventUserCreated           = "user.created"
+	EventUserActivated         = "user.activated"
+	EventUserDeactivated       = "user.deactivated"
+	EventUserRoleAssigned      = "user.role_assigned"
+	EventUserRoleRevoked       = "user.role_revoked"
+	EventUserDeletionScheduled = "user.deletion_scheduled"
+	EventUserDeletionCancelled = "user.deletion_cancelled"
+	EventUserDeleted           = "user.deleted"
+)
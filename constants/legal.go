@@ -0,0 +1,17 @@
+package constants
+
+// Legal document types users must accept
+const (
+	DocumentTermsOfService = "terms_of_service"
+	DocumentPrivacyPolicy  = "privacy_policy"
+)
+
+// RequiredDocuments are the documents every user must have accepted in their
+// current version
+var RequiredDocuments = []string{DocumentTermsOfService, DocumentPrivacyPolicy}
+
+// Optional consent types
+const (
+	ConsentMarketingEmail = "marketing_email"
+	ConsentMarketingSMS   = "marketing_sms"
+)
@@ -0,0 +1,32 @@
+package constants
+
+// Permission names follow the "resource:action" convention. A trailing "*"
+// acts as a wildcard, e.g. "orders:*" grants every orders permission and
+// "*" grants everything.
+const (
+	PermissionAll = "*"
+
+	PermProfileRead  = "profile:read"
+	PermProfileWrite = "profile:write"
+
+	PermUsersRead   = "users:read"
+	PermUsersWrite  = "users:write"
+	PermUsersDelete = "users:delete"
+
+	PermRolesManage = "roles:manage"
+
+	PermOrdersRead   = "orders:read"
+	PermOrdersWrite  = "orders:write"
+	PermOrdersRefund = "orders:refund"
+
+	PermStoresManage  = "stores:manage"
+	PermProductsWrite = "products:write"
+
+	PermPIIRead   = "pii:read"
+	PermPIIExport = "pii:export"
+
+	PermSellersReview = "sellers:review"
+
+	PermLegalManage  = "legal:manage"
+	PermConsentsRead = "consents:read"
+)
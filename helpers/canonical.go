@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	caseFolder = cases.Fold()
+
+	// stripMarks removes accents, so "búdi" and "budi" share a skeleton
+	stripMarks = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+	// confusables maps the digits that read as letters in usernames, which
+	// only allow ASCII letters, digits and underscores
+	confusables = strings.NewReplacer("0", "o", "1", "l")
+)
+
+// CanonicalEmail returns the form used for email uniqueness and lookups:
+// Unicode NFKC, case folded and trimmed
+func CanonicalEmail(email string) string {
+	return caseFolder.String(norm.NFKC.String(strings.TrimSpace(email)))
+}
+
+// CanonicalUsername returns the form used for username uniqueness and lookups.
+// On top of NFKC and case folding, accents are removed and the digits 0 and 1
+// are read as o and l, so "bob", "BOB" and "b0b" collide while "ali" and "all"
+// do not. The result is only used for comparison, never displayed.
+func CanonicalUsername(username string) string {
+	s := caseFolder.String(norm.NFKC.String(strings.TrimSpace(username)))
+	if stripped, _, err := transform.String(stripMarks, s); err == nil {
+		s = stripped
+	}
+	return confusables.Replace(s)
+}
@@ -0,0 +1,67 @@
+package helpers
+
+import "testing"
+
+func TestCanonicalEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"budi@example.com", "budi@example.com"},
+		{"  Budi@Example.COM ", "budi@example.com"},
+		{"STRASSE@example.com", "strasse@example.com"},
+		{"straße@example.com", "strasse@example.com"},
+		// Fullwidth letters are folded by NFKC
+		{"ｂｕｄｉ@example.com", "budi@example.com"},
+		// Emails keep accents and digits, only usernames use skeletons
+		{"búdi0@example.com", "búdi0@example.com"},
+	}
+	for _, tt := range tests {
+		if got := CanonicalEmail(tt.email); got != tt.want {
+			t.Errorf("CanonicalEmail(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalUsernameCollisions(t *testing.T) {
+	tests := []struct {
+		name      string
+		usernames []string
+	}{
+		{"case", []string{"admin", "ADMIN", "Admin"}},
+		{"one", []string{"all", "a11", "al1"}},
+		{"zero", []string{"bob", "b0b"}},
+		{"accents", []string{"budi", "búdi", "BÚDI"}},
+		{"fullwidth", []string{"budi", "ｂｕｄｉ"}},
+		{"whitespace", []string{"budi", " budi "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := CanonicalUsername(tt.usernames[0])
+			for _, username := range tt.usernames[1:] {
+				if got := CanonicalUsername(username); got != want {
+					t.Errorf("CanonicalUsername(%q) = %q, want %q like %q", username, got, want, tt.usernames[0])
+				}
+			}
+		})
+	}
+}
+
+func TestCanonicalUsernameKeepsDistinctNames(t *testing.T) {
+	distinct := [][2]string{
+		{"budi", "badi"},
+		{"admin", "admin2"},
+		{"seller_1", "seller-1"},
+		{"andi", "andy"},
+		// Only 0/o and 1/l are folded, letters that merely look alike stay apart
+		{"ali", "all"},
+		{"admin", "admln"},
+		{"bernard", "bemard"},
+		{"vvendy", "wendy"},
+	}
+	for _, pair := range distinct {
+		if CanonicalUsername(pair[0]) == CanonicalUsername(pair[1]) {
+			t.Errorf("%q and %q collide as %q", pair[0], pair[1], CanonicalUsername(pair[0]))
+		}
+	}
+}
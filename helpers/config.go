@@ -1,27 +1,41 @@
 package helpers
 
 import (
-	"log"
-
-	"github.com/joho/godotenv"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/config"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
 )
 
-var Env = map[string]string{}
-
-func SetupUpConfig() {
-	var err error
+// Config is the active configuration, replaced by SetupConfig
+var Config = config.Default()
 
-	Env, err = godotenv.Read(".env")
+// LoadConfig reads the configuration from the defaults, the YAML file (file,
+// or CONFIG_FILE when empty), the environment including an optional .env
+// file, and flag overrides
+func LoadConfig(file string, overrides map[string]string) (*config.Config, error) {
+	env, err := config.Environment(".env")
 	if err != nil {
-		log.Fatal("failed to read .env", err)
+		return nil, err
+	}
+	if file == "" {
+		file = env["CONFIG_FILE"]
 	}
+	return config.Load(config.Options{File: file, Env: env, Overrides: overrides})
 }
 
-func GetEnv(key, value string) string {
-	result := Env[key]
-	if result != "" {
-		result = value
+// SetupConfig loads and validates the configuration and makes it active
+func SetupConfig(file string, overrides map[string]string) error {
+	cfg, err := LoadConfig(file, overrides)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
 	}
 
-	return result
+	Config = cfg
+	ConfigureLogger(cfg.Log)
+	if locale, ok := i18n.Parse(cfg.App.DefaultLocale); ok {
+		i18n.SetDefault(locale)
+	}
+	return nil
 }
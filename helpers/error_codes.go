@@ -0,0 +1,135 @@
+package helpers
+
+import "net/http"
+
+// ErrorCode is a stable, machine-readable error identifier. Clients switch
+// on codes instead of messages, so a released code is never renamed or
+// reused for another error.
+type ErrorCode string
+
+// Generic codes, used when no specific code applies
+const (
+	CodeBadRequest       ErrorCode = "BAD_REQUEST"
+	CodeValidation       ErrorCode = "VALIDATION_FAILED"
+	CodeUnauthorized     ErrorCode = "UNAUTHORIZED"
+	CodeForbidden        ErrorCode = "FORBIDDEN"
+	CodeNotFound         ErrorCode = "NOT_FOUND"
+	CodeMethodNotAllowed ErrorCode = "METHOD_NOT_ALLOWED"
+	CodeConflict         ErrorCode = "CONFLICT"
+	CodePayloadTooLarge  ErrorCode = "PAYLOAD_TOO_LARGE"
+	CodeTooManyRequests  ErrorCode = "TOO_MANY_REQUESTS"
+	CodeInternal         ErrorCode = "INTERNAL_ERROR"
+	CodeUnavailable      ErrorCode = "SERVICE_UNAVAILABLE"
+)
+
+// Authentication
+const (
+	CodeAuthInvalidCredentials  ErrorCode = "AUTH_INVALID_CREDENTIALS"
+	CodeAuthAccountInactive     ErrorCode = "AUTH_ACCOUNT_INACTIVE"
+	CodeAuthTokenMissing        ErrorCode = "AUTH_TOKEN_MISSING"
+	CodeAuthTokenInvalid        ErrorCode = "AUTH_TOKEN_INVALID"
+	CodeAuthRefreshTokenInvalid ErrorCode = "AUTH_REFRESH_TOKEN_INVALID"
+	CodeAuthRefreshTokenExpired ErrorCode = "AUTH_REFRESH_TOKEN_EXPIRED"
+	CodeAuthPermissionDenied    ErrorCode = "AUTH_PERMISSION_DENIED"
+	CodeAuthPasswordIncorrect   ErrorCode = "AUTH_PASSWORD_INCORRECT"
+	CodeAuthPasswordPolicy      ErrorCode = "AUTH_PASSWORD_POLICY"
+	CodeAuthResetTokenInvalid   ErrorCode = "AUTH_RESET_TOKEN_INVALID"
+	CodeAuthOTPInvalid          ErrorCode = "AUTH_OTP_INVALID"
+	CodeAuthOTPRateLimited      ErrorCode = "AUTH_OTP_RATE_LIMITED"
+	CodeAuthLinkInvalid         ErrorCode = "AUTH_LINK_INVALID"
+)
+
+// Users and tenants
+const (
+	CodeUserNotFound        ErrorCode = "USER_NOT_FOUND"
+	CodeUserEmailTaken      ErrorCode = "USER_EMAIL_TAKEN"
+	CodeUserUsernameTaken   ErrorCode = "USER_USERNAME_TAKEN"
+	CodeUserPhoneTaken      ErrorCode = "USER_PHONE_TAKEN"
+	CodeUserConsentRequired ErrorCode = "USER_CONSENT_REQUIRED"
+	CodeTenantUnknown       ErrorCode = "TENANT_UNKNOWN"
+	CodeTenantDisabled      ErrorCode = "TENANT_DISABLED"
+)
+
+// Idempotency keys
+const (
+	CodeIdempotencyKeyReused     ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInProgress ErrorCode = "IDEMPOTENCY_KEY_IN_PROGRESS"
+)
+
+// errorCodeStatus is the catalogue of codes and the HTTP status each one is
+// returned with
+var errorCodeStatus = map[ErrorCode]int{
+	CodeBadRequest:       http.StatusBadRequest,
+	CodeValidation:       http.StatusBadRequest,
+	CodeUnauthorized:     http.StatusUnauthorized,
+	CodeForbidden:        http.StatusForbidden,
+	CodeNotFound:         http.StatusNotFound,
+	CodeMethodNotAllowed: http.StatusMethodNotAllowed,
+	CodeConflict:         http.StatusConflict,
+	CodePayloadTooLarge:  http.StatusRequestEntityTooLarge,
+	CodeTooManyRequests:  http.StatusTooManyRequests,
+	CodeInternal:         http.StatusInternalServerError,
+	CodeUnavailable:      http.StatusServiceUnavailable,
+
+	CodeAuthInvalidCredentials:  http.StatusUnauthorized,
+	CodeAuthAccountInactive:     http.StatusUnauthorized,
+	CodeAuthTokenMissing:        http.StatusUnauthorized,
+	CodeAuthTokenInvalid:        http.StatusUnauthorized,
+	CodeAuthRefreshTokenInvalid: http.StatusUnauthorized,
+	CodeAuthRefreshTokenExpired: http.StatusUnauthorized,
+	CodeAuthPermissionDenied:    http.StatusForbidden,
+	CodeAuthPasswordIncorrect:   http.StatusBadRequest,
+	CodeAuthPasswordPolicy:      http.StatusBadRequest,
+	CodeAuthResetTokenInvalid:   http.StatusBadRequest,
+	CodeAuthOTPInvalid:          http.StatusBadRequest,
+	CodeAuthOTPRateLimited:      http.StatusBadRequest,
+	CodeAuthLinkInvalid:         http.StatusBadRequest,
+
+	CodeUserNotFound:        http.StatusNotFound,
+	CodeUserEmailTaken:      http.StatusConflict,
+	CodeUserUsernameTaken:   http.StatusConflict,
+	CodeUserPhoneTaken:      http.StatusConflict,
+	CodeUserConsentRequired: http.StatusBadRequest,
+	CodeTenantUnknown:       http.StatusBadRequest,
+	CodeTenantDisabled:      http.StatusForbidden,
+
+	CodeIdempotencyKeyReused:     http.StatusUnprocessableEntity,
+	CodeIdempotencyKeyInProgress: http.StatusConflict,
+}
+
+// Status returns the HTTP status of the code, 500 for unknown codes
+func (c ErrorCode) Status() int {
+	if status, ok := errorCodeStatus[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// CodeForStatus returns the generic code of an HTTP status, for errors
+// raised without a code such as echo's 404 and 405
+func CodeForStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
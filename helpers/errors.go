@@ -1,32 +1,112 @@
 package helpers
 
-import "net/http"
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
+)
 
 // AppError represents standardized application error
 type AppError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
+	Code      int       `json:"code"`
+	ErrorCode ErrorCode `json:"error_code"`
+	Message   string    `json:"message"`
+	Details   string    `json:"details,omitempty"`
+	// Fields maps request fields to what is wrong with them
+	Fields map[string]string `json:"errors,omitempty"`
+	// Cause is the underlying error, it is logged but never sent to clients
+	Cause error `json:"-"`
+
+	// format and args rebuild Message in another locale, see Localize
+	format string
+	args   []interface{}
 }
 
 func (e *AppError) Error() string {
 	return e.Message
 }
 
-// NewAppError creates a new AppError
+func (e *AppError) Unwrap() error {
+	return e.Cause
+}
+
+// Wrap returns a copy of the error with cause attached for the logs
+func (e *AppError) Wrap(cause error) *AppError {
+	wrapped := *e
+	wrapped.Cause = cause
+	return &wrapped
+}
+
+// WithField returns a copy of the error reporting what is wrong with a
+// request field
+func (e *AppError) WithField(field, message string) *AppError {
+	withField := *e
+	withField.Fields = make(map[string]string, len(e.Fields)+1)
+	for k, v := range e.Fields {
+		withField.Fields[k] = v
+	}
+	withField.Fields[field] = message
+	return &withField
+}
+
+// Localize returns a copy of the error with the message and field errors
+// translated into locale
+func (e *AppError) Localize(locale i18n.Locale) *AppError {
+	localized := *e
+	if e.format != "" {
+		localized.Message = i18n.Translate(locale, e.format, e.args...)
+	} else {
+		localized.Message = i18n.Translate(locale, e.Message)
+	}
+	if len(e.Fields) > 0 {
+		localized.Fields = make(map[string]string, len(e.Fields))
+		for field, message := range e.Fields {
+			localized.Fields[field] = i18n.Translate(locale, message)
+		}
+	}
+	return &localized
+}
+
+// NewAppError creates a new AppError with the generic code of the status
 func NewAppError(code int, message string, details string) *AppError {
 	return &AppError{
-		Code:    code,
-		Message: message,
-		Details: details,
+		Code:      code,
+		ErrorCode: CodeForStatus(code),
+		Message:   message,
+		Details:   details,
+	}
+}
+
+// NewCodeError creates an AppError with a catalogued code, the HTTP status
+// comes from the catalogue
+func NewCodeError(code ErrorCode, message string) *AppError {
+	return &AppError{
+		Code:      code.Status(),
+		ErrorCode: code,
+		Message:   message,
 	}
 }
 
+// NewCodeErrorf is NewCodeError with a message format, the format is the
+// translation key
+func NewCodeErrorf(code ErrorCode, format string, args ...interface{}) *AppError {
+	err := NewCodeError(code, fmt.Sprintf(format, args...))
+	err.format, err.args = format, args
+	return err
+}
+
 // Common error constructors
 func ErrBadRequest(message string) *AppError {
 	return NewAppError(http.StatusBadRequest, message, "")
 }
 
+func ErrBadRequestf(format string, args ...interface{}) *AppError {
+	err := ErrBadRequest(fmt.Sprintf(format, args...))
+	err.format, err.args = format, args
+	return err
+}
+
 func ErrUnauthorized(message string) *AppError {
 	return NewAppError(http.StatusUnauthorized, message, "")
 }
@@ -43,10 +123,39 @@ func ErrConflict(message string) *AppError {
 	return NewAppError(http.StatusConflict, message, "")
 }
 
+// ErrAlreadyInUse is the error of taken unique user fields, coded after the
+// first of them and listing all
+func ErrAlreadyInUse(fields ...string) *AppError {
+	if len(fields) == 0 {
+		return ErrConflict("Duplicate data")
+	}
+
+	var err *AppError
+	switch fields[0] {
+	case "email":
+		err = NewCodeError(CodeUserEmailTaken, "Email already registered")
+	case "username":
+		err = NewCodeError(CodeUserUsernameTaken, "Username already taken")
+	case "phone_number":
+		err = NewCodeError(CodeUserPhoneTaken, "Phone already exist")
+	default:
+		return ErrConflict("Duplicate data")
+	}
+	if len(fields) > 1 {
+		err = NewCodeError(err.ErrorCode, "Some of these details are already in use")
+	}
+	for _, field := range fields {
+		err = err.WithField(field, "already in use")
+	}
+	return err
+}
+
 func ErrInternalServer(message string) *AppError {
 	return NewAppError(http.StatusInternalServerError, message, "")
 }
 
 func ErrValidation(details string) *AppError {
-	return NewAppError(http.StatusBadRequest, "Validation error", details)
+	err := NewAppError(http.StatusBadRequest, "Validation error", details)
+	err.ErrorCode = CodeValidation
+	return err
 }
@@ -2,33 +2,91 @@ package helpers
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type JWTClaims struct {
-	UserID   int    `json:"user_id"`
-	Email    string `json:"email"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID      int      `json:"user_id"`
+	TenantID    int      `json:"tenant_id"`
+	Email       string   `json:"email"`
+	Username    string   `json:"username"`
+	Role        string   `json:"role"`
+	Locale      string   `json:"locale,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// TokenSubject holds the user data embedded in an access token.
+// SigningKey overrides JWT_SECRET, e.g. for tenants with their own key.
+type TokenSubject struct {
+	UserID      int
+	TenantID    int
+	Email       string
+	Username    string
+	Role        string
+	Locale      string
+	Roles       []string
+	Permissions []string
+	SigningKey  string
+}
+
+// HasPermission reports whether the claims grant the required permission
+func (c *JWTClaims) HasPermission(required string) bool {
+	return HasPermission(c.Permissions, required)
+}
+
+// HasRole reports whether the claims contain one of the given roles
+func (c *JWTClaims) HasRole(roles ...string) bool {
+	for _, want := range roles {
+		if c.Role == want {
+			return true
+		}
+		for _, role := range c.Roles {
+			if role == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasPermission checks a required permission against granted permissions.
+// Granted permissions may use wildcards: "*" matches everything and
+// "orders:*" matches every permission of the orders resource.
+func HasPermission(granted []string, required string) bool {
+	for _, perm := range granted {
+		if perm == required || perm == "*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(perm, "*"); ok && strings.HasPrefix(required, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // GenerateAccessToken generates JWT access token
-func GenerateAccessToken(userID int, email, username, role string) (string, time.Time, error) {
-	secretKey := Env["JWT_SECRET"]
+func GenerateAccessToken(subject TokenSubject) (string, time.Time, error) {
+	secretKey := accessSecret(subject.SigningKey)
 	if secretKey == "" {
 		return "", time.Time{}, errors.New("JWT_SECRET not configured")
 	}
 
-	expirationTime := time.Now().Add(24 * time.Hour) // 24 hours
+	expirationTime := time.Now().Add(Config.Auth.AccessTokenTTL)
 
 	claims := &JWTClaims{
-		UserID:   userID,
-		Email:    email,
-		Username: username,
-		Role:     role,
+		UserID:      subject.UserID,
+		TenantID:    subject.TenantID,
+		Email:       subject.Email,
+		Username:    subject.Username,
+		Role:        subject.Role,
+		Locale:      subject.Locale,
+		Roles:       subject.Roles,
+		Permissions: subject.Permissions,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -47,16 +105,14 @@ func GenerateAccessToken(userID int, email, username, role string) (string, time
 }
 
 // GenerateRefreshToken generates JWT refresh token
-func GenerateRefreshToken(userID int) (string, time.Time, error) {
-	secretKey := Env["JWT_REFRESH_SECRET"]
-	if secretKey == "" {
-		secretKey = Env["JWT_SECRET"] // fallback to JWT_SECRET
-	}
+func GenerateRefreshToken(userID, tenantID int, signingKey string) (string, time.Time, error) {
+	secretKey := refreshSecret(signingKey)
 
-	expirationTime := time.Now().Add(7 * 24 * time.Hour) // 7 days
+	expirationTime := time.Now().Add(Config.Auth.RefreshTokenTTL)
 
 	claims := &JWTClaims{
-		UserID: userID,
+		UserID:   userID,
+		TenantID: tenantID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -74,9 +130,10 @@ func GenerateRefreshToken(userID int) (string, time.Time, error) {
 	return tokenString, expirationTime, nil
 }
 
-// ValidateToken validates JWT token and returns claims
-func ValidateToken(tokenString string) (*JWTClaims, error) {
-	secretKey := Env["JWT_SECRET"]
+// ValidateToken validates JWT token and returns claims.
+// An empty signingKey validates against JWT_SECRET.
+func ValidateToken(tokenString, signingKey string) (*JWTClaims, error) {
+	secretKey := accessSecret(signingKey)
 	if secretKey == "" {
 		return nil, errors.New("JWT_SECRET not configured")
 	}
@@ -100,11 +157,8 @@ func ValidateToken(tokenString string) (*JWTClaims, error) {
 }
 
 // ValidateRefreshToken validates refresh token and returns claims
-func ValidateRefreshToken(tokenString string) (*JWTClaims, error) {
-	secretKey := Env["JWT_REFRESH_SECRET"]
-	if secretKey == "" {
-		secretKey = Env["JWT_SECRET"] // fallback to JWT_SECRET
-	}
+func ValidateRefreshToken(tokenString, signingKey string) (*JWTClaims, error) {
+	secretKey := refreshSecret(signingKey)
 
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -123,3 +177,25 @@ func ValidateRefreshToken(tokenString string) (*JWTClaims, error) {
 
 	return nil, errors.New("invalid refresh token")
 }
+
+// accessSecret returns the key used to sign access tokens
+func accessSecret(signingKey string) string {
+	if signingKey != "" {
+		return signingKey
+	}
+	return Config.Auth.JWTSecret
+}
+
+// refreshSecret returns the key used to sign refresh tokens. Tenant keys are
+// suffixed so access and refresh tokens are never interchangeable.
+func refreshSecret(signingKey string) string {
+	if signingKey != "" {
+		return signingKey + ":refresh"
+	}
+
+	secretKey := Config.Auth.JWTRefreshSecret
+	if secretKey == "" {
+		secretKey = Config.Auth.JWTSecret // fallback to JWT_SECRET
+	}
+	return secretKey
+}
@@ -1,16 +1,64 @@
 package helpers
 
-import "github.com/sirupsen/logrus"
+import (
+	"context"
+	"os"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/config"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/logging"
+	"github.com/sirupsen/logrus"
+)
 
 var Logger *logrus.Logger
 
+// SetupLogger creates the logger with the default settings, ConfigureLogger
+// applies the configuration once it is loaded
 func SetupLogger() {
 	log := logrus.New()
+	log.SetOutput(os.Stdout)
+	Logger = log
+	ConfigureLogger(config.Default().Log)
+}
 
-	log.SetFormatter(&logrus.JSONFormatter{
-		PrettyPrint: true,
-	})
+// ConfigureLogger sets the level, format and redaction of the logger
+func ConfigureLogger(cfg config.LogConfig) {
+	level, err := logrus.ParseLevel(cfg.Level)
+	if err != nil {
+		level = logrus.InfoLevel
+	}
+	Logger.SetLevel(level)
 
-	log.Info("Logger initiated using logrus")
-	Logger = log
+	if cfg.Format == "text" {
+		Logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	} else {
+		Logger.SetFormatter(&logrus.JSONFormatter{})
+	}
+
+	// The standard logger is still used by a few startup messages
+	logrus.SetLevel(level)
+	logrus.SetFormatter(Logger.Formatter)
+
+	hooks := logrus.LevelHooks{}
+	hooks.Add(logging.TraceHook{})
+	if cfg.Redact {
+		hooks.Add(logging.RedactHook{})
+	}
+	Logger.ReplaceHooks(hooks)
+	logrus.StandardLogger().ReplaceHooks(hooks)
+}
+
+type loggerKey struct{}
+
+// WithLogger returns a context carrying entry, e.g. with the request ID
+func WithLogger(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, entry)
+}
+
+// Log returns the logger of the request in ctx, which adds the request ID
+// and trace ID to every entry
+func Log(ctx context.Context) *logrus.Entry {
+	if entry, ok := ctx.Value(loggerKey{}).(*logrus.Entry); ok {
+		return entry.WithContext(ctx)
+	}
+	return Logger.WithContext(ctx)
 }
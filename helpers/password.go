@@ -1,20 +1,32 @@
 package helpers
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"math/big"
+	"time"
 
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // HashPassword hashes a password using bcrypt
-func HashPassword(password string) (string, error) {
+func HashPassword(ctx context.Context, password string) (string, error) {
 	if password == "" {
 		return "", errors.New("password cannot be empty")
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	_, span := tracer.Start(ctx, "bcrypt.hash", trace.WithAttributes(attribute.Int("bcrypt.cost", Config.Auth.BcryptCost)))
+	start := time.Now()
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), Config.Auth.BcryptCost)
+	metrics.PasswordHashDuration.WithLabelValues("hash").Observe(metrics.Since(start))
+	RecordSpanError(span, err)
+	span.End()
 	if err != nil {
 		return "", err
 	}
@@ -23,8 +35,13 @@ func HashPassword(password string) (string, error) {
 }
 
 // ComparePassword compares a hashed password with plain text password
-func ComparePassword(hashedPassword, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+func ComparePassword(ctx context.Context, hashedPassword, password string) error {
+	_, span := tracer.Start(ctx, "bcrypt.compare")
+	start := time.Now()
+	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	metrics.PasswordHashDuration.WithLabelValues("compare").Observe(metrics.Since(start))
+	span.End()
+	return err
 }
 
 // GenerateRandomToken generates a random token for password reset, etc
@@ -35,3 +52,23 @@ func GenerateRandomToken(length int) (string, error) {
 	}
 	return hex.EncodeToString(bytes), nil
 }
+
+// HashToken hashes a random token before it is stored, so a database leak
+// does not expose usable links
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateOTP generates a numeric one-time password with the given number of digits
+func GenerateOTP(digits int) (string, error) {
+	otp := make([]byte, digits)
+	for i := range otp {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		otp[i] = byte('0' + n.Int64())
+	}
+	return string(otp), nil
+}
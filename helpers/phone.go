@@ -0,0 +1,57 @@
+package helpers
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// DefaultCountryCode is used for national numbers written with a leading 0
+const DefaultCountryCode = "62"
+
+var (
+	// ErrInvalidPhone is returned when a number cannot be normalized to E.164
+	ErrInvalidPhone = errors.New("invalid phone number")
+
+	phoneSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+	reE164          = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+	reIndonesianMSN = regexp.MustCompile(`^\+628[1-9][0-9]{6,11}$`)
+)
+
+// NormalizePhone converts a phone number to E.164, e.g. 0812-3456-7890,
+// 6281234567890 and +62 812 3456 7890 all become +6281234567890. Numbers
+// without a country code are treated as Indonesian.
+func NormalizePhone(phone string) (string, error) {
+	p := phoneSeparators.Replace(strings.TrimSpace(phone))
+
+	switch {
+	case strings.HasPrefix(p, "+"):
+	case strings.HasPrefix(p, "00"):
+		p = "+" + p[2:]
+	case strings.HasPrefix(p, "0"):
+		p = "+" + DefaultCountryCode + p[1:]
+	case strings.HasPrefix(p, DefaultCountryCode):
+		p = "+" + p
+	default:
+		return "", ErrInvalidPhone
+	}
+
+	if !reE164.MatchString(p) {
+		return "", ErrInvalidPhone
+	}
+	// Indonesian numbers must be mobile numbers so they can receive an OTP
+	if strings.HasPrefix(p, "+"+DefaultCountryCode) && !reIndonesianMSN.MatchString(p) {
+		return "", ErrInvalidPhone
+	}
+
+	return p, nil
+}
+
+// NormalizePhoneOrRaw normalizes a phone number for lookups, keeping the input
+// when it is not a valid number so the lookup simply finds nothing
+func NormalizePhoneOrRaw(phone string) string {
+	if normalized, err := NormalizePhone(phone); err == nil {
+		return normalized
+	}
+	return strings.TrimSpace(phone)
+}
@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log"
+	"os"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/pii"
+	"github.com/sirupsen/logrus"
+)
+
+// SetupPIIKeyring loads the keys used to encrypt personal data columns. It
+// must run before the database is used.
+func SetupPIIKeyring() {
+	keyring, err := LoadPIIKeyring()
+	if err != nil {
+		log.Fatal("failed to load PII encryption keys: ", err)
+	}
+	pii.SetDefault(keyring)
+}
+
+// LoadPIIKeyring builds the keyring from PII_MASTER_KEY_FILE or
+// PII_MASTER_KEYS ("id:base64key" entries), PII_ACTIVE_KEY_ID (defaults to
+// the last listed key) and PII_BLIND_INDEX_KEY. Without master keys it falls
+// back to keys derived from APP_SECRET, which is only meant for development.
+func LoadPIIKeyring() (*pii.Keyring, error) {
+	source := Config.PII.MasterKeys
+	if file := Config.PII.MasterKeyFile; file != "" {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		source = string(content)
+	}
+
+	if source == "" {
+		logrus.Warn("PII_MASTER_KEYS is not set, deriving PII encryption keys from APP_SECRET")
+		return pii.NewKeyring(map[string][]byte{"dev": deriveKey("pii-master-key")}, "dev", deriveKey("pii-blind-index"))
+	}
+
+	keys, ids, err := pii.ParseKeys(source)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("no PII master keys found")
+	}
+
+	activeID := Config.PII.ActiveKeyID
+	if activeID == "" {
+		activeID = ids[len(ids)-1]
+	}
+
+	if Config.PII.BlindIndexKey == "" {
+		return nil, errors.New("PII_BLIND_INDEX_KEY is required when PII master keys are set")
+	}
+	indexKey, err := base64.StdEncoding.DecodeString(Config.PII.BlindIndexKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return pii.NewKeyring(keys, activeID, indexKey)
+}
+
+func deriveKey(purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(Config.App.Secret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
@@ -1,29 +1,252 @@
 package helpers
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"log"
+	"strings"
+	"time"
 
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/constants"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/migrator"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/migrations"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/sirupsen/logrus"
+	"github.com/uptrace/opentelemetry-go-extra/otelgorm"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var DB *gorm.DB
 
-func SetupPostgreSQL() {
+// ConnectPostgreSQL opens the database connection without touching the schema
+func ConnectPostgreSQL() {
 	var err error
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", GetEnv("DB_HOST", "127.0.0.1"), GetEnv("DB_PORT", "5432"), GetEnv("DB_USER", "auth_db"), GetEnv("DB_PASSWORD", "password"), GetEnv("DB_NAME", "auth_db"))
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	DB, err = gorm.Open(postgres.Open(Config.Database.DSN()), &gorm.Config{})
 	if err != nil {
 		log.Fatal("failed to connect to database: ", err)
 	}
+	// Statements are recorded with placeholders, never the bound values, so
+	// personal data stays out of the traces
+	if err := DB.Use(otelgorm.NewPlugin(
+		otelgorm.WithDBName(Config.Database.Name),
+		otelgorm.WithoutQueryVariables(),
+		otelgorm.WithoutMetrics(),
+	)); err != nil {
+		log.Fatal("failed to register database tracing: ", err)
+	}
 
 	logrus.Info("Successfully connect to database..")
+}
+
+// ClosePostgreSQL closes the connection pool, waiting for queries in progress
+func ClosePostgreSQL() {
+	if DB == nil {
+		return
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return
+	}
+	if err := sqlDB.Close(); err != nil {
+		logrus.Warn("Failed to close database connections: ", err)
+		return
+	}
+	logrus.Info("Database connections closed")
+}
+
+// NewMigrator returns the migrator for the embedded SQL migrations
+func NewMigrator() (*migrator.Migrator, error) {
+	return migrator.New(DB, migrations.FS, Logger)
+}
+
+// SetupPostgreSQL connects to the database, makes sure every migration has
+// been applied and seeds default data. With DB_AUTO_MIGRATE=true pending
+// migrations are applied, otherwise the server refuses to start.
+func SetupPostgreSQL() {
+	ConnectPostgreSQL()
+
+	m, err := NewMigrator()
+	if err != nil {
+		log.Fatal("failed to load migrations: ", err)
+	}
+
+	if Config.Database.AutoMigrate {
+		if _, err := m.Up(context.Background(), 0); err != nil {
+			log.Fatal("failed to migrate database: ", err)
+		}
+	}
+
+	pending, err := m.Pending(context.Background())
+	if err != nil {
+		log.Fatal("failed to read migration status: ", err)
+	}
+	if len(pending) > 0 {
+		log.Fatalf("database schema is not up to date, %d migrations pending (next: %03d_%s), run `migrate up`",
+			len(pending), pending[0].Version, pending[0].Name)
+	}
+
+	// Registration needs the default tenant and its legal documents
+	if err := SeedDefaultTenant(DB); err != nil {
+		log.Fatal("failed to seed default tenant: ", err)
+	}
+
+	if err := SeedLegalDocuments(DB); err != nil {
+		log.Fatal("failed to seed legal documents: ", err)
+	}
+
+	missing, err := CountMissingCanonicalIdentities(DB)
+	if err != nil {
+		log.Fatal("failed to check canonical emails and usernames: ", err)
+	}
+	if missing > 0 {
+		log.Fatalf("%d users have no canonical email or username and no reported collision, run `user backfill-identities`", missing)
+	}
+}
+
+// SeedDefaultTenant makes sure the default tenant exists. Users created
+// before multi-tenancy belong to it.
+func SeedDefaultTenant(db *gorm.DB) error {
+	tenant := models.Tenant{
+		ID:                constants.DefaultTenantID,
+		Slug:              constants.DefaultTenantSlug,
+		Name:              "Default",
+		PasswordMinLength: 8,
+		IsActive:          true,
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tenant).Error; err != nil {
+		return err
+	}
+
+	// Keep the sequence ahead of the explicitly inserted ID
+	return db.Exec(`SELECT setval(pg_get_serial_sequence('tenants', 'id'), GREATEST((SELECT MAX(id) FROM tenants), 1))`).Error
+}
+
+// SeedLegalDocuments publishes version 1.0 of the terms of service and
+// privacy policy for tenants that have none, so registration can require
+// accepting them. New versions are published through the admin API.
+func SeedLegalDocuments(db *gorm.DB) error {
+	appURL := strings.TrimRight(Config.App.URL, "/")
+
+	defaults := []models.LegalDocument{
+		{Type: constants.DocumentTermsOfService, Version: "1.0", Title: "Terms of Service", URL: appURL + "/legal/terms"},
+		{Type: constants.DocumentPrivacyPolicy, Version: "1.0", Title: "Privacy Policy", URL: appURL + "/legal/privacy"},
+	}
+
+	var tenantIDs []int
+	if err := db.Model(&models.Tenant{}).Pluck("id", &tenantIDs).Error; err != nil {
+		return err
+	}
+
+	for _, tenantID := range tenantIDs {
+		for _, document := range defaults {
+			var count int64
+			if err := db.Model(&models.LegalDocument{}).
+				Where("tenant_id = ? AND type = ?", tenantID, document.Type).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
 
-	err = DB.AutoMigrate(&models.User{}, &models.UserSession{})
+			document.TenantID = tenantID
+			document.PublishedAt = time.Now()
+			if err := db.Create(&document).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CountMissingCanonicalIdentities counts users whose email or username has no
+// canonical value that are not reported in identity_collisions either, i.e.
+// users BackfillCanonicalIdentities has not processed yet
+func CountMissingCanonicalIdentities(db *gorm.DB) (int64, error) {
+	var count int64
+	err := db.Model(&models.User{}).
+		Where(`(email_canonical IS NULL AND NOT EXISTS (
+			SELECT 1 FROM identity_collisions c WHERE c.user_id = users.id AND c.field = 'email'
+		)) OR (username_canonical IS NULL AND NOT EXISTS (
+			SELECT 1 FROM identity_collisions c WHERE c.user_id = users.id AND c.field = 'username'
+		))`).
+		Count(&count).Error
+	return count, err
+}
+
+// BackfillCanonicalIdentities fills email_canonical and username_canonical for
+// users created before they existed. The oldest account keeps a canonical
+// value; newer accounts that collide with it are left empty and reported in
+// identity_collisions. It returns every unresolved collision.
+func BackfillCanonicalIdentities(db *gorm.DB) ([]models.IdentityCollision, error) {
+	var users []models.User
+	err := db.Select("id", "tenant_id", "email", "username", "email_canonical", "username_canonical").
+		Where("email_canonical IS NULL OR username_canonical IS NULL").
+		Order("id").
+		Find(&users).Error
 	if err != nil {
-		logrus.Info("Failed to auto migration", err)
+		return nil, err
+	}
+
+	for _, user := range users {
+		fields := []struct {
+			name, column, current, canonical string
+		}{
+			{"email", "email_canonical", user.EmailCanonical, CanonicalEmail(user.Email)},
+			{"username", "username_canonical", user.UsernameCanonical, CanonicalUsername(user.Username)},
+		}
+
+		for _, field := range fields {
+			if field.current != "" {
+				continue
+			}
+
+			var holder models.User
+			err := db.Select("id").
+				Where("tenant_id = ? AND "+field.column+" = ? AND id <> ?", user.TenantID, field.canonical, user.ID).
+				Limit(1).
+				Find(&holder).Error
+			if err != nil {
+				return nil, err
+			}
+
+			if holder.ID == 0 {
+				err = db.Model(&models.User{}).Where("id = ?", user.ID).Update(field.column, field.canonical).Error
+				if err == nil {
+					continue
+				}
+				if !isUniqueViolation(err) {
+					return nil, err
+				}
+				// Taken concurrently, find who holds it for the report
+				if err := db.Select("id").Where("tenant_id = ? AND "+field.column+" = ?", user.TenantID, field.canonical).
+					Limit(1).Find(&holder).Error; err != nil {
+					return nil, err
+				}
+			}
+
+			collision := models.IdentityCollision{
+				TenantID:          user.TenantID,
+				Field:             field.name,
+				CanonicalValue:    field.canonical,
+				UserID:            user.ID,
+				ConflictingUserID: holder.ID,
+			}
+			if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&collision).Error; err != nil {
+				return nil, err
+			}
+		}
 	}
+
+	var collisions []models.IdentityCollision
+	err = db.Where("resolved_at IS NULL").Order("tenant_id, canonical_value, user_id").Find(&collisions).Error
+	return collisions, err
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == constants.UniqueViolation
 }
@@ -1,19 +1,95 @@
 package helpers
 
-import "github.com/labstack/echo/v4"
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
+	"github.com/labstack/echo/v4"
+)
 
 type BaseResponse struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	Code      int               `json:"code"`
+	ErrorCode ErrorCode         `json:"error_code,omitempty"`
+	Message   string            `json:"message"`
+	Data      interface{}       `json:"data,omitempty"`
+	Errors    map[string]string `json:"errors,omitempty"`
+	TraceID   string            `json:"trace_id,omitempty"`
 }
 
+// ResponseHttp writes a response with message translated into the locale of
+// the request
 func ResponseHttp(e echo.Context, code int, message string, data interface{}) error {
 	resp := BaseResponse{
 		Code:    code,
-		Message: message,
+		Message: i18n.T(e.Request().Context(), message),
 		Data:    data,
 	}
 
 	return e.JSON(code, resp)
 }
+
+// ErrorResponseHttp writes err, already localized, with its error code and the trace ID of the
+// request, so a failure reported by a client can be found in the traces
+func ErrorResponseHttp(e echo.Context, err *AppError) error {
+	resp := BaseResponse{
+		Code:      err.Code,
+		ErrorCode: err.ErrorCode,
+		Message:   err.Message,
+		Errors:    err.Fields,
+		TraceID:   TraceID(e.Request().Context()),
+	}
+
+	// data is kept for clients written before errors and error_code
+	if len(err.Fields) > 0 {
+		resp.Data = err.Fields
+	} else {
+		resp.Data = map[string]string{"details": err.Details}
+	}
+
+	return e.JSON(err.Code, resp)
+}
+
+// MIMEProblemJSON is the media type of RFC 7807 problem details
+const MIMEProblemJSON = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 problem extended with the error code, the
+// field errors and the IDs to quote in bug reports
+type ProblemDetails struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail,omitempty"`
+	Instance  string            `json:"instance,omitempty"`
+	Code      ErrorCode         `json:"code"`
+	Errors    map[string]string `json:"errors,omitempty"`
+	TraceID   string            `json:"trace_id,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+// ProblemResponseHttp writes err as application/problem+json. The type is
+// the error code under http.problem_type_base_url, or about:blank.
+func ProblemResponseHttp(e echo.Context, err *AppError) error {
+	problem := ProblemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(err.Code),
+		Status:   err.Code,
+		Detail:   err.Message,
+		Instance: e.Request().URL.Path,
+		Code:     err.ErrorCode,
+		Errors:   err.Fields,
+		TraceID:  TraceID(e.Request().Context()),
+	}
+	if base := Config.HTTP.ProblemTypeBaseURL; base != "" {
+		problem.Type = base + strings.ToLower(strings.ReplaceAll(string(err.ErrorCode), "_", "-"))
+	}
+	if err.Details != "" {
+		problem.Detail += ": " + err.Details
+	}
+	if requestID, ok := e.Get("request_id").(string); ok {
+		problem.RequestID = requestID
+	}
+
+	e.Response().Header().Set(echo.HeaderContentType, MIMEProblemJSON)
+	return e.JSON(err.Code, problem)
+}
@@ -0,0 +1,28 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Sign returns an HMAC-SHA256 signature of value keyed with the app secret, used
+// for links that must not be forged such as data export downloads
+func Sign(value string) string {
+	mac := hmac.New(sha256.New, []byte(Config.App.Secret))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks a signature created by Sign in constant time
+func VerifySignature(value, signature string) bool {
+	expected, err := hex.DecodeString(Sign(value))
+	if err != nil {
+		return false
+	}
+	actual, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, actual)
+}
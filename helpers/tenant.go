@@ -0,0 +1,20 @@
+package helpers
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+)
+
+type tenantContextKey struct{}
+
+// WithTenant returns a copy of ctx carrying the resolved tenant
+func WithTenant(ctx context.Context, tenant *models.Tenant) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant resolved for the request, or nil
+func TenantFromContext(ctx context.Context) *models.Tenant {
+	tenant, _ := ctx.Value(tenantContextKey{}).(*models.Tenant)
+	return tenant
+}
@@ -0,0 +1,97 @@
+package helpers
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/ibnuzaman/auth-simple-ecommerce.git/helpers")
+
+// SetupTracing installs the OpenTelemetry tracer provider configured by
+// TRACING_EXPORTER and returns a function flushing the spans not exported yet
+func SetupTracing() (func(ctx context.Context) error, error) {
+	cfg := Config.Tracing
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case config.TracingExporterOTLP:
+		exporter, err = otlptracehttp.New(context.Background(),
+			otlptracehttp.WithEndpointURL(strings.TrimRight(cfg.OTLPEndpoint, "/")+"/v1/traces"),
+			otlptracehttp.WithHeaders(parseOTLPHeaders(cfg.OTLPHeaders)))
+	case config.TracingExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case config.TracingExporterFile:
+		file, openErr := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if openErr != nil {
+			return nil, openErr
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Traces started upstream follow the caller's sampling decision. Without
+	// an exporter spans still get IDs for the logs and X-Trace-Id.
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName))),
+	}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	provider := sdktrace.NewTracerProvider(opts...)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		Logger.WithError(err).Warn("Failed to export trace spans")
+	}))
+
+	Logger.WithField("exporter", cfg.Exporter).Info("Tracing initiated")
+	return provider.Shutdown, nil
+}
+
+// parseOTLPHeaders parses comma separated key=value pairs, the format of
+// OTEL_EXPORTER_OTLP_HEADERS
+func parseOTLPHeaders(headers string) map[string]string {
+	parsed := map[string]string{}
+	for _, pair := range strings.Split(headers, ",") {
+		if key, value, ok := strings.Cut(pair, "="); ok {
+			parsed[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return parsed
+}
+
+// TraceID returns the hex trace ID of the current span, or an empty string
+// outside a trace
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
+
+// RecordSpanError marks the span failed with err, nothing when err is nil
+func RecordSpanError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -1,7 +1,9 @@
 package helpers
 
 import (
+	"reflect"
 	"regexp"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -11,6 +13,18 @@ var validate *validator.Validate
 func init() {
 	validate = validator.New()
 
+	// Report fields by their JSON names, the names clients send
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			return ""
+		case "":
+			return field.Name
+		}
+		return name
+	})
+
 	// Register custom validators
 	_ = validate.RegisterValidation("phone", validatePhone)
 	_ = validate.RegisterValidation("username", validateUsername)
@@ -21,12 +35,11 @@ func GetValidator() *validator.Validate {
 	return validate
 }
 
-// validatePhone validates Indonesian phone number format
+// validatePhone validates that the phone number can be normalized to E.164.
+// Indonesian numbers may start with 08, 62 or +62
 func validatePhone(fl validator.FieldLevel) bool {
-	phone := fl.Field().String()
-	// Indonesian phone number: starts with 08 or +62, 10-15 digits
-	matched, _ := regexp.MatchString(`^(\+62|62|0)8[1-9][0-9]{6,11}$`, phone)
-	return matched
+	_, err := NormalizePhone(fl.Field().String())
+	return err == nil
 }
 
 // validateUsername validates username format
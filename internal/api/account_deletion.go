@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/labstack/echo/v4"
+)
+
+type AccountDeletionHandler struct {
+	accountDeletionService interfaces.IAccountDeletionService
+	validate               *validator.Validate
+}
+
+func NewAccountDeletionHandler(accountDeletionService interfaces.IAccountDeletionService) *AccountDeletionHandler {
+	return &AccountDeletionHandler{
+		accountDeletionService: accountDeletionService,
+		validate:               helpers.GetValidator(),
+	}
+}
+
+// DeleteAccount godoc
+// @Summary Delete account
+// @Description Schedule the account for deletion after a grace period. All sessions are revoked and logging in again before the scheduled time cancels the deletion. Afterwards personal data is anonymized
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.DeleteAccountRequest true "Current password"
+// @Success 202 {object} helpers.BaseResponse{data=dto.DeleteAccountResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 401 {object} helpers.BaseResponse
+// @Failure 500 {object} helpers.BaseResponse
+// @Router /v1/auth/account [delete]
+func (h *AccountDeletionHandler) DeleteAccount(c echo.Context) error {
+	userID, ok := c.Get("user_id").(int)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	var req dto.DeleteAccountRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	response, err := h.accountDeletionService.RequestDeletion(c.Request().Context(), userID, &req)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusAccepted, "Account deletion scheduled", response)
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/labstack/echo/v4"
+)
+
+type AddressHandler struct {
+	addressService interfaces.IAddressService
+	validate       *validator.Validate
+}
+
+func NewAddressHandler(addressService interfaces.IAddressService) *AddressHandler {
+	return &AddressHandler{
+		addressService: addressService,
+		validate:       helpers.GetValidator(),
+	}
+}
+
+// ListAddresses godoc
+// @Summary List addresses
+// @Description List the authenticated user's address book, defaults first
+// @Tags Address
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} helpers.BaseResponse{data=[]dto.AddressResponse}
+// @Failure 401 {object} helpers.BaseResponse
+// @Router /v1/users/me/addresses [get]
+func (h *AddressHandler) ListAddresses(c echo.Context) error {
+	userID, ok := c.Get("user_id").(int)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	response, err := h.addressService.List(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Addresses retrieved successfully", response)
+}
+
+// GetAddress godoc
+// @Summary Get address
+// @Description Get a single address from the address book
+// @Tags Address
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Address ID"
+// @Success 200 {object} helpers.BaseResponse{data=dto.AddressResponse}
+// @Failure 404 {object} helpers.BaseResponse
+// @Router /v1/users/me/addresses/{id} [get]
+func (h *AddressHandler) GetAddress(c echo.Context) error {
+	userID, ok := c.Get("user_id").(int)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return helpers.ErrBadRequest("Invalid address id")
+	}
+
+	response, err := h.addressService.Get(c.Request().Context(), userID, id)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Address retrieved successfully", response)
+}
+
+// CreateAddress godoc
+// @Summary Create address
+// @Description Add an address to the address book. The first address becomes the default shipping and billing address
+// @Tags Address
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.AddressRequest true "Address"
+// @Success 201 {object} helpers.BaseResponse{data=dto.AddressResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Router /v1/users/me/addresses [post]
+func (h *AddressHandler) CreateAddress(c echo.Context) error {
+	userID, ok := c.Get("user_id").(int)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	var req dto.AddressRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	response, err := h.addressService.Create(c.Request().Context(), userID, &req)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusCreated, "Address created successfully", response)
+}
+
+// UpdateAddress godoc
+// @Summary Update address
+// @Description Partially update an address. Setting a default flag moves it from the previous default
+// @Tags Address
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Address ID"
+// @Param request body dto.UpdateAddressRequest true "Address fields"
+// @Success 200 {object} helpers.BaseResponse{data=dto.AddressResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 404 {object} helpers.BaseResponse
+// @Router /v1/users/me/addresses/{id} [patch]
+func (h *AddressHandler) UpdateAddress(c echo.Context) error {
+	userID, ok := c.Get("user_id").(int)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return helpers.ErrBadRequest("Invalid address id")
+	}
+
+	var req dto.UpdateAddressRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	response, err := h.addressService.Update(c.Request().Context(), userID, id, &req)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Address updated successfully", response)
+}
+
+// DeleteAddress godoc
+// @Summary Delete address
+// @Description Delete an address. A removed default moves to the oldest remaining address
+// @Tags Address
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Address ID"
+// @Success 200 {object} helpers.BaseResponse{data=dto.MessageResponse}
+// @Failure 404 {object} helpers.BaseResponse
+// @Router /v1/users/me/addresses/{id} [delete]
+func (h *AddressHandler) DeleteAddress(c echo.Context) error {
+	userID, ok := c.Get("user_id").(int)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return helpers.ErrBadRequest("Invalid address id")
+	}
+
+	if err := h.addressService.Delete(c.Request().Context(), userID, id); err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Address deleted successfully", dto.MessageResponse{
+		Message: i18n.T(c.Request().Context(), "The address has been removed from your address book"),
+	})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
 	"github.com/labstack/echo/v4"
@@ -18,7 +19,7 @@ type AuthHandler struct {
 func NewAuthHandler(authService interfaces.IAuthService) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
-		validate:    validator.New(),
+		validate:    helpers.GetValidator(),
 	}
 }
 
@@ -29,16 +30,20 @@ func NewAuthHandler(authService interfaces.IAuthService) *AuthHandler {
 // @Accept json
 // @Produce json
 // @Param request body dto.RegisterRequest true "Registration request"
+// @Param Idempotency-Key header string false "Makes retries replay the first response"
 // @Success 201 {object} helpers.BaseResponse{data=dto.AuthResponse}
 // @Failure 400 {object} helpers.BaseResponse
 // @Failure 409 {object} helpers.BaseResponse
+// @Failure 422 {object} helpers.BaseResponse
 // @Failure 500 {object} helpers.BaseResponse
 // @Router /v1/auth/register [post]
 func (h *AuthHandler) Register(c echo.Context) error {
 	var req dto.RegisterRequest
 	if err := c.Bind(&req); err != nil {
-		return helpers.ResponseHttp(c, http.StatusBadRequest, "Invalid request body", nil)
+		return helpers.ErrBadRequest("Invalid request body")
 	}
+	req.IPAddress = c.RealIP()
+	req.UserAgent = c.Request().UserAgent()
 
 	if err := h.validate.Struct(req); err != nil {
 		return err
@@ -67,7 +72,7 @@ func (h *AuthHandler) Register(c echo.Context) error {
 func (h *AuthHandler) Login(c echo.Context) error {
 	var req dto.LoginRequest
 	if err := c.Bind(&req); err != nil {
-		return helpers.ResponseHttp(c, http.StatusBadRequest, "Invalid request body", nil)
+		return helpers.ErrBadRequest("Invalid request body")
 	}
 
 	if err := h.validate.Struct(req); err != nil {
@@ -97,7 +102,7 @@ func (h *AuthHandler) Login(c echo.Context) error {
 func (h *AuthHandler) RefreshToken(c echo.Context) error {
 	var req dto.RefreshTokenRequest
 	if err := c.Bind(&req); err != nil {
-		return helpers.ResponseHttp(c, http.StatusBadRequest, "Invalid request body", nil)
+		return helpers.ErrBadRequest("Invalid request body")
 	}
 
 	if err := h.validate.Struct(req); err != nil {
@@ -119,6 +124,7 @@ func (h *AuthHandler) RefreshToken(c echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param request body dto.ForgotPasswordRequest true "User email"
+// @Param Idempotency-Key header string false "Makes retries replay the first response"
 // @Success 200 {object} helpers.BaseResponse{data=dto.MessageResponse}
 // @Failure 400 {object} helpers.BaseResponse
 // @Failure 500 {object} helpers.BaseResponse
@@ -126,7 +132,7 @@ func (h *AuthHandler) RefreshToken(c echo.Context) error {
 func (h *AuthHandler) ForgotPassword(c echo.Context) error {
 	var req dto.ForgotPasswordRequest
 	if err := c.Bind(&req); err != nil {
-		return helpers.ResponseHttp(c, http.StatusBadRequest, "Invalid request body", nil)
+		return helpers.ErrBadRequest("Invalid request body")
 	}
 
 	if err := h.validate.Struct(req); err != nil {
@@ -139,7 +145,7 @@ func (h *AuthHandler) ForgotPassword(c echo.Context) error {
 	}
 
 	return helpers.ResponseHttp(c, http.StatusOK, "Password reset instructions sent to your email", dto.MessageResponse{
-		Message: "If the email exists, you will receive password reset instructions",
+		Message: i18n.T(c.Request().Context(), "If the email exists, you will receive password reset instructions"),
 	})
 }
 
@@ -150,14 +156,16 @@ func (h *AuthHandler) ForgotPassword(c echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param request body dto.ResetPasswordRequest true "Reset token and new password"
+// @Param Idempotency-Key header string false "Makes retries replay the first response"
 // @Success 200 {object} helpers.BaseResponse{data=dto.MessageResponse}
 // @Failure 400 {object} helpers.BaseResponse
+// @Failure 422 {object} helpers.BaseResponse
 // @Failure 500 {object} helpers.BaseResponse
 // @Router /v1/auth/reset-password [post]
 func (h *AuthHandler) ResetPassword(c echo.Context) error {
 	var req dto.ResetPasswordRequest
 	if err := c.Bind(&req); err != nil {
-		return helpers.ResponseHttp(c, http.StatusBadRequest, "Invalid request body", nil)
+		return helpers.ErrBadRequest("Invalid request body")
 	}
 
 	if err := h.validate.Struct(req); err != nil {
@@ -170,7 +178,7 @@ func (h *AuthHandler) ResetPassword(c echo.Context) error {
 	}
 
 	return helpers.ResponseHttp(c, http.StatusOK, "Password reset successful", dto.MessageResponse{
-		Message: "Your password has been reset successfully. Please login with your new password",
+		Message: i18n.T(c.Request().Context(), "Your password has been reset successfully. Please login with your new password"),
 	})
 }
 
@@ -182,6 +190,7 @@ func (h *AuthHandler) ResetPassword(c echo.Context) error {
 // @Produce json
 // @Security BearerAuth
 // @Param request body dto.ChangePasswordRequest true "Old and new password"
+// @Param Idempotency-Key header string false "Makes retries replay the first response"
 // @Success 200 {object} helpers.BaseResponse{data=dto.MessageResponse}
 // @Failure 400 {object} helpers.BaseResponse
 // @Failure 401 {object} helpers.BaseResponse
@@ -190,12 +199,12 @@ func (h *AuthHandler) ResetPassword(c echo.Context) error {
 func (h *AuthHandler) ChangePassword(c echo.Context) error {
 	userID, ok := c.Get("user_id").(int)
 	if !ok {
-		return helpers.ResponseHttp(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return helpers.ErrUnauthorized("Unauthorized")
 	}
 
 	var req dto.ChangePasswordRequest
 	if err := c.Bind(&req); err != nil {
-		return helpers.ResponseHttp(c, http.StatusBadRequest, "Invalid request body", nil)
+		return helpers.ErrBadRequest("Invalid request body")
 	}
 
 	if err := h.validate.Struct(req); err != nil {
@@ -208,7 +217,7 @@ func (h *AuthHandler) ChangePassword(c echo.Context) error {
 	}
 
 	return helpers.ResponseHttp(c, http.StatusOK, "Password changed successfully", dto.MessageResponse{
-		Message: "Your password has been changed successfully",
+		Message: i18n.T(c.Request().Context(), "Your password has been changed successfully"),
 	})
 }
 
@@ -226,12 +235,12 @@ func (h *AuthHandler) ChangePassword(c echo.Context) error {
 func (h *AuthHandler) Logout(c echo.Context) error {
 	userID, ok := c.Get("user_id").(int)
 	if !ok {
-		return helpers.ResponseHttp(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return helpers.ErrUnauthorized("Unauthorized")
 	}
 
 	token, ok := c.Get("token").(string)
 	if !ok {
-		return helpers.ResponseHttp(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return helpers.ErrUnauthorized("Unauthorized")
 	}
 
 	err := h.authService.Logout(c.Request().Context(), userID, token)
@@ -240,7 +249,7 @@ func (h *AuthHandler) Logout(c echo.Context) error {
 	}
 
 	return helpers.ResponseHttp(c, http.StatusOK, "Logout successful", dto.MessageResponse{
-		Message: "You have been logged out successfully",
+		Message: i18n.T(c.Request().Context(), "You have been logged out successfully"),
 	})
 }
 
@@ -258,7 +267,7 @@ func (h *AuthHandler) Logout(c echo.Context) error {
 func (h *AuthHandler) GetProfile(c echo.Context) error {
 	userID, ok := c.Get("user_id").(int)
 	if !ok {
-		return helpers.ResponseHttp(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return helpers.ErrUnauthorized("Unauthorized")
 	}
 
 	response, err := h.authService.GetProfile(c.Request().Context(), userID)
@@ -268,3 +277,39 @@ func (h *AuthHandler) GetProfile(c echo.Context) error {
 
 	return helpers.ResponseHttp(c, http.StatusOK, "Profile retrieved successfully", response)
 }
+
+// UpdateProfile godoc
+// @Summary Update user profile
+// @Description Update full name, date of birth or address of the authenticated user. Omitted fields are unchanged
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdateProfileRequest true "Profile fields"
+// @Success 200 {object} helpers.BaseResponse{data=dto.UserResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 401 {object} helpers.BaseResponse
+// @Failure 500 {object} helpers.BaseResponse
+// @Router /v1/auth/profile [patch]
+func (h *AuthHandler) UpdateProfile(c echo.Context) error {
+	userID, ok := c.Get("user_id").(int)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	var req dto.UpdateProfileRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	response, err := h.authService.UpdateProfile(c.Request().Context(), userID, &req)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Profile updated successfully", response)
+}
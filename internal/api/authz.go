@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/labstack/echo/v4"
+)
+
+type AuthzHandler struct {
+	authzService interfaces.IAuthzService
+	validate     *validator.Validate
+}
+
+func NewAuthzHandler(authzService interfaces.IAuthzService) *AuthzHandler {
+	return &AuthzHandler{
+		authzService: authzService,
+		validate:     validator.New(),
+	}
+}
+
+// Check godoc
+// @Summary Check authorization
+// @Description Evaluate the authorization policies for the bearer token subject.
+// @Description Other services forward the end user's access token and describe the action and resource.
+// @Tags Authorization
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.AuthzCheckRequest true "Action and resource"
+// @Success 200 {object} helpers.BaseResponse{data=dto.AuthzCheckResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 401 {object} helpers.BaseResponse
+// @Router /v1/authz/check [post]
+func (h *AuthzHandler) Check(c echo.Context) error {
+	claims, ok := c.Get("claims").(*helpers.JWTClaims)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	var req dto.AuthzCheckRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	if req.Environment == nil {
+		req.Environment = map[string]interface{}{}
+	}
+	if _, ok := req.Environment["ip"]; !ok {
+		req.Environment["ip"] = c.RealIP()
+	}
+
+	response := h.authzService.Check(c.Request().Context(), claims, &req)
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Authorization evaluated", response)
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/labstack/echo/v4"
+)
+
+type ConsentHandler struct {
+	consentService interfaces.IConsentService
+	validate       *validator.Validate
+}
+
+func NewConsentHandler(consentService interfaces.IConsentService) *ConsentHandler {
+	return &ConsentHandler{
+		consentService: consentService,
+		validate:       helpers.GetValidator(),
+	}
+}
+
+// ListDocuments godoc
+// @Summary List legal documents
+// @Description List the current version of the terms of service and privacy policy. Registration requires accepting these versions
+// @Tags Consent
+// @Produce json
+// @Success 200 {object} helpers.BaseResponse{data=[]dto.LegalDocumentResponse}
+// @Router /v1/legal/documents [get]
+func (h *ConsentHandler) ListDocuments(c echo.Context) error {
+	response, err := h.consentService.ListCurrentDocuments(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Legal documents retrieved successfully", response)
+}
+
+// GetConsents godoc
+// @Summary Get consents
+// @Description Get accepted legal document versions, documents that must be accepted again and marketing consent
+// @Tags Consent
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} helpers.BaseResponse{data=dto.ConsentStatusResponse}
+// @Failure 401 {object} helpers.BaseResponse
+// @Router /v1/auth/consents [get]
+func (h *ConsentHandler) GetConsents(c echo.Context) error {
+	userID, ok := c.Get("user_id").(int)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	response, err := h.consentService.GetStatus(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Consents retrieved successfully", response)
+}
+
+// UpdateConsents godoc
+// @Summary Update consents
+// @Description Accept the current terms of service / privacy policy, or grant or withdraw marketing consent
+// @Tags Consent
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdateConsentRequest true "Accepted versions and marketing choices"
+// @Success 200 {object} helpers.BaseResponse{data=dto.ConsentStatusResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 401 {object} helpers.BaseResponse
+// @Router /v1/auth/consents [post]
+func (h *ConsentHandler) UpdateConsents(c echo.Context) error {
+	userID, ok := c.Get("user_id").(int)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	var req dto.UpdateConsentRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+	req.IPAddress = c.RealIP()
+	req.UserAgent = c.Request().UserAgent()
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	response, err := h.consentService.UpdateConsent(c.Request().Context(), userID, &req)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Consents updated successfully", response)
+}
+
+// PublishDocument godoc
+// @Summary Publish legal document
+// @Description Publish a new terms of service or privacy policy version. Users are asked to accept it on their next login
+// @Tags Consent
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.PublishLegalDocumentRequest true "Document version"
+// @Success 201 {object} helpers.BaseResponse{data=dto.LegalDocumentResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 403 {object} helpers.BaseResponse
+// @Failure 409 {object} helpers.BaseResponse
+// @Router /v1/admin/legal/documents [post]
+func (h *ConsentHandler) PublishDocument(c echo.Context) error {
+	var req dto.PublishLegalDocumentRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	response, err := h.consentService.PublishDocument(c.Request().Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusCreated, "Legal document published", response)
+}
+
+// ListMarketingConsents godoc
+// @Summary List marketing consents
+// @Description List the current marketing consent of each user for CRM sync, ordered by last change
+// @Tags Consent
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "marketing_email or marketing_sms"
+// @Param granted query string false "true or false"
+// @Param updated_since query string false "RFC 3339 timestamp"
+// @Param page query int false "Page, starting at 1"
+// @Param limit query int false "Page size, max 1000"
+// @Success 200 {object} helpers.BaseResponse{data=[]models.MarketingConsent}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 403 {object} helpers.BaseResponse
+// @Router /v1/admin/consents/marketing [get]
+func (h *ConsentHandler) ListMarketingConsents(c echo.Context) error {
+	var query dto.MarketingConsentQuery
+	if err := c.Bind(&query); err != nil {
+		return helpers.ErrBadRequest("Invalid query parameters")
+	}
+
+	if err := h.validate.Struct(query); err != nil {
+		return err
+	}
+
+	response, err := h.consentService.ListMarketingConsents(c.Request().Context(), &query)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Marketing consents retrieved successfully", response)
+}
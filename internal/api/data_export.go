@@ -0,0 +1,104 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/labstack/echo/v4"
+)
+
+type DataExportHandler struct {
+	dataExportService interfaces.IDataExportService
+	validate          *validator.Validate
+}
+
+func NewDataExportHandler(dataExportService interfaces.IDataExportService) *DataExportHandler {
+	return &DataExportHandler{
+		dataExportService: dataExportService,
+		validate:          helpers.GetValidator(),
+	}
+}
+
+// RequestExport godoc
+// @Summary Request data export
+// @Description Queue an export of all personal data as a JSON+CSV zip archive. The user is emailed a download link when it is ready
+// @Tags Data Export
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} helpers.BaseResponse{data=dto.DataExportResponse}
+// @Failure 401 {object} helpers.BaseResponse
+// @Failure 409 {object} helpers.BaseResponse
+// @Router /v1/users/me/exports [post]
+func (h *DataExportHandler) RequestExport(c echo.Context) error {
+	userID, ok := c.Get("user_id").(int)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	response, err := h.dataExportService.RequestExport(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusAccepted, "Data export requested", response)
+}
+
+// ListExports godoc
+// @Summary List data exports
+// @Description List the user's data exports. Ready exports include a signed download link valid for 24 hours
+// @Tags Data Export
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} helpers.BaseResponse{data=[]dto.DataExportResponse}
+// @Failure 401 {object} helpers.BaseResponse
+// @Router /v1/users/me/exports [get]
+func (h *DataExportHandler) ListExports(c echo.Context) error {
+	userID, ok := c.Get("user_id").(int)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	response, err := h.dataExportService.ListExports(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Data exports retrieved successfully", response)
+}
+
+// Download godoc
+// @Summary Download data export
+// @Description Download a data export archive through a signed link
+// @Tags Data Export
+// @Produce application/zip
+// @Param id path int true "Export ID"
+// @Param expires query int true "Link expiry as Unix time"
+// @Param signature query string true "Link signature"
+// @Success 200 {file} file
+// @Failure 403 {object} helpers.BaseResponse
+// @Failure 404 {object} helpers.BaseResponse
+// @Router /v1/exports/{id}/download [get]
+func (h *DataExportHandler) Download(c echo.Context) error {
+	var req dto.DataExportDownloadRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid download link")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	archive, fileName, err := h.dataExportService.Download(c.Request().Context(), &req)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.Stream(http.StatusOK, "application/zip", archive)
+}
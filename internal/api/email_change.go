@@ -0,0 +1,125 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/labstack/echo/v4"
+)
+
+type EmailChangeHandler struct {
+	emailChangeService interfaces.IEmailChangeService
+	validate           *validator.Validate
+}
+
+func NewEmailChangeHandler(emailChangeService interfaces.IEmailChangeService) *EmailChangeHandler {
+	return &EmailChangeHandler{
+		emailChangeService: emailChangeService,
+		validate:           helpers.GetValidator(),
+	}
+}
+
+// RequestChange godoc
+// @Summary Request email change
+// @Description Send a confirmation link to the new email and a cancel link to the current email. The email is only changed after confirmation
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.RequestEmailChangeRequest true "New email and current password"
+// @Param Idempotency-Key header string false "Makes retries replay the first response"
+// @Success 200 {object} helpers.BaseResponse{data=dto.MessageResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 401 {object} helpers.BaseResponse
+// @Failure 409 {object} helpers.BaseResponse
+// @Failure 500 {object} helpers.BaseResponse
+// @Router /v1/auth/email-change [post]
+func (h *EmailChangeHandler) RequestChange(c echo.Context) error {
+	userID, ok := c.Get("user_id").(int)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	var req dto.RequestEmailChangeRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	if err := h.emailChangeService.RequestChange(c.Request().Context(), userID, &req); err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Email change requested", dto.MessageResponse{
+		Message: i18n.T(c.Request().Context(), "Please check your new email to confirm the change"),
+	})
+}
+
+// ConfirmChange godoc
+// @Summary Confirm email change
+// @Description Apply a pending email change. All sessions are revoked afterwards
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.EmailChangeTokenRequest true "Confirmation token"
+// @Success 200 {object} helpers.BaseResponse{data=dto.MessageResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 409 {object} helpers.BaseResponse
+// @Failure 500 {object} helpers.BaseResponse
+// @Router /v1/auth/email-change/confirm [post]
+func (h *EmailChangeHandler) ConfirmChange(c echo.Context) error {
+	var req dto.EmailChangeTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	if err := h.emailChangeService.Confirm(c.Request().Context(), &req); err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Email changed successfully", dto.MessageResponse{
+		Message: i18n.T(c.Request().Context(), "Your email has been changed. Please login again"),
+	})
+}
+
+// CancelChange godoc
+// @Summary Cancel email change
+// @Description Cancel a pending email change, or restore the previous email of a recently confirmed change. All sessions are revoked after a restore
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.EmailChangeTokenRequest true "Cancel token"
+// @Success 200 {object} helpers.BaseResponse{data=dto.MessageResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 409 {object} helpers.BaseResponse
+// @Failure 500 {object} helpers.BaseResponse
+// @Router /v1/auth/email-change/cancel [post]
+func (h *EmailChangeHandler) CancelChange(c echo.Context) error {
+	var req dto.EmailChangeTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	if err := h.emailChangeService.Cancel(c.Request().Context(), &req); err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Email change cancelled", dto.MessageResponse{
+		Message: i18n.T(c.Request().Context(), "The email change has been cancelled"),
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
 	"github.com/labstack/echo/v4"
 )
 
@@ -12,6 +13,11 @@ type home struct {
 }
 
 type HealthCheckAPI struct {
+	healthService interfaces.IHealthService
+}
+
+func NewHealthCheckAPI(healthService interfaces.IHealthService) *HealthCheckAPI {
+	return &HealthCheckAPI{healthService: healthService}
 }
 
 // HealthCheck godoc
@@ -26,3 +32,32 @@ type HealthCheckAPI struct {
 func (api *HealthCheckAPI) HealthCheck(e echo.Context) error {
 	return helpers.ResponseHttp(e, http.StatusOK, "Healthty", home{Title: "Welcome to the auth service simple ecommerce"})
 }
+
+// Live godoc
+//
+//	@Summary		Liveness probe
+//	@Description	Report that the process is running, without checking dependencies
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	helpers.BaseResponse{data=dto.HealthResponse}
+//	@Router			/health/live [get]
+func (api *HealthCheckAPI) Live(e echo.Context) error {
+	return helpers.ResponseHttp(e, http.StatusOK, "Alive", api.healthService.Live(e.Request().Context()))
+}
+
+// Ready godoc
+//
+//	@Summary		Readiness probe
+//	@Description	Check the database, migrations and mailer. Returns 503 when one is down or the server is shutting down
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	helpers.BaseResponse{data=dto.HealthResponse}
+//	@Failure		503	{object}	helpers.BaseResponse{data=dto.HealthResponse}
+//	@Router			/health/ready [get]
+func (api *HealthCheckAPI) Ready(e echo.Context) error {
+	response, ready := api.healthService.Ready(e.Request().Context())
+	if !ready {
+		return helpers.ResponseHttp(e, http.StatusServiceUnavailable, "Not ready", response)
+	}
+	return helpers.ResponseHttp(e, http.StatusOK, "Ready", response)
+}
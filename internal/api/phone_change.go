@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/labstack/echo/v4"
+)
+
+type PhoneChangeHandler struct {
+	phoneChangeService interfaces.IPhoneChangeService
+	validate           *validator.Validate
+}
+
+func NewPhoneChangeHandler(phoneChangeService interfaces.IPhoneChangeService) *PhoneChangeHandler {
+	return &PhoneChangeHandler{
+		phoneChangeService: phoneChangeService,
+		validate:           helpers.GetValidator(),
+	}
+}
+
+// RequestChange godoc
+// @Summary Request phone number change
+// @Description Send an OTP to the new phone number. The number is normalized to E.164
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.RequestPhoneChangeRequest true "New phone number and current password"
+// @Param Idempotency-Key header string false "Makes retries replay the first response"
+// @Success 200 {object} helpers.BaseResponse{data=dto.MessageResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 401 {object} helpers.BaseResponse
+// @Failure 409 {object} helpers.BaseResponse
+// @Failure 500 {object} helpers.BaseResponse
+// @Router /v1/auth/phone-change [post]
+func (h *PhoneChangeHandler) RequestChange(c echo.Context) error {
+	userID, ok := c.Get("user_id").(int)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	var req dto.RequestPhoneChangeRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	if err := h.phoneChangeService.RequestChange(c.Request().Context(), userID, &req); err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "OTP sent", dto.MessageResponse{
+		Message: i18n.T(c.Request().Context(), "Please enter the OTP sent to your new phone number"),
+	})
+}
+
+// VerifyChange godoc
+// @Summary Verify phone number change
+// @Description Verify the OTP and apply the pending phone number change
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.VerifyPhoneChangeRequest true "OTP"
+// @Success 200 {object} helpers.BaseResponse{data=dto.MessageResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 401 {object} helpers.BaseResponse
+// @Failure 409 {object} helpers.BaseResponse
+// @Failure 500 {object} helpers.BaseResponse
+// @Router /v1/auth/phone-change/verify [post]
+func (h *PhoneChangeHandler) VerifyChange(c echo.Context) error {
+	userID, ok := c.Get("user_id").(int)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	var req dto.VerifyPhoneChangeRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	if err := h.phoneChangeService.Verify(c.Request().Context(), userID, &req); err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Phone number changed successfully", dto.MessageResponse{
+		Message: i18n.T(c.Request().Context(), "Your phone number has been changed"),
+	})
+}
@@ -0,0 +1,297 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/labstack/echo/v4"
+)
+
+type RBACHandler struct {
+	rbacService interfaces.IRBACService
+	validate    *validator.Validate
+}
+
+func NewRBACHandler(rbacService interfaces.IRBACService) *RBACHandler {
+	return &RBACHandler{
+		rbacService: rbacService,
+		validate:    validator.New(),
+	}
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description List all roles with their permissions
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} helpers.BaseResponse{data=[]dto.RoleResponse}
+// @Failure 401 {object} helpers.BaseResponse
+// @Failure 403 {object} helpers.BaseResponse
+// @Router /v1/admin/roles [get]
+func (h *RBACHandler) ListRoles(c echo.Context) error {
+	response, err := h.rbacService.ListRoles(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Roles retrieved successfully", response)
+}
+
+// ListPermissions godoc
+// @Summary List permissions
+// @Description List all permissions
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} helpers.BaseResponse{data=[]dto.PermissionResponse}
+// @Failure 401 {object} helpers.BaseResponse
+// @Failure 403 {object} helpers.BaseResponse
+// @Router /v1/admin/permissions [get]
+func (h *RBACHandler) ListPermissions(c echo.Context) error {
+	response, err := h.rbacService.ListPermissions(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Permissions retrieved successfully", response)
+}
+
+// CreatePermission godoc
+// @Summary Create permission
+// @Description Create a new permission. Roles and permissions are shared by every tenant, only a platform superadmin may change them
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreatePermissionRequest true "Permission"
+// @Success 201 {object} helpers.BaseResponse{data=dto.PermissionResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 403 {object} helpers.BaseResponse
+// @Failure 409 {object} helpers.BaseResponse
+// @Router /v1/admin/permissions [post]
+func (h *RBACHandler) CreatePermission(c echo.Context) error {
+	claims, ok := c.Get("claims").(*helpers.JWTClaims)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	var req dto.CreatePermissionRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	response, err := h.rbacService.CreatePermission(c.Request().Context(), claims, &req)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusCreated, "Permission created successfully", response)
+}
+
+// SetRolePermissions godoc
+// @Summary Replace role permissions
+// @Description Replace every permission of a role. Only a platform superadmin may change roles
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param role path string true "Role name"
+// @Param request body dto.SetRolePermissionsRequest true "Permissions"
+// @Success 200 {object} helpers.BaseResponse{data=dto.RoleResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 403 {object} helpers.BaseResponse
+// @Failure 404 {object} helpers.BaseResponse
+// @Router /v1/admin/roles/{role}/permissions [put]
+func (h *RBACHandler) SetRolePermissions(c echo.Context) error {
+	claims, ok := c.Get("claims").(*helpers.JWTClaims)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	var req dto.SetRolePermissionsRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	response, err := h.rbacService.SetRolePermissions(c.Request().Context(), claims, c.Param("role"), &req)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Role permissions updated successfully", response)
+}
+
+// GrantPermission godoc
+// @Summary Grant permission to role
+// @Description Add a permission to a role. Only a platform superadmin may change roles
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param role path string true "Role name"
+// @Param request body dto.GrantPermissionRequest true "Permission"
+// @Success 200 {object} helpers.BaseResponse{data=dto.RoleResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 403 {object} helpers.BaseResponse
+// @Failure 404 {object} helpers.BaseResponse
+// @Router /v1/admin/roles/{role}/permissions [post]
+func (h *RBACHandler) GrantPermission(c echo.Context) error {
+	claims, ok := c.Get("claims").(*helpers.JWTClaims)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	var req dto.GrantPermissionRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	response, err := h.rbacService.GrantPermission(c.Request().Context(), claims, c.Param("role"), &req)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Permission granted successfully", response)
+}
+
+// RevokePermission godoc
+// @Summary Revoke permission from role
+// @Description Remove a permission from a role. Only a platform superadmin may change roles
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param role path string true "Role name"
+// @Param permission path string true "Permission name"
+// @Success 200 {object} helpers.BaseResponse{data=dto.RoleResponse}
+// @Failure 403 {object} helpers.BaseResponse
+// @Failure 404 {object} helpers.BaseResponse
+// @Router /v1/admin/roles/{role}/permissions/{permission} [delete]
+func (h *RBACHandler) RevokePermission(c echo.Context) error {
+	claims, ok := c.Get("claims").(*helpers.JWTClaims)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	response, err := h.rbacService.RevokePermission(c.Request().Context(), claims, c.Param("role"), c.Param("permission"))
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Permission revoked successfully", response)
+}
+
+// GetUserRoles godoc
+// @Summary Get user roles
+// @Description Get the roles and effective permissions of a user
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} helpers.BaseResponse{data=dto.UserAccessResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 404 {object} helpers.BaseResponse
+// @Router /v1/admin/users/{id}/roles [get]
+func (h *RBACHandler) GetUserRoles(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return helpers.ErrBadRequest("Invalid user id")
+	}
+
+	response, err := h.rbacService.GetUserRoles(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "User roles retrieved successfully", response)
+}
+
+// AssignUserRole godoc
+// @Summary Assign role to user
+// @Description Assign a role to a user. Takes effect on the user's next login or token refresh
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body dto.AssignRoleRequest true "Role"
+// @Success 200 {object} helpers.BaseResponse{data=dto.UserAccessResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 403 {object} helpers.BaseResponse
+// @Failure 404 {object} helpers.BaseResponse
+// @Router /v1/admin/users/{id}/roles [post]
+func (h *RBACHandler) AssignUserRole(c echo.Context) error {
+	claims, ok := c.Get("claims").(*helpers.JWTClaims)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return helpers.ErrBadRequest("Invalid user id")
+	}
+
+	var req dto.AssignRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	response, err := h.rbacService.AssignUserRole(c.Request().Context(), claims, userID, &req)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Role assigned successfully", response)
+}
+
+// RevokeUserRole godoc
+// @Summary Revoke role from user
+// @Description Remove a role from a user
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param role path string true "Role name"
+// @Success 200 {object} helpers.BaseResponse{data=dto.UserAccessResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 403 {object} helpers.BaseResponse
+// @Failure 404 {object} helpers.BaseResponse
+// @Router /v1/admin/users/{id}/roles/{role} [delete]
+func (h *RBACHandler) RevokeUserRole(c echo.Context) error {
+	claims, ok := c.Get("claims").(*helpers.JWTClaims)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return helpers.ErrBadRequest("Invalid user id")
+	}
+
+	response, err := h.rbacService.RevokeUserRole(c.Request().Context(), claims, userID, c.Param("role"))
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Role revoked successfully", response)
+}
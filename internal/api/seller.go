@@ -0,0 +1,240 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/labstack/echo/v4"
+)
+
+type SellerHandler struct {
+	sellerService interfaces.ISellerService
+	validate      *validator.Validate
+}
+
+func NewSellerHandler(sellerService interfaces.ISellerService) *SellerHandler {
+	return &SellerHandler{
+		sellerService: sellerService,
+		validate:      validator.New(),
+	}
+}
+
+// Apply godoc
+// @Summary Apply to become a seller
+// @Description Submit a seller application with KYC documents (JPEG, PNG or PDF, max 5 MB each)
+// @Tags Seller
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param store_name formData string true "Store name"
+// @Param nik formData string true "NIK (16 digits)"
+// @Param npwp formData string true "NPWP (15 or 16 digits)"
+// @Param ktp formData file true "KTP document"
+// @Param npwp_document formData file true "NPWP document"
+// @Success 201 {object} helpers.BaseResponse{data=dto.SellerApplicationResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 409 {object} helpers.BaseResponse
+// @Router /v1/seller/applications [post]
+func (h *SellerHandler) Apply(c echo.Context) error {
+	userID, ok := c.Get("user_id").(int)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	var req dto.SellerApplicationRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	var documents []dto.DocumentUpload
+	for docType, field := range map[string]string{"ktp": "ktp", "npwp": "npwp_document"} {
+		file, err := c.FormFile(field)
+		if err != nil {
+			continue
+		}
+		documents = append(documents, dto.DocumentUpload{Type: docType, File: file})
+	}
+
+	response, err := h.sellerService.Apply(c.Request().Context(), userID, &req, documents)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusCreated, "Application submitted successfully", response)
+}
+
+// GetMyApplication godoc
+// @Summary Get my seller application
+// @Description Get the status of the latest seller application
+// @Tags Seller
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} helpers.BaseResponse{data=dto.SellerApplicationResponse}
+// @Failure 404 {object} helpers.BaseResponse
+// @Router /v1/seller/applications/me [get]
+func (h *SellerHandler) GetMyApplication(c echo.Context) error {
+	userID, ok := c.Get("user_id").(int)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	response, err := h.sellerService.GetMyApplication(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Application retrieved successfully", response)
+}
+
+// ListApplications godoc
+// @Summary List seller applications
+// @Description List seller applications for review
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by status (pending, approved, rejected)"
+// @Success 200 {object} helpers.BaseResponse{data=[]dto.SellerApplicationResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Router /v1/admin/seller-applications [get]
+func (h *SellerHandler) ListApplications(c echo.Context) error {
+	response, err := h.sellerService.ListApplications(c.Request().Context(), c.QueryParam("status"))
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Applications retrieved successfully", response)
+}
+
+// GetApplication godoc
+// @Summary Get seller application
+// @Description Get a seller application for review
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Success 200 {object} helpers.BaseResponse{data=dto.SellerApplicationResponse}
+// @Failure 404 {object} helpers.BaseResponse
+// @Router /v1/admin/seller-applications/{id} [get]
+func (h *SellerHandler) GetApplication(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return helpers.ErrBadRequest("Invalid application id")
+	}
+
+	response, err := h.sellerService.GetApplication(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "Application retrieved successfully", response)
+}
+
+// Approve godoc
+// @Summary Approve seller application
+// @Description Approve a pending application and grant the seller role.
+// @Description The role is granted in the same transaction; applications that are not pending return 409.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Param request body dto.ReviewSellerApplicationRequest false "Reviewer notes"
+// @Success 200 {object} helpers.BaseResponse{data=dto.SellerApplicationResponse}
+// @Failure 404 {object} helpers.BaseResponse
+// @Failure 409 {object} helpers.BaseResponse
+// @Router /v1/admin/seller-applications/{id}/approve [post]
+func (h *SellerHandler) Approve(c echo.Context) error {
+	return h.review(c, h.sellerService.Approve, "Application approved")
+}
+
+// Reject godoc
+// @Summary Reject seller application
+// @Description Reject a pending application, notes are required
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Param request body dto.ReviewSellerApplicationRequest true "Reviewer notes"
+// @Success 200 {object} helpers.BaseResponse{data=dto.SellerApplicationResponse}
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 404 {object} helpers.BaseResponse
+// @Failure 409 {object} helpers.BaseResponse
+// @Router /v1/admin/seller-applications/{id}/reject [post]
+func (h *SellerHandler) Reject(c echo.Context) error {
+	return h.review(c, h.sellerService.Reject, "Application rejected")
+}
+
+// GetDocument godoc
+// @Summary Download KYC document
+// @Description Download a document attached to a seller application
+// @Tags Admin
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path int true "Application ID"
+// @Param documentId path int true "Document ID"
+// @Success 200 {file} file
+// @Failure 404 {object} helpers.BaseResponse
+// @Router /v1/admin/seller-applications/{id}/documents/{documentId} [get]
+func (h *SellerHandler) GetDocument(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return helpers.ErrBadRequest("Invalid application id")
+	}
+	documentID, err := strconv.Atoi(c.Param("documentId"))
+	if err != nil {
+		return helpers.ErrBadRequest("Invalid document id")
+	}
+
+	doc, reader, err := h.sellerService.OpenDocument(c.Request().Context(), id, documentID)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename="+strconv.Quote(doc.FileName))
+	c.Response().Header().Set(echo.HeaderContentType, doc.ContentType)
+	c.Response().WriteHeader(http.StatusOK)
+	_, err = io.Copy(c.Response(), reader)
+	return err
+}
+
+type reviewFunc func(ctx context.Context, reviewerID, id int, req *dto.ReviewSellerApplicationRequest) (*dto.SellerApplicationResponse, error)
+
+func (h *SellerHandler) review(c echo.Context, decide reviewFunc, message string) error {
+	reviewerID, ok := c.Get("user_id").(int)
+	if !ok {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return helpers.ErrBadRequest("Invalid application id")
+	}
+
+	var req dto.ReviewSellerApplicationRequest
+	if err := c.Bind(&req); err != nil {
+		return helpers.ErrBadRequest("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return err
+	}
+
+	response, err := decide(c.Request().Context(), reviewerID, id, &req)
+	if err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, message, response)
+}
@@ -1,58 +1,81 @@
 package api
 
 import (
-	"errors"
 	"net/http"
+	"strconv"
 
-	"github.com/go-playground/validator/v10"
-	"github.com/ibnuzaman/auth-simple-ecommerce.git/constants"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
-	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
-	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/services"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
 	"github.com/labstack/echo/v4"
 )
 
-type UserAPI struct {
-	UserService services.UserService
+// UserAdminHandler exposes the user lifecycle to administrators
+type UserAdminHandler struct {
+	users interfaces.IUserLifecycleService
 }
 
-// RegisterUser godoc
-//
-//	@Summary		Register a new user
-//	@Description	Register a new customer user account
-//	@Tags			users
-//	@Accept			json
-//	@Produce		json
-//	@Param			user	body		models.User				true	"User registration details"
-//	@Success		200		{object}	helpers.BaseResponse	"Successfully registered user"
-//	@Failure		400		{object}	helpers.BaseResponse	"Bad request - invalid input"
-//	@Failure		500		{object}	helpers.BaseResponse	"Internal server error"
-//	@Router			/api/v1/auth/register [post]
-var validate = validator.New()
-
-func (api *UserAPI) RegisterUser(c echo.Context) error {
-	var req dto.RegisterRequest
-	if err := c.Bind(&req); err != nil {
-		return helpers.ResponseHttp(c, http.StatusBadRequest, constants.ErrBadRequest, nil)
+func NewUserAdminHandler(users interfaces.IUserLifecycleService) *UserAdminHandler {
+	return &UserAdminHandler{users: users}
+}
+
+// Activate godoc
+// @Summary Activate user
+// @Description Let a deactivated user log in again
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} helpers.BaseResponse
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 404 {object} helpers.BaseResponse
+// @Router /v1/admin/users/{id}/activate [post]
+func (h *UserAdminHandler) Activate(c echo.Context) error {
+	user, err := h.findUser(c)
+	if err != nil {
+		return err
+	}
+	if err := h.users.Activate(c.Request().Context(), user); err != nil {
+		return err
+	}
+
+	return helpers.ResponseHttp(c, http.StatusOK, "User activated successfully", nil)
+}
+
+// Deactivate godoc
+// @Summary Deactivate user
+// @Description Block a user from logging in and revoke every session
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} helpers.BaseResponse
+// @Failure 400 {object} helpers.BaseResponse
+// @Failure 404 {object} helpers.BaseResponse
+// @Router /v1/admin/users/{id}/deactivate [post]
+func (h *UserAdminHandler) Deactivate(c echo.Context) error {
+	user, err := h.findUser(c)
+	if err != nil {
+		return err
 	}
-	if err := validate.Struct(req); err != nil {
-		return helpers.ResponseHttp(c, http.StatusBadRequest, constants.ErrBadRequest, echo.Map{
-			"details": err.Error(),
-		})
+	if err := h.users.Deactivate(c.Request().Context(), user); err != nil {
+		return err
 	}
 
-	resp, err := api.UserService.Register(c.Request().Context(), req, constants.RoleCustomer)
+	return helpers.ResponseHttp(c, http.StatusOK, "User deactivated successfully", nil)
+}
+
+// findUser finds the user of the id path parameter in the request tenant
+func (h *UserAdminHandler) findUser(c echo.Context) (*models.User, error) {
+	userID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		var cf *constants.ConflictError
-		if errors.As(err, &cf) {
-			return helpers.ResponseHttp(c, http.StatusConflict, constants.ErrDuplicate, echo.Map{
-				"field":   cf.Field,
-				"message": cf.Field + " already in use",
-			})
-		}
-		return helpers.ResponseHttp(c, http.StatusInternalServerError, constants.ErrServerError, nil)
+		return nil, helpers.ErrBadRequest("Invalid user id")
 	}
 
-	// 201 Created lebih tepat untuk registrasi
-	return helpers.ResponseHttp(c, http.StatusCreated, constants.SuccessMessage, resp)
+	ctx := c.Request().Context()
+	tenant := helpers.TenantFromContext(ctx)
+	if tenant == nil {
+		return nil, helpers.NewCodeError(helpers.CodeTenantUnknown, "Unknown tenant")
+	}
+	return h.users.Find(ctx, tenant.ID, userID)
 }
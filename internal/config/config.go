@@ -0,0 +1,299 @@
+// Package config defines the typed service configuration. Values are layered
+// from defaults, an optional YAML file, environment variables and command
+// line flags, each overriding the previous one.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
+	"github.com/labstack/gommon/bytes"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// minSecretLength is the minimum length of signing secrets
+const minSecretLength = 32
+
+// Config is the configuration of the service. The yaml tag is the key in the
+// config file and, joined with the section, the flag name; env is the
+// environment variable. Fields tagged secret are redacted when printed.
+type Config struct {
+	App      AppConfig      `yaml:"app"`
+	HTTP     HTTPConfig     `yaml:"http"`
+	Database DatabaseConfig `yaml:"database"`
+	Auth     AuthConfig     `yaml:"auth"`
+	PII      PIIConfig      `yaml:"pii"`
+	SMTP     SMTPConfig     `yaml:"smtp"`
+	Storage  StorageConfig  `yaml:"storage"`
+	Jobs     JobsConfig     `yaml:"jobs"`
+	Tracing  TracingConfig  `yaml:"tracing"`
+	Log      LogConfig      `yaml:"log"`
+}
+
+type AppConfig struct {
+	URL        string `yaml:"url" env:"APP_URL"`
+	APIURL     string `yaml:"api_url" env:"API_URL"`
+	Secret     string `yaml:"secret" env:"APP_SECRET" secret:"true"`
+	PolicyFile string `yaml:"policy_file" env:"POLICY_FILE"`
+	// DefaultLocale is the language used when neither Accept-Language nor
+	// the user's preference chose one, "en" or "id"
+	DefaultLocale string `yaml:"default_locale" env:"DEFAULT_LOCALE"`
+}
+
+type HTTPConfig struct {
+	Port int `yaml:"port" env:"PORT"`
+	// AdminPort serves /metrics apart from the API, 0 serves it on Port
+	AdminPort         int           `yaml:"admin_port" env:"ADMIN_PORT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT"`
+	// ShutdownDelay keeps serving after readiness turns 503 so load
+	// balancers notice before connections are refused
+	ShutdownDelay    time.Duration `yaml:"shutdown_delay" env:"HTTP_SHUTDOWN_DELAY"`
+	ReadinessTimeout time.Duration `yaml:"readiness_timeout" env:"HTTP_READINESS_TIMEOUT"`
+	MaxHeaderBytes   int           `yaml:"max_header_bytes" env:"HTTP_MAX_HEADER_BYTES"`
+	// BodyLimit is the largest accepted request body, e.g. "25M"
+	BodyLimit         string        `yaml:"body_limit" env:"HTTP_BODY_LIMIT"`
+	TLSCertFile       string        `yaml:"tls_cert_file" env:"TLS_CERT_FILE"`
+	TLSKeyFile        string        `yaml:"tls_key_file" env:"TLS_KEY_FILE"`
+	TLSReloadInterval time.Duration `yaml:"tls_reload_interval" env:"TLS_RELOAD_INTERVAL"`
+	// ErrorFormat is the default error body, "json" or "problem" for RFC 7807
+	// application/problem+json. Clients accepting problem+json always get it.
+	ErrorFormat string `yaml:"error_format" env:"HTTP_ERROR_FORMAT"`
+	// ProblemTypeBaseURL prefixes error codes to form the problem type, e.g.
+	// https://docs.example.com/errors/ (empty uses about:blank)
+	ProblemTypeBaseURL string `yaml:"problem_type_base_url" env:"HTTP_PROBLEM_TYPE_BASE_URL"`
+	// IdempotencyTTL is how long responses to POSTs with an Idempotency-Key
+	// are kept for replay
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl" env:"IDEMPOTENCY_TTL"`
+}
+
+// TLS reports whether the server should serve HTTPS
+func (h HTTPConfig) TLS() bool {
+	return h.TLSCertFile != ""
+}
+
+type DatabaseConfig struct {
+	Host        string `yaml:"host" env:"DB_HOST"`
+	Port        int    `yaml:"port" env:"DB_PORT"`
+	User        string `yaml:"user" env:"DB_USER"`
+	Password    string `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name        string `yaml:"name" env:"DB_NAME"`
+	SSLMode     string `yaml:"sslmode" env:"DB_SSLMODE"`
+	AutoMigrate bool   `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+}
+
+// DSN returns the Postgres connection string
+func (d DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s", d.Host, d.Port, d.User, d.Password, d.Name, d.SSLMode)
+}
+
+type AuthConfig struct {
+	JWTSecret        string        `yaml:"jwt_secret" env:"JWT_SECRET" secret:"true"`
+	JWTRefreshSecret string        `yaml:"jwt_refresh_secret" env:"JWT_REFRESH_SECRET" secret:"true"`
+	AccessTokenTTL   time.Duration `yaml:"access_token_ttl" env:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL  time.Duration `yaml:"refresh_token_ttl" env:"REFRESH_TOKEN_TTL"`
+	ResetTokenTTL    time.Duration `yaml:"reset_token_ttl" env:"RESET_TOKEN_TTL"`
+	BcryptCost       int           `yaml:"bcrypt_cost" env:"BCRYPT_COST"`
+}
+
+type PIIConfig struct {
+	MasterKeys       string        `yaml:"master_keys" env:"PII_MASTER_KEYS" secret:"true"`
+	MasterKeyFile    string        `yaml:"master_key_file" env:"PII_MASTER_KEY_FILE"`
+	ActiveKeyID      string        `yaml:"active_key_id" env:"PII_ACTIVE_KEY_ID"`
+	BlindIndexKey    string        `yaml:"blind_index_key" env:"PII_BLIND_INDEX_KEY" secret:"true"`
+	RotationInterval time.Duration `yaml:"rotation_interval" env:"PII_KEY_ROTATION_INTERVAL"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host" env:"SMTP_HOST"`
+	Port     int    `yaml:"port" env:"SMTP_PORT"`
+	Username string `yaml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD" secret:"true"`
+	From     string `yaml:"from" env:"SMTP_FROM"`
+}
+
+type StorageConfig struct {
+	BlobDir string `yaml:"blob_dir" env:"BLOB_STORAGE_DIR"`
+}
+
+type JobsConfig struct {
+	AccountDeletionGracePeriod time.Duration `yaml:"account_deletion_grace_period" env:"ACCOUNT_DELETION_GRACE_PERIOD"`
+	AccountDeletionInterval    time.Duration `yaml:"account_deletion_interval" env:"ACCOUNT_DELETION_INTERVAL"`
+	DataExportInterval         time.Duration `yaml:"data_export_interval" env:"DATA_EXPORT_INTERVAL"`
+}
+
+// Tracing exporters
+const (
+	TracingExporterNone   = "none"
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+	TracingExporterFile   = "file"
+)
+
+type TracingConfig struct {
+	Exporter     string  `yaml:"exporter" env:"TRACING_EXPORTER"`
+	OTLPEndpoint string  `yaml:"otlp_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	OTLPHeaders  string  `yaml:"otlp_headers" env:"OTEL_EXPORTER_OTLP_HEADERS" secret:"true"`
+	File         string  `yaml:"file" env:"TRACING_FILE"`
+	SampleRatio  float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+	ServiceName  string  `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+}
+
+type LogConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+	// Redact masks passwords, tokens and OTPs. Only turn it off locally,
+	// e.g. to follow links the log mailer prints.
+	Redact bool `yaml:"redact" env:"LOG_REDACT"`
+}
+
+// Default returns the configuration used when nothing is overridden
+func Default() *Config {
+	return &Config{
+		App: AppConfig{
+			URL:           "http://localhost:3000",
+			APIURL:        "http://localhost:9000/api",
+			PolicyFile:    "policies/policies.yaml",
+			DefaultLocale: "en",
+		},
+		HTTP: HTTPConfig{
+			Port:              9000,
+			ReadTimeout:       30 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   30 * time.Second,
+			ShutdownDelay:     5 * time.Second,
+			ReadinessTimeout:  2 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+			BodyLimit:         "25M",
+			TLSReloadInterval: time.Minute,
+			ErrorFormat:       "json",
+			IdempotencyTTL:    24 * time.Hour,
+		},
+		Database: DatabaseConfig{
+			Host:     "127.0.0.1",
+			Port:     5432,
+			User:     "auth_db",
+			Password: "password",
+			Name:     "auth_db",
+			SSLMode:  "disable",
+		},
+		Auth: AuthConfig{
+			AccessTokenTTL:  24 * time.Hour,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+			ResetTokenTTL:   time.Hour,
+			BcryptCost:      bcrypt.DefaultCost,
+		},
+		PII: PIIConfig{
+			RotationInterval: time.Hour,
+		},
+		SMTP: SMTPConfig{
+			Port: 587,
+			From: "no-reply@example.com",
+		},
+		Storage: StorageConfig{
+			BlobDir: "storage",
+		},
+		Jobs: JobsConfig{
+			AccountDeletionGracePeriod: 30 * 24 * time.Hour,
+			AccountDeletionInterval:    time.Hour,
+			DataExportInterval:         time.Minute,
+		},
+		Tracing: TracingConfig{
+			Exporter:     TracingExporterNone,
+			OTLPEndpoint: "http://localhost:4318",
+			File:         "traces.jsonl",
+			SampleRatio:  1,
+			ServiceName:  "auth-simple-ecommerce",
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+			Redact: true,
+		},
+	}
+}
+
+// Validate reports every invalid value at once
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(len(c.App.Secret) >= minSecretLength, "app.secret (APP_SECRET) must be at least %d characters", minSecretLength)
+	check(len(c.Auth.JWTSecret) >= minSecretLength, "auth.jwt_secret (JWT_SECRET) must be at least %d characters", minSecretLength)
+	check(c.Auth.JWTRefreshSecret == "" || len(c.Auth.JWTRefreshSecret) >= minSecretLength,
+		"auth.jwt_refresh_secret (JWT_REFRESH_SECRET) must be at least %d characters", minSecretLength)
+	check(c.Auth.BcryptCost >= bcrypt.MinCost && c.Auth.BcryptCost <= bcrypt.MaxCost,
+		"auth.bcrypt_cost (BCRYPT_COST) must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	check(c.Auth.RefreshTokenTTL > c.Auth.AccessTokenTTL,
+		"auth.refresh_token_ttl (REFRESH_TOKEN_TTL) must be longer than auth.access_token_ttl")
+
+	_, ok := i18n.Parse(c.App.DefaultLocale)
+	check(ok, "app.default_locale (DEFAULT_LOCALE) must be en or id")
+	check(validPort(c.HTTP.Port), "http.port (PORT) must be between 1 and 65535")
+	check(c.HTTP.ShutdownDelay >= 0, "http.shutdown_delay (HTTP_SHUTDOWN_DELAY) must not be negative")
+	check(c.HTTP.MaxHeaderBytes > 0, "http.max_header_bytes (HTTP_MAX_HEADER_BYTES) must be positive")
+	_, err := bytes.Parse(c.HTTP.BodyLimit)
+	check(err == nil, "http.body_limit (HTTP_BODY_LIMIT) must be a size such as 25M")
+	check((c.HTTP.TLSCertFile == "") == (c.HTTP.TLSKeyFile == ""),
+		"http.tls_cert_file (TLS_CERT_FILE) and http.tls_key_file (TLS_KEY_FILE) must be set together")
+	check(c.HTTP.AdminPort == 0 || validPort(c.HTTP.AdminPort) && c.HTTP.AdminPort != c.HTTP.Port,
+		"http.admin_port (ADMIN_PORT) must be 0 or a port other than http.port")
+	check(c.HTTP.ErrorFormat == "json" || c.HTTP.ErrorFormat == "problem", "http.error_format (HTTP_ERROR_FORMAT) must be json or problem")
+	check(validPort(c.Database.Port), "database.port (DB_PORT) must be between 1 and 65535")
+	check(c.SMTP.Host == "" || validPort(c.SMTP.Port), "smtp.port (SMTP_PORT) must be between 1 and 65535")
+	check(c.Database.Host != "" && c.Database.Name != "", "database.host and database.name are required")
+
+	switch c.Tracing.Exporter {
+	case TracingExporterNone, TracingExporterStdout:
+	case TracingExporterOTLP:
+		check(c.Tracing.OTLPEndpoint != "", "tracing.otlp_endpoint (OTEL_EXPORTER_OTLP_ENDPOINT) is required by the otlp exporter")
+	case TracingExporterFile:
+		check(c.Tracing.File != "", "tracing.file (TRACING_FILE) is required by the file exporter")
+	default:
+		check(false, "tracing.exporter (TRACING_EXPORTER) must be one of none, otlp, stdout or file")
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio (TRACING_SAMPLE_RATIO) must be between 0 and 1")
+
+	_, err = logrus.ParseLevel(c.Log.Level)
+	check(err == nil, "log.level (LOG_LEVEL) must be one of debug, info, warn or error")
+	check(c.Log.Format == "json" || c.Log.Format == "text", "log.format (LOG_FORMAT) must be json or text")
+
+	durations := map[string]time.Duration{
+		"http.read_timeout (HTTP_READ_TIMEOUT)":                              c.HTTP.ReadTimeout,
+		"http.read_header_timeout (HTTP_READ_HEADER_TIMEOUT)":                c.HTTP.ReadHeaderTimeout,
+		"http.write_timeout (HTTP_WRITE_TIMEOUT)":                            c.HTTP.WriteTimeout,
+		"http.idle_timeout (HTTP_IDLE_TIMEOUT)":                              c.HTTP.IdleTimeout,
+		"http.shutdown_timeout (HTTP_SHUTDOWN_TIMEOUT)":                      c.HTTP.ShutdownTimeout,
+		"http.readiness_timeout (HTTP_READINESS_TIMEOUT)":                    c.HTTP.ReadinessTimeout,
+		"http.tls_reload_interval (TLS_RELOAD_INTERVAL)":                     c.HTTP.TLSReloadInterval,
+		"http.idempotency_ttl (IDEMPOTENCY_TTL)":                             c.HTTP.IdempotencyTTL,
+		"auth.access_token_ttl (ACCESS_TOKEN_TTL)":                           c.Auth.AccessTokenTTL,
+		"auth.refresh_token_ttl (REFRESH_TOKEN_TTL)":                         c.Auth.RefreshTokenTTL,
+		"auth.reset_token_ttl (RESET_TOKEN_TTL)":                             c.Auth.ResetTokenTTL,
+		"pii.rotation_interval (PII_KEY_ROTATION_INTERVAL)":                  c.PII.RotationInterval,
+		"jobs.account_deletion_grace_period (ACCOUNT_DELETION_GRACE_PERIOD)": c.Jobs.AccountDeletionGracePeriod,
+		"jobs.account_deletion_interval (ACCOUNT_DELETION_INTERVAL)":         c.Jobs.AccountDeletionInterval,
+		"jobs.data_export_interval (DATA_EXPORT_INTERVAL)":                   c.Jobs.DataExportInterval,
+	}
+	for _, name := range sortedKeys(durations) {
+		check(durations[name] > 0, "%s must be a positive duration", name)
+	}
+
+	return errors.Join(errs...)
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func validConfig() *Config {
+	cfg := Default()
+	cfg.App.Secret = strings.Repeat("a", minSecretLength)
+	cfg.Auth.JWTSecret = strings.Repeat("j", minSecretLength)
+	return cfg
+}
+
+func TestValidateAcceptsDefaultsWithSecrets(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateRejectsBadValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   string
+	}{
+		{"missing app secret", func(c *Config) { c.App.Secret = "" }, "app.secret (APP_SECRET)"},
+		{"short jwt secret", func(c *Config) { c.Auth.JWTSecret = "short" }, "auth.jwt_secret (JWT_SECRET)"},
+		{"short refresh secret", func(c *Config) { c.Auth.JWTRefreshSecret = "short" }, "auth.jwt_refresh_secret"},
+		{"bcrypt cost", func(c *Config) { c.Auth.BcryptCost = 99 }, "auth.bcrypt_cost"},
+		{"refresh shorter than access", func(c *Config) { c.Auth.RefreshTokenTTL = time.Hour }, "auth.refresh_token_ttl (REFRESH_TOKEN_TTL) must be longer"},
+		{"zero duration", func(c *Config) { c.HTTP.ReadTimeout = 0 }, "http.read_timeout (HTTP_READ_TIMEOUT) must be a positive duration"},
+		{"negative duration", func(c *Config) { c.Jobs.DataExportInterval = -time.Second }, "jobs.data_export_interval"},
+		{"negative shutdown delay", func(c *Config) { c.HTTP.ShutdownDelay = -time.Second }, "http.shutdown_delay"},
+		{"port zero", func(c *Config) { c.HTTP.Port = 0 }, "http.port (PORT)"},
+		{"port too large", func(c *Config) { c.HTTP.Port = 70000 }, "http.port (PORT)"},
+		{"admin port equals port", func(c *Config) { c.HTTP.AdminPort = c.HTTP.Port }, "http.admin_port"},
+		{"database port", func(c *Config) { c.Database.Port = -1 }, "database.port (DB_PORT)"},
+		{"smtp port", func(c *Config) { c.SMTP.Host, c.SMTP.Port = "smtp.example.com", 0 }, "smtp.port"},
+		{"database name", func(c *Config) { c.Database.Name = "" }, "database.host and database.name"},
+		{"locale", func(c *Config) { c.App.DefaultLocale = "fr" }, "app.default_locale"},
+		{"body limit", func(c *Config) { c.HTTP.BodyLimit = "lots" }, "http.body_limit"},
+		{"tls key without cert", func(c *Config) { c.HTTP.TLSKeyFile = "key.pem" }, "http.tls_cert_file"},
+		{"error format", func(c *Config) { c.HTTP.ErrorFormat = "xml" }, "http.error_format"},
+		{"tracing exporter", func(c *Config) { c.Tracing.Exporter = "jaeger" }, "tracing.exporter"},
+		{"otlp endpoint", func(c *Config) { c.Tracing.Exporter, c.Tracing.OTLPEndpoint = TracingExporterOTLP, "" }, "tracing.otlp_endpoint"},
+		{"sample ratio", func(c *Config) { c.Tracing.SampleRatio = 1.5 }, "tracing.sample_ratio"},
+		{"log level", func(c *Config) { c.Log.Level = "loud" }, "log.level"},
+		{"log format", func(c *Config) { c.Log.Format = "xml" }, "log.format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want an error about %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryError(t *testing.T) {
+	cfg := validConfig()
+	cfg.App.Secret = ""
+	cfg.HTTP.Port = 0
+	cfg.Auth.ResetTokenTTL = 0
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() succeeded, want errors")
+	}
+	for _, want := range []string{"app.secret", "http.port", "auth.reset_token_ttl"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, missing %q", err, want)
+		}
+	}
+}
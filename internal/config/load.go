@@ -0,0 +1,200 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"go.yaml.in/yaml/v3"
+)
+
+const redacted = "******"
+
+// Options lists the sources layered over the defaults
+type Options struct {
+	// File is an optional YAML config file
+	File string
+	// Env holds environment variables, see Environment
+	Env map[string]string
+	// Overrides are values from command line flags keyed by path, e.g. "http.port"
+	Overrides map[string]string
+}
+
+// Load builds the configuration from defaults, the config file, environment
+// variables and flag overrides, in increasing priority. It does not validate.
+func Load(opts Options) (*Config, error) {
+	cfg := Default()
+
+	if opts.File != "" {
+		content, err := os.ReadFile(opts.File)
+		if err != nil {
+			return nil, err
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s: %w", opts.File, err)
+		}
+	}
+
+	var errs []error
+	byPath := map[string]field{}
+	for _, f := range fields(cfg) {
+		byPath[f.path] = f
+		if value := opts.Env[f.env]; f.env != "" && value != "" {
+			if err := f.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", f.env, err))
+			}
+		}
+	}
+
+	for _, path := range sortedKeys(opts.Overrides) {
+		f, ok := byPath[path]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown setting %q", path))
+			continue
+		}
+		if err := f.set(opts.Overrides[path]); err != nil {
+			errs = append(errs, fmt.Errorf("--%s: %w", path, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return cfg, nil
+}
+
+// Environment returns the process environment merged over the variables of
+// envFile. The file is optional and real environment variables win, so
+// containers can configure the service without one.
+func Environment(envFile string) (map[string]string, error) {
+	env := map[string]string{}
+	if envFile != "" {
+		fileEnv, err := godotenv.Read(envFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		for key, value := range fileEnv {
+			env[key] = value
+		}
+	}
+
+	for _, entry := range os.Environ() {
+		if key, value, ok := strings.Cut(entry, "="); ok {
+			env[key] = value
+		}
+	}
+	return env, nil
+}
+
+// RegisterFlags adds a flag for every setting, named by its path such as
+// --http.port. Values given on the command line are collected in overrides.
+func RegisterFlags(flags *flag.FlagSet, overrides map[string]string) {
+	for _, f := range fields(Default()) {
+		path := f.path
+		flags.Func(path, fmt.Sprintf("override %s (%s)", path, f.env), func(value string) error {
+			overrides[path] = value
+			return nil
+		})
+	}
+}
+
+// Print writes every setting with secrets redacted
+func (c *Config) Print(w io.Writer) {
+	for _, f := range fields(c) {
+		value := f.String()
+		if f.secret && value != "" {
+			value = redacted
+		}
+		fmt.Fprintf(w, "%-38s %-32s %s\n", f.path, f.env, value)
+	}
+}
+
+// field is one leaf setting of the config struct
+type field struct {
+	path   string
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func fields(cfg *Config) []field {
+	var result []field
+	sections := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Field(i)
+		sectionName := sections.Type().Field(i).Tag.Get("yaml")
+		for j := 0; j < section.NumField(); j++ {
+			tags := section.Type().Field(j).Tag
+			result = append(result, field{
+				path:   sectionName + "." + tags.Get("yaml"),
+				env:    tags.Get("env"),
+				secret: tags.Get("secret") == "true",
+				value:  section.Field(j),
+			})
+		}
+	}
+	return result
+}
+
+func (f field) set(s string) error {
+	switch {
+	case f.value.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(d))
+	case f.value.Kind() == reflect.String:
+		f.value.SetString(s)
+	case f.value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(n))
+	case f.value.Kind() == reflect.Float64:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		f.value.SetFloat(n)
+	case f.value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		f.value.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+	return nil
+}
+
+func (f field) String() string {
+	if f.value.Type() == durationType {
+		return time.Duration(f.value.Int()).String()
+	}
+	return fmt.Sprint(f.value.Interface())
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeConfigFile(t, `
+http:
+  port: 8000
+  read_timeout: 10s
+log:
+  level: debug
+  format: text
+database:
+  name: from_file
+`)
+	cfg, err := Load(Options{
+		File: file,
+		Env: map[string]string{
+			"PORT":       "8100",
+			"LOG_LEVEL":  "warn",
+			"DB_HOST":    "db.internal",
+			"LOG_FORMAT": "",
+		},
+		Overrides: map[string]string{"http.port": "8200", "database.name": "from_flag"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"default", cfg.SMTP.Port, 587},
+		{"file over default", cfg.HTTP.ReadTimeout.String(), "10s"},
+		{"env over file", cfg.Log.Level, "warn"},
+		{"env over default", cfg.Database.Host, "db.internal"},
+		{"empty env keeps file", cfg.Log.Format, "text"},
+		{"flag over env and file", cfg.HTTP.Port, 8200},
+		{"flag over file", cfg.Database.Name, "from_flag"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadRejectsBadValues(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"unknown file key", Options{File: writeConfigFile(t, "http:\n  prot: 8000\n")}, "prot"},
+		{"file type", Options{File: writeConfigFile(t, "http:\n  port: many\n")}, "config.yaml"},
+		{"missing file", Options{File: filepath.Join(t.TempDir(), "missing.yaml")}, "missing.yaml"},
+		{"env duration", Options{Env: map[string]string{"HTTP_READ_TIMEOUT": "30"}}, "HTTP_READ_TIMEOUT"},
+		{"env int", Options{Env: map[string]string{"PORT": "http"}}, "PORT"},
+		{"env bool", Options{Env: map[string]string{"DB_AUTO_MIGRATE": "maybe"}}, "DB_AUTO_MIGRATE"},
+		{"flag value", Options{Overrides: map[string]string{"tracing.sample_ratio": "half"}}, "--tracing.sample_ratio"},
+		{"unknown flag", Options{Overrides: map[string]string{"http.prot": "8000"}}, `unknown setting "http.prot"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() = %v, want an error about %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	cfg := validConfig()
+	cfg.Database.Password = "db-password"
+	cfg.Tracing.OTLPHeaders = "authorization=Bearer abc"
+	cfg.SMTP.Password = ""
+
+	var out bytes.Buffer
+	cfg.Print(&out)
+
+	for _, secret := range []string{cfg.App.Secret, cfg.Auth.JWTSecret, "db-password", "Bearer abc"} {
+		if strings.Contains(out.String(), secret) {
+			t.Errorf("Print() shows the secret %q", secret)
+		}
+	}
+
+	lines := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if parts := strings.Fields(line); len(parts) > 0 {
+			lines[parts[0]] = line
+		}
+	}
+	tests := []struct {
+		path     string
+		redacted bool
+	}{
+		{"app.secret", true},
+		{"database.password", true},
+		{"tracing.otlp_headers", true},
+		// An unset secret prints empty, so a missing value is visible
+		{"smtp.password", false},
+		{"database.user", false},
+	}
+	for _, tt := range tests {
+		line, ok := lines[tt.path]
+		if !ok {
+			t.Errorf("Print() has no line for %s", tt.path)
+			continue
+		}
+		if strings.HasSuffix(line, redacted) != tt.redacted {
+			t.Errorf("%s printed as %q, redacted = %v", tt.path, line, tt.redacted)
+		}
+	}
+	if !strings.Contains(lines["database.user"], "auth_db") {
+		t.Errorf("database.user printed as %q, want its value", lines["database.user"])
+	}
+}
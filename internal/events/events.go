@@ -0,0 +1,54 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Event is a domain event published for other services
+type Event struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	TenantID   int                    `json:"tenant_id"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Payload    map[string]interface{} `json:"payload"`
+}
+
+// New creates an event with a random ID
+func New(eventType string, tenantID int, payload map[string]interface{}) Event {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return Event{
+		ID:         hex.EncodeToString(id),
+		Type:       eventType,
+		TenantID:   tenantID,
+		OccurredAt: time.Now().UTC(),
+		Payload:    payload,
+	}
+}
+
+// LogPublisher writes events to the log. It is the default publisher until a
+// message broker is configured.
+type LogPublisher struct {
+	logger *logrus.Logger
+}
+
+func NewLogPublisher(logger *logrus.Logger) *LogPublisher {
+	return &LogPublisher{logger: logger}
+}
+
+// Publish logs the event
+func (p *LogPublisher) Publish(ctx context.Context, event Event) error {
+	p.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"event_id":   event.ID,
+		"event_type": event.Type,
+		"tenant_id":  event.TenantID,
+		"payload":    event.Payload,
+	}).Info("event published")
+	return nil
+}
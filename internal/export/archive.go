@@ -0,0 +1,277 @@
+// Package export builds the personal data archive users can download for data
+// portability.
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+)
+
+// table is a section that is also written as a CSV file
+type table struct {
+	file   string
+	header []string
+	rows   [][]string
+}
+
+type profile struct {
+	ID                  int        `json:"id"`
+	Username            string     `json:"username"`
+	Email               string     `json:"email"`
+	EmailVerified       bool       `json:"email_verified"`
+	PhoneNumber         string     `json:"phone_number"`
+	FullName            string     `json:"full_name"`
+	Address             string     `json:"address,omitempty"`
+	Dob                 *time.Time `json:"dob,omitempty"`
+	Role                string     `json:"role"`
+	IsActive            bool       `json:"is_active"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+type session struct {
+	ID                    int       `json:"id"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+type sellerApplication struct {
+	models.SellerApplication
+	NIK  string `json:"nik"`
+	NPWP string `json:"npwp"`
+}
+
+const readme = `Personal data export
+
+This archive contains the personal data stored by the account service.
+
+data.json                 everything below in one JSON document
+profile.json              account profile
+addresses.csv             address book
+sessions.csv              active and past login sessions (tokens are not included)
+roles.csv                 assigned roles
+email_changes.csv         email change history
+phone_changes.csv         phone number change history
+consents.csv              accepted terms and privacy policy versions and marketing choices
+
+Seller applications and previous exports are included in data.json only.
+Uploaded KYC documents are listed by name; contact support for copies.
+`
+
+// BuildArchive returns a zip archive with the user's data as JSON and CSV
+func BuildArchive(data *models.UserData, generatedAt time.Time) ([]byte, error) {
+	user := data.User
+	p := profile{
+		ID:                  user.ID,
+		Username:            user.Username,
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerified,
+		PhoneNumber:         string(user.PhoneNumber),
+		FullName:            user.FullName,
+		Address:             string(user.Address),
+		Dob:                 user.Dob.TimePtr(),
+		Role:                user.Role,
+		IsActive:            user.IsActive,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+	}
+
+	sessions := make([]session, len(data.Sessions))
+	for i, s := range data.Sessions {
+		sessions[i] = session{
+			ID:                    s.ID,
+			CreatedAt:             s.CreatedAt,
+			UpdatedAt:             s.UpdatedAt,
+			AccessTokenExpiresAt:  s.TokenExpired,
+			RefreshTokenExpiresAt: s.RefreshTokenExpired,
+		}
+	}
+
+	applications := make([]sellerApplication, len(data.SellerApplications))
+	for i, a := range data.SellerApplications {
+		applications[i] = sellerApplication{SellerApplication: a, NIK: a.NIK, NPWP: a.NPWP}
+	}
+
+	roles := make([]string, len(data.Roles))
+	for i, r := range data.Roles {
+		roles[i] = r.Name
+	}
+
+	document := map[string]interface{}{
+		"generated_at":        generatedAt.UTC(),
+		"profile":             p,
+		"roles":               roles,
+		"addresses":           data.Addresses,
+		"sessions":            sessions,
+		"email_changes":       data.EmailChanges,
+		"phone_changes":       data.PhoneChanges,
+		"seller_applications": applications,
+		"data_exports":        data.DataExports,
+		"consents":            data.Consents,
+	}
+
+	tables := []table{
+		addressTable(data.Addresses),
+		sessionTable(sessions),
+		roleTable(roles),
+		emailChangeTable(data.EmailChanges),
+		phoneChangeTable(data.PhoneChanges),
+		consentTable(data.Consents),
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	if err := writeFile(zw, "README.txt", []byte(readme)); err != nil {
+		return nil, err
+	}
+	if err := writeJSON(zw, "data.json", document); err != nil {
+		return nil, err
+	}
+	if err := writeJSON(zw, "profile.json", p); err != nil {
+		return nil, err
+	}
+	for _, t := range tables {
+		if err := writeCSV(zw, t); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func addressTable(addresses []models.Address) table {
+	t := table{
+		file: "addresses.csv",
+		header: []string{"id", "label", "recipient_name", "phone_number", "address_line", "province", "city",
+			"district", "postal_code", "latitude", "longitude", "is_default_shipping", "is_default_billing", "created_at"},
+	}
+	for _, a := range addresses {
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(a.ID), a.Label, a.RecipientName, a.PhoneNumber, a.AddressLine, a.Province, a.City,
+			a.District, a.PostalCode, formatFloat(a.Latitude), formatFloat(a.Longitude),
+			strconv.FormatBool(a.IsDefaultShipping), strconv.FormatBool(a.IsDefaultBilling), formatTime(&a.CreatedAt),
+		})
+	}
+	return t
+}
+
+func sessionTable(sessions []session) table {
+	t := table{
+		file:   "sessions.csv",
+		header: []string{"id", "created_at", "access_token_expires_at", "refresh_token_expires_at"},
+	}
+	for _, s := range sessions {
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(s.ID), formatTime(&s.CreatedAt), formatTime(&s.AccessTokenExpiresAt), formatTime(&s.RefreshTokenExpiresAt),
+		})
+	}
+	return t
+}
+
+func roleTable(roles []string) table {
+	t := table{file: "roles.csv", header: []string{"role"}}
+	for _, r := range roles {
+		t.rows = append(t.rows, []string{r})
+	}
+	return t
+}
+
+func emailChangeTable(changes []models.EmailChangeRequest) table {
+	t := table{
+		file:   "email_changes.csv",
+		header: []string{"id", "old_email", "new_email", "status", "created_at", "confirmed_at"},
+	}
+	for _, c := range changes {
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(c.ID), c.OldEmail, c.NewEmail, c.Status, formatTime(&c.CreatedAt), formatTime(c.ConfirmedAt),
+		})
+	}
+	return t
+}
+
+func phoneChangeTable(changes []models.PhoneChangeRequest) table {
+	t := table{
+		file:   "phone_changes.csv",
+		header: []string{"id", "old_phone", "new_phone", "status", "created_at", "confirmed_at"},
+	}
+	for _, c := range changes {
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(c.ID), c.OldPhone, c.NewPhone, c.Status, formatTime(&c.CreatedAt), formatTime(c.ConfirmedAt),
+		})
+	}
+	return t
+}
+
+func consentTable(consents []models.Consent) table {
+	t := table{
+		file:   "consents.csv",
+		header: []string{"id", "type", "document_version", "granted", "ip_address", "user_agent", "created_at"},
+	}
+	for _, c := range consents {
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(c.ID), c.Type, c.DocumentVersion, strconv.FormatBool(c.Granted), c.IPAddress, c.UserAgent, formatTime(&c.CreatedAt),
+		})
+	}
+	return t
+}
+
+func writeFile(zw *zip.Writer, name string, content []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(zw, name, content)
+}
+
+func writeCSV(zw *zip.Writer, t table) error {
+	w, err := zw.Create(t.file)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(t.rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
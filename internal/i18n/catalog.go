@@ -0,0 +1,117 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Message marks a translatable string that is not passed to Translate
+// directly, e.g. a document name used as an argument. Arguments of type
+// Message are translated too.
+type Message string
+
+// catalogues maps English messages to their translation, see messages_id.go
+var catalogues = map[Locale]map[string]string{
+	ID: messagesID,
+}
+
+// fieldLabels names request fields in validation errors, English uses the
+// JSON field names
+var fieldLabels = map[Locale]map[string]string{
+	ID: fieldLabelsID,
+}
+
+var reported sync.Map
+
+// Translate returns the message key in locale, formatted with args when
+// given. A missing translation falls back to English and is logged once.
+func Translate(locale Locale, key string, args ...interface{}) string {
+	message := key
+	if locale != EN {
+		if translated, ok := catalogues[locale][key]; ok {
+			message = translated
+		} else if _, seen := reported.LoadOrStore(string(locale)+"\x00"+key, true); !seen {
+			logrus.WithFields(logrus.Fields{"locale": locale, "key": key}).Warn("Missing translation, run the i18n check command")
+		}
+	}
+
+	if len(args) == 0 {
+		return message
+	}
+	translatedArgs := make([]interface{}, len(args))
+	for i, arg := range args {
+		if m, ok := arg.(Message); ok {
+			arg = Translate(locale, string(m))
+		}
+		translatedArgs[i] = arg
+	}
+	return fmt.Sprintf(message, translatedArgs...)
+}
+
+// T translates key into the locale of the request in ctx
+func T(ctx context.Context, key string, args ...interface{}) string {
+	return Translate(FromContext(ctx), key, args...)
+}
+
+// FieldLabel names a request field, given by its JSON name, in locale
+func FieldLabel(locale Locale, field string) string {
+	if label, ok := fieldLabels[locale][field]; ok {
+		return label
+	}
+	return field
+}
+
+// HasTranslation reports whether key can be shown in locale
+func HasTranslation(locale Locale, key string) bool {
+	if locale == EN {
+		return true
+	}
+	_, ok := catalogues[locale][key]
+	return ok
+}
+
+// HasFieldLabel reports whether field has a label in locale
+func HasFieldLabel(locale Locale, field string) bool {
+	if locale == EN {
+		return true
+	}
+	_, ok := fieldLabels[locale][field]
+	return ok
+}
+
+var verbPattern = regexp.MustCompile(`%(?:\[\d+\])?[-+# 0]*\d*(?:\.\d+)?[a-zA-Z%]`)
+
+// Verify checks the catalogues themselves: every translation must use as
+// many format verbs as its English key, so arguments are never dropped or
+// rendered as %!s(MISSING). It runs at startup.
+func Verify() []string {
+	var problems []string
+	for _, locale := range Supported {
+		for key, translated := range catalogues[locale] {
+			if translated == "" {
+				problems = append(problems, fmt.Sprintf("%s: empty translation of %q", locale, key))
+				continue
+			}
+			if countVerbs(key) != countVerbs(translated) {
+				problems = append(problems, fmt.Sprintf("%s: %q has different format verbs than %q", locale, translated, key))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+func countVerbs(s string) int {
+	count := 0
+	for _, verb := range verbPattern.FindAllString(s, -1) {
+		if verb != "%%" {
+			count++
+		}
+	}
+	return count
+}
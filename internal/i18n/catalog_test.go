@@ -0,0 +1,93 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// moduleRoot is the repository root relative to this package
+const moduleRoot = "../.."
+
+func TestCataloguesAreValid(t *testing.T) {
+	for _, problem := range Verify() {
+		t.Error(problem)
+	}
+}
+
+func TestSourceMessagesAreTranslated(t *testing.T) {
+	result, err := Scan(moduleRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Messages) == 0 {
+		t.Fatal("Scan found no messages, is the module root right?")
+	}
+
+	for _, locale := range Supported[1:] {
+		for _, message := range result.Missing(locale) {
+			kind := "message"
+			if message.Field {
+				kind = "field label"
+			}
+			t.Errorf("%s: %s: missing %s %q", message.Position, locale, kind, message.Key)
+		}
+	}
+	for _, position := range result.Dynamic {
+		t.Logf("%s: warning: message built at runtime cannot be translated", position)
+	}
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	source := `package api
+
+import "github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+
+const notFound = "Thing not found"
+
+func handler(name string) error {
+	if name == "" {
+		return helpers.ErrBadRequest("Name is required")
+	}
+	if name == "gone" {
+		return helpers.ErrNotFound(notFound)
+	}
+	return helpers.ErrConflict("Thing " + name + " exists")
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "handler.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	for _, message := range result.Messages {
+		keys = append(keys, message.Key)
+	}
+	if len(keys) != 2 || keys[0] != "Name is required" || keys[1] != "Thing not found" {
+		t.Errorf("messages = %q, want the literal and the constant", keys)
+	}
+	if len(result.Dynamic) != 1 {
+		t.Errorf("dynamic messages = %v, want the concatenation", result.Dynamic)
+	}
+}
+
+func TestCountVerbs(t *testing.T) {
+	tests := map[string]int{
+		"No verbs":              0,
+		"Hello %s":              1,
+		"%d of %d, 100%%":       2,
+		"%[2]s before %[1]s":    2,
+		"Padded %-10s and %.2f": 2,
+	}
+	for s, want := range tests {
+		if got := countVerbs(s); got != want {
+			t.Errorf("countVerbs(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package i18n
+
+import (
+	"strings"
+	"time"
+)
+
+var monthsID = strings.NewReplacer(
+	"January", "Januari", "February", "Februari", "March", "Maret", "April", "April",
+	"May", "Mei", "June", "Juni", "July", "Juli", "August", "Agustus",
+	"September", "September", "October", "Oktober", "November", "November", "December", "Desember",
+)
+
+// FormatDate formats t as a day, e.g. "2 January 2006" or "2 Januari 2006"
+func FormatDate(locale Locale, t time.Time) string {
+	return localizeMonth(locale, t.Format("2 January 2006"))
+}
+
+// FormatDateTime formats t with the time of day and zone
+func FormatDateTime(locale Locale, t time.Time) string {
+	return localizeMonth(locale, t.Format("2 January 2006 15:04 MST"))
+}
+
+func localizeMonth(locale Locale, formatted string) string {
+	if locale == ID {
+		return monthsID.Replace(formatted)
+	}
+	return formatted
+}
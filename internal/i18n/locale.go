@@ -0,0 +1,112 @@
+// Package i18n translates API messages, validation errors and email and SMS
+// templates. English is the source language: messages are written in
+// English in the code and the English text is the key of the other
+// catalogues.
+package i18n
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/text/language"
+)
+
+// Locale is a supported language
+type Locale string
+
+const (
+	EN Locale = "en"
+	ID Locale = "id"
+)
+
+// Supported lists the locales with a catalogue, English first
+var Supported = []Locale{EN, ID}
+
+var matcher = language.NewMatcher([]language.Tag{language.English, language.Indonesian})
+
+// Parse returns the supported locale of a language tag such as "id",
+// "id-ID" or "en-US"
+func Parse(tag string) (Locale, bool) {
+	parsed, err := language.Parse(strings.TrimSpace(tag))
+	if err != nil {
+		return "", false
+	}
+	base, _ := parsed.Base()
+	for _, locale := range Supported {
+		if base.String() == string(locale) {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// FromAcceptLanguage returns the best supported locale of an Accept-Language
+// header, false when the client accepts none of them
+func FromAcceptLanguage(header string) (Locale, bool) {
+	if strings.TrimSpace(header) == "" {
+		return "", false
+	}
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil || len(tags) == 0 {
+		return "", false
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return "", false
+	}
+	return Supported[index], true
+}
+
+var defaultLocale atomic.Value
+
+// SetDefault sets the locale used when neither the request nor the user
+// chose one
+func SetDefault(locale Locale) {
+	defaultLocale.Store(locale)
+}
+
+// Default returns the locale set by SetDefault, English until then
+func Default() Locale {
+	if locale, ok := defaultLocale.Load().(Locale); ok {
+		return locale
+	}
+	return EN
+}
+
+type localeKey struct{}
+
+type localeValue struct {
+	locale   Locale
+	explicit bool
+}
+
+// WithLocale returns a context carrying locale. explicit marks a locale the
+// client asked for, which a saved user preference does not override.
+func WithLocale(ctx context.Context, locale Locale, explicit bool) context.Context {
+	return context.WithValue(ctx, localeKey{}, localeValue{locale: locale, explicit: explicit})
+}
+
+// FromContext returns the locale of the request, the default outside one
+func FromContext(ctx context.Context) Locale {
+	if value, ok := ctx.Value(localeKey{}).(localeValue); ok {
+		return value.locale
+	}
+	return Default()
+}
+
+// IsExplicit reports whether the client chose the locale in ctx
+func IsExplicit(ctx context.Context) bool {
+	value, ok := ctx.Value(localeKey{}).(localeValue)
+	return ok && value.explicit
+}
+
+// ForUser returns the locale of messages sent to a user outside the
+// response, such as emails and SMS: the saved preference, else the locale
+// of the request
+func ForUser(ctx context.Context, preference string) Locale {
+	if locale, ok := Parse(preference); ok {
+		return locale
+	}
+	return FromContext(ctx)
+}
@@ -0,0 +1,311 @@
+package i18n
+
+// messagesID is the Indonesian catalogue. Keep the format verbs of the
+// English key, the i18n check command reports missing entries.
+var messagesID = map[string]string{
+	// Validation
+	"%s is invalid":                               "%s tidak valid",
+	"%s is required":                              "%s wajib diisi",
+	"%s must be at least %s characters":           "%s minimal %s karakter",
+	"%s must be at most %s characters":            "%s maksimal %s karakter",
+	"DOB cannot be in the future":                 "Tanggal lahir tidak boleh di masa depan",
+	"Invalid date format for DOB. Use YYYY-MM-DD": "Format tanggal lahir tidak valid. Gunakan YYYY-MM-DD",
+	"Invalid date format, expected %s":            "Format tanggal tidak valid, seharusnya %s",
+	"Invalid email format":                        "Format email tidak valid",
+	"Invalid phone number":                        "Nomor telepon tidak valid",
+	"Invalid query parameters":                    "Parameter query tidak valid",
+	"Invalid request":                             "Permintaan tidak valid",
+	"Invalid request body":                        "Isi permintaan tidak valid",
+	"Invalid status filter":                       "Filter status tidak valid",
+	"Validation error":                            "Kesalahan validasi",
+	"Validation failed":                           "Validasi gagal",
+	"already in use":                              "sudah digunakan",
+	"cannot be in the future":                     "tidak boleh di masa depan",
+	"must be YYYY-MM-DD":                          "harus berformat YYYY-MM-DD",
+	"updated_since must be an RFC 3339 timestamp": "updated_since harus berupa waktu RFC 3339",
+	"Invalid phone number, use a mobile number such as 081234567890 or +6281234567890": "Nomor telepon tidak valid, gunakan nomor ponsel seperti 081234567890 atau +6281234567890",
+	"must be a mobile number such as 081234567890 or +6281234567890":                   "harus berupa nomor ponsel seperti 081234567890 atau +6281234567890",
+
+	// HTTP and generic errors
+	"Duplicate data":                            "Data duplikat",
+	"Internal server error":                     "Terjadi kesalahan pada server",
+	"Method not allowed":                        "Metode tidak diizinkan",
+	"Request body is too large":                 "Isi permintaan terlalu besar",
+	"Resource not found":                        "Data tidak ditemukan",
+	"Service is temporarily unavailable":        "Layanan sedang tidak tersedia",
+	"Too many requests, please try again later": "Terlalu banyak permintaan, silakan coba lagi nanti",
+	"Unauthorized":                              "Tidak terautentikasi",
+	"Alive":                                     "Aktif",
+	"Healthty":                                  "Sehat",
+	"Not ready":                                 "Belum siap",
+	"Ready":                                     "Siap",
+	"A request with this Idempotency-Key is still in progress":       "Permintaan dengan Idempotency-Key ini masih diproses",
+	"Idempotency-Key must be at most %d characters":                  "Idempotency-Key maksimal %d karakter",
+	"This Idempotency-Key was already used with a different request": "Idempotency-Key ini sudah dipakai untuk permintaan yang berbeda",
+
+	// Authentication
+	"Access denied by policy":                                           "Akses ditolak oleh kebijakan",
+	"Account is deactivated":                                            "Akun dinonaktifkan",
+	"Authorization evaluated":                                           "Otorisasi dievaluasi",
+	"Insufficient permissions":                                          "Izin tidak mencukupi",
+	"Invalid authorization header format":                               "Format header otorisasi tidak valid",
+	"Invalid credentials":                                               "Kredensial tidak valid",
+	"Invalid old password":                                              "Kata sandi lama salah",
+	"Invalid or expired OTP":                                            "OTP tidak valid atau sudah kedaluwarsa",
+	"Invalid or expired reset token":                                    "Token reset tidak valid atau sudah kedaluwarsa",
+	"Invalid or expired token":                                          "Token tidak valid atau sudah kedaluwarsa",
+	"Invalid password":                                                  "Kata sandi salah",
+	"Invalid refresh token":                                             "Refresh token tidak valid",
+	"Login successful":                                                  "Berhasil masuk",
+	"Logout successful":                                                 "Berhasil keluar",
+	"Missing authorization header":                                      "Header otorisasi tidak ada",
+	"Password changed successfully":                                     "Kata sandi berhasil diubah",
+	"Password must be at least %d characters":                           "Kata sandi minimal %d karakter",
+	"Password must contain both letters and digits":                     "Kata sandi harus mengandung huruf dan angka",
+	"Password reset instructions sent to your email":                    "Petunjuk reset kata sandi telah dikirim ke email Anda",
+	"Password reset successful":                                         "Kata sandi berhasil direset",
+	"Refresh token expired":                                             "Refresh token sudah kedaluwarsa",
+	"Registration successful":                                           "Pendaftaran berhasil",
+	"Token is not valid for this tenant":                                "Token tidak berlaku untuk tenant ini",
+	"Token refreshed successfully":                                      "Token berhasil diperbarui",
+	"You have been logged out successfully":                             "Anda telah berhasil keluar",
+	"Your password has been changed successfully":                       "Kata sandi Anda berhasil diubah",
+	"If the email exists, you will receive password reset instructions": "Jika email terdaftar, Anda akan menerima petunjuk reset kata sandi",
+	"Your password has been reset successfully. Please login with your new password": "Kata sandi Anda berhasil direset. Silakan masuk dengan kata sandi baru",
+
+	// Users, profile and tenants
+	"Default tenant is not configured":         "Tenant bawaan belum dikonfigurasi",
+	"Email already registered":                 "Email sudah terdaftar",
+	"No fields to update":                      "Tidak ada data yang diubah",
+	"Nothing to update":                        "Tidak ada yang perlu diubah",
+	"Phone already exist":                      "Nomor telepon sudah terdaftar",
+	"Some of these details are already in use": "Sebagian data ini sudah digunakan",
+	"Profile retrieved successfully":           "Profil berhasil diambil",
+	"Profile updated successfully":             "Profil berhasil diperbarui",
+	"Tenant is disabled":                       "Tenant dinonaktifkan",
+	"Tenant not found":                         "Tenant tidak ditemukan",
+	"Unknown tenant":                           "Tenant tidak dikenal",
+	"User not found":                           "Pengguna tidak ditemukan",
+	"User activated successfully":              "Pengguna berhasil diaktifkan",
+	"User deactivated successfully":            "Pengguna berhasil dinonaktifkan",
+	"Username already taken":                   "Username sudah digunakan",
+	"Invalid user id":                          "ID pengguna tidak valid",
+
+	// Roles and permissions
+	"One or more permissions do not exist":                                         "Satu atau lebih izin tidak ada",
+	"Only a superadmin can manage the * permission":                                "Hanya superadmin yang dapat mengelola izin *",
+	"Only a superadmin can manage the superadmin role":                             "Hanya superadmin yang dapat mengelola peran superadmin",
+	"Permission already exists":                                                    "Izin sudah ada",
+	"Permission created successfully":                                              "Izin berhasil dibuat",
+	"Permission granted successfully":                                              "Izin berhasil diberikan",
+	"Permission not found":                                                         "Izin tidak ditemukan",
+	"Permission revoked successfully":                                              "Izin berhasil dicabut",
+	"Permissions retrieved successfully":                                           "Daftar izin berhasil diambil",
+	"Role assigned successfully":                                                   "Peran berhasil diberikan",
+	"Role not found":                                                               "Peran tidak ditemukan",
+	"Role permissions updated successfully":                                        "Izin peran berhasil diperbarui",
+	"Roles are shared by every tenant, only a platform superadmin can change them": "Peran berlaku untuk semua tenant, hanya superadmin platform yang dapat mengubahnya",
+	"Role revoked successfully":                                                    "Peran berhasil dicabut",
+	"Roles retrieved successfully":                                                 "Daftar peran berhasil diambil",
+	"You cannot grant the %s permission, you do not hold it":                       "Anda tidak dapat memberikan izin %s karena Anda tidak memilikinya",
+	"User roles retrieved successfully":                                            "Peran pengguna berhasil diambil",
+
+	// Email change
+	"Confirm your new email address":                                            "Konfirmasi alamat email baru Anda",
+	"Email change cancelled":                                                    "Perubahan email dibatalkan",
+	"Email change requested":                                                    "Perubahan email diminta",
+	"Email changed successfully":                                                "Email berhasil diubah",
+	"Invalid or expired cancel link":                                            "Tautan pembatalan tidak valid atau sudah kedaluwarsa",
+	"Invalid or expired email change link":                                      "Tautan perubahan email tidak valid atau sudah kedaluwarsa",
+	"New email must be different from the current email":                        "Email baru harus berbeda dari email saat ini",
+	"Please check your new email to confirm the change":                         "Silakan periksa email baru Anda untuk mengonfirmasi perubahan",
+	"The email change has been cancelled":                                       "Perubahan email telah dibatalkan",
+	"The previous email is now used by another account, please contact support": "Email sebelumnya kini digunakan akun lain, silakan hubungi dukungan",
+	"Your email address is being changed":                                       "Alamat email Anda sedang diubah",
+	"Your email has been changed. Please login again":                           "Email Anda telah diubah. Silakan masuk kembali",
+	"Hi %s,\n\nA request was made to change the email address of your account to %s.\n\nIf this wasn't you, open the link below to cancel the change and sign out every device:\n\n%s\n": "Halo %s,\n\nAda permintaan untuk mengubah alamat email akun Anda menjadi %s.\n\nJika ini bukan Anda, buka tautan di bawah untuk membatalkan perubahan dan keluar dari semua perangkat:\n\n%s\n",
+	"Hi %s,\n\nPlease confirm your new email address by opening the link below within 24 hours:\n\n%s\n\nIf you didn't request this, you can ignore this email.\n":                       "Halo %s,\n\nSilakan konfirmasi alamat email baru Anda dengan membuka tautan di bawah dalam 24 jam:\n\n%s\n\nJika Anda tidak memintanya, abaikan email ini.\n",
+
+	// Phone change
+	"Failed to send OTP": "Gagal mengirim OTP",
+	"New phone number must be different from the current phone number": "Nomor telepon baru harus berbeda dari nomor saat ini",
+	"OTP sent":                          "OTP terkirim",
+	"Phone number changed successfully": "Nomor telepon berhasil diubah",
+	"Please enter the OTP sent to your new phone number": "Silakan masukkan OTP yang dikirim ke nomor telepon baru Anda",
+	"Please wait a minute before requesting another OTP": "Silakan tunggu satu menit sebelum meminta OTP lagi",
+	"Your phone number has been changed":                 "Nomor telepon Anda telah diubah",
+	"%s is your verification code to change your phone number. It expires in 10 minutes. Do not share this code with anyone.": "%s adalah kode verifikasi untuk mengubah nomor telepon Anda. Kode berlaku 10 menit. Jangan bagikan kode ini kepada siapa pun.",
+
+	// Addresses
+	"Address book is full":                                "Buku alamat sudah penuh",
+	"Address created successfully":                        "Alamat berhasil dibuat",
+	"Address deleted successfully":                        "Alamat berhasil dihapus",
+	"Address not found":                                   "Alamat tidak ditemukan",
+	"Address retrieved successfully":                      "Alamat berhasil diambil",
+	"Address updated successfully":                        "Alamat berhasil diperbarui",
+	"Addresses retrieved successfully":                    "Daftar alamat berhasil diambil",
+	"Invalid address id":                                  "ID alamat tidak valid",
+	"The address has been removed from your address book": "Alamat telah dihapus dari buku alamat Anda",
+
+	// Seller applications
+	"An application is already pending review":         "Masih ada pengajuan yang menunggu peninjauan",
+	"Application has already been reviewed":            "Pengajuan sudah ditinjau",
+	"Application not found":                            "Pengajuan tidak ditemukan",
+	"Application retrieved successfully":               "Pengajuan berhasil diambil",
+	"Application submitted successfully":               "Pengajuan berhasil dikirim",
+	"Applications retrieved successfully":              "Daftar pengajuan berhasil diambil",
+	"Document %s exceeds 5 MB":                         "Dokumen %s melebihi 5 MB",
+	"Document %s is required":                          "Dokumen %s wajib diunggah",
+	"Document %s must be a JPEG, PNG or PDF file":      "Dokumen %s harus berupa file JPEG, PNG atau PDF",
+	"Document not found":                               "Dokumen tidak ditemukan",
+	"Invalid application id":                           "ID pengajuan tidak valid",
+	"Invalid document id":                              "ID dokumen tidak valid",
+	"Notes are required when rejecting an application": "Catatan wajib diisi saat menolak pengajuan",
+	"User is already a seller":                         "Pengguna sudah menjadi penjual",
+
+	// Consents and legal documents
+	"Consents retrieved successfully":           "Persetujuan berhasil diambil",
+	"Consents updated successfully":             "Persetujuan berhasil diperbarui",
+	"Legal document published":                  "Dokumen legal diterbitkan",
+	"Legal documents retrieved successfully":    "Dokumen legal berhasil diambil",
+	"Marketing consents retrieved successfully": "Persetujuan pemasaran berhasil diambil",
+	"Please accept the current %s (version %s)": "Silakan setujui %s terbaru (versi %s)",
+	"This version is already published":         "Versi ini sudah diterbitkan",
+	"privacy policy":                            "kebijakan privasi",
+	"terms of service":                          "syarat dan ketentuan",
+
+	// Data export and account deletion
+	"A data export is already being prepared":                                  "Ekspor data sedang disiapkan",
+	"Account deletion scheduled":                                               "Penghapusan akun dijadwalkan",
+	"Data export not found":                                                    "Ekspor data tidak ditemukan",
+	"Data export requested":                                                    "Ekspor data diminta",
+	"Data exports retrieved successfully":                                      "Daftar ekspor data berhasil diambil",
+	"Invalid download link":                                                    "Tautan unduhan tidak valid",
+	"Invalid or expired download link":                                         "Tautan unduhan tidak valid atau sudah kedaluwarsa",
+	"Your account is scheduled for deletion":                                   "Akun Anda dijadwalkan untuk dihapus",
+	"Your account will be deleted. Log in before the scheduled time to cancel": "Akun Anda akan dihapus. Masuk sebelum waktu yang dijadwalkan untuk membatalkan",
+	"Your data export is ready":                                                "Ekspor data Anda sudah siap",
+	"Hi %s,\n\nThe export of your personal data is ready. Download it within 24 hours from:\n\n%s\n\nAfter that you can get a new link from your account settings until %s.\n": "Halo %s,\n\nEkspor data pribadi Anda sudah siap. Unduh dalam 24 jam dari:\n\n%s\n\nSetelah itu Anda dapat meminta tautan baru dari pengaturan akun hingga %s.\n",
+	"Hi %s,\n\nYour account and personal data will be permanently deleted on %s.\n\nChanged your mind? Just log in before then and the deletion will be cancelled.\n":          "Halo %s,\n\nAkun dan data pribadi Anda akan dihapus permanen pada %s.\n\nBerubah pikiran? Cukup masuk sebelum waktu tersebut dan penghapusan akan dibatalkan.\n",
+
+	// Internal errors
+	"Failed to assign role":                "Gagal memberikan peran",
+	"Failed to activate user":              "Gagal mengaktifkan pengguna",
+	"Failed to cancel account deletion":    "Gagal membatalkan penghapusan akun",
+	"Failed to cancel email change":        "Gagal membatalkan perubahan email",
+	"Failed to change email":               "Gagal mengubah email",
+	"Failed to change phone number":        "Gagal mengubah nomor telepon",
+	"Failed to check data exports":         "Gagal memeriksa ekspor data",
+	"Failed to check email":                "Gagal memeriksa email",
+	"Failed to check existing application": "Gagal memeriksa pengajuan yang ada",
+	"Failed to check permission":           "Gagal memeriksa izin",
+	"Failed to check phone":                "Gagal memeriksa nomor telepon",
+	"Failed to clear reset token":          "Gagal menghapus token reset",
+	"Failed to count addresses":            "Gagal menghitung alamat",
+	"Failed to create address":             "Gagal membuat alamat",
+	"Failed to create application":         "Gagal membuat pengajuan",
+	"Failed to create permission":          "Gagal membuat izin",
+	"Failed to create session":             "Gagal membuat sesi",
+	"Failed to create user":                "Gagal membuat pengguna",
+	"Failed to deactivate user":            "Gagal menonaktifkan pengguna",
+	"Failed to delete user":                "Gagal menghapus pengguna",
+	"Failed to delete address":             "Gagal menghapus alamat",
+	"Failed to find address":               "Gagal mencari alamat",
+	"Failed to find application":           "Gagal mencari pengajuan",
+	"Failed to find consents":              "Gagal mencari persetujuan",
+	"Failed to find data export":           "Gagal mencari ekspor data",
+	"Failed to find email change request":  "Gagal mencari permintaan perubahan email",
+	"Failed to find legal documents":       "Gagal mencari dokumen legal",
+	"Failed to find permission":            "Gagal mencari izin",
+	"Failed to find permissions":           "Gagal mencari daftar izin",
+	"Failed to find phone change request":  "Gagal mencari permintaan perubahan nomor telepon",
+	"Failed to find role":                  "Gagal mencari peran",
+	"Failed to find session":               "Gagal mencari sesi",
+	"Failed to find tenant":                "Gagal mencari tenant",
+	"Failed to find user":                  "Gagal mencari pengguna",
+	"Failed to generate OTP":               "Gagal membuat OTP",
+	"Failed to generate access token":      "Gagal membuat access token",
+	"Failed to generate refresh token":     "Gagal membuat refresh token",
+	"Failed to generate reset token":       "Gagal membuat token reset",
+	"Failed to generate token":             "Gagal membuat token",
+	"Failed to grant permission":           "Gagal memberikan izin",
+	"Failed to hash password":              "Gagal memproses kata sandi",
+	"Failed to list addresses":             "Gagal mengambil daftar alamat",
+	"Failed to list applications":          "Gagal mengambil daftar pengajuan",
+	"Failed to list data exports":          "Gagal mengambil daftar ekspor data",
+	"Failed to list marketing consents":    "Gagal mengambil daftar persetujuan pemasaran",
+	"Failed to list permissions":           "Gagal mengambil daftar izin",
+	"Failed to list roles":                 "Gagal mengambil daftar peran",
+	"Failed to load user roles":            "Gagal memuat peran pengguna",
+	"Failed to logout":                     "Gagal keluar",
+	"Failed to open document":              "Gagal membuka dokumen",
+	"Failed to publish legal document":     "Gagal menerbitkan dokumen legal",
+	"Failed to purge sessions":             "Gagal membersihkan sesi",
+	"Failed to read data export":           "Gagal membaca ekspor data",
+	"Failed to read document":              "Gagal membaca dokumen",
+	"Failed to read document %s":           "Gagal membaca dokumen %s",
+	"Failed to request data export":        "Gagal meminta ekspor data",
+	"Failed to reserve idempotency key":    "Gagal memesan idempotency key",
+	"Failed to resolve tenant":             "Gagal menentukan tenant",
+	"Failed to review application":         "Gagal meninjau pengajuan",
+	"Failed to revoke permission":          "Gagal mencabut izin",
+	"Failed to revoke role":                "Gagal mencabut peran",
+	"Failed to revoke sessions":            "Gagal mencabut sesi",
+	"Failed to save consents":              "Gagal menyimpan persetujuan",
+	"Failed to save email change request":  "Gagal menyimpan permintaan perubahan email",
+	"Failed to save phone change request":  "Gagal menyimpan permintaan perubahan nomor telepon",
+	"Failed to save reset token":           "Gagal menyimpan token reset",
+	"Failed to schedule account deletion":  "Gagal menjadwalkan penghapusan akun",
+	"Failed to send confirmation email":    "Gagal mengirim email konfirmasi",
+	"Failed to set role":                   "Gagal mengatur peran",
+	"Failed to store document":             "Gagal menyimpan dokumen",
+	"Failed to update address":             "Gagal memperbarui alamat",
+	"Failed to update password":            "Gagal memperbarui kata sandi",
+	"Failed to update profile":             "Gagal memperbarui profil",
+	"Failed to update role permissions":    "Gagal memperbarui izin peran",
+	"Failed to update session":             "Gagal memperbarui sesi",
+	"Failed to verify OTP":                 "Gagal memverifikasi OTP",
+	"Failed to verify reset token":         "Gagal memverifikasi token reset",
+}
+
+// fieldLabelsID names request fields, by JSON name, in validation errors
+var fieldLabelsID = map[string]string{
+	"action":            "Aksi",
+	"address":           "Alamat",
+	"address_line":      "Alamat lengkap",
+	"city":              "Kota",
+	"description":       "Deskripsi",
+	"district":          "Kecamatan",
+	"dob":               "Tanggal lahir",
+	"email":             "Email",
+	"email_or_username": "Email atau username",
+	"full_name":         "Nama lengkap",
+	"label":             "Label",
+	"latitude":          "Lintang",
+	"locale":            "Bahasa",
+	"longitude":         "Bujur",
+	"name":              "Nama",
+	"new_email":         "Email baru",
+	"new_password":      "Kata sandi baru",
+	"new_phone":         "Nomor telepon baru",
+	"notes":             "Catatan",
+	"old_password":      "Kata sandi lama",
+	"otp":               "OTP",
+	"password":          "Kata sandi",
+	"permission":        "Izin",
+	"permissions":       "Daftar izin",
+	"phone_number":      "Nomor telepon",
+	"postal_code":       "Kode pos",
+	"privacy_version":   "Versi kebijakan privasi",
+	"province":          "Provinsi",
+	"recipient_name":    "Nama penerima",
+	"refresh_token":     "Refresh token",
+	"role":              "Peran",
+	"terms_version":     "Versi syarat dan ketentuan",
+	"title":             "Judul",
+	"token":             "Token",
+	"type":              "Tipe",
+	"url":               "URL",
+	"username":          "Username",
+	"version":           "Versi",
+}
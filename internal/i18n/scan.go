@@ -0,0 +1,328 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SourceMessage is a translatable string found in the source
+type SourceMessage struct {
+	Key string
+	// Field marks a request field name shown in validation errors
+	Field    bool
+	Position string
+}
+
+// ScanResult lists the messages of a source tree
+type ScanResult struct {
+	Messages []SourceMessage
+	// Dynamic are messages built at runtime, e.g. by concatenation, which
+	// cannot be translated. Use a format key with arguments instead.
+	Dynamic []string
+}
+
+// messageArgs are the functions taking a message and the position of the
+// message argument, by package
+var messageArgs = map[string]map[string]int{
+	"helpers": {
+		"ErrBadRequest":     0,
+		"ErrBadRequestf":    0,
+		"ErrUnauthorized":   0,
+		"ErrForbidden":      0,
+		"ErrNotFound":       0,
+		"ErrConflict":       0,
+		"ErrInternalServer": 0,
+		"NewAppError":       1,
+		"NewCodeError":      1,
+		"NewCodeErrorf":     1,
+		"ResponseHttp":      2,
+	},
+	"i18n": {
+		"T":         1,
+		"Translate": 1,
+		"Message":   0,
+	},
+}
+
+// Scan collects the messages passed to the helpers error constructors,
+// helpers.ResponseHttp, AppError.WithField and the i18n functions, and the
+// JSON names of validated request fields in the dto package
+func Scan(dir string) (*ScanResult, error) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", "vendor", "testdata", "node_modules":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &scanner{fset: fset, dir: dir, consts: map[string]string{}, seen: map[string]bool{}, result: &ScanResult{}}
+	for _, file := range files {
+		s.collectConsts(file)
+	}
+	for _, file := range files {
+		s.scanFile(file)
+	}
+
+	sort.Slice(s.result.Messages, func(i, j int) bool {
+		return s.result.Messages[i].Key < s.result.Messages[j].Key
+	})
+	sort.Strings(s.result.Dynamic)
+	return s.result, nil
+}
+
+// Missing returns the messages without a translation or field label in locale
+func (r *ScanResult) Missing(locale Locale) []SourceMessage {
+	var missing []SourceMessage
+	for _, message := range r.Messages {
+		if message.Field && !HasFieldLabel(locale, message.Key) || !message.Field && !HasTranslation(locale, message.Key) {
+			missing = append(missing, message)
+		}
+	}
+	return missing
+}
+
+// Unused returns the translations in locale no message refers to anymore
+func (r *ScanResult) Unused(locale Locale) []string {
+	used := map[string]bool{}
+	for _, message := range r.Messages {
+		if !message.Field {
+			used[message.Key] = true
+		}
+	}
+	var unused []string
+	for key := range catalogues[locale] {
+		if !used[key] {
+			unused = append(unused, key)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+type scanner struct {
+	fset   *token.FileSet
+	dir    string
+	consts map[string]string
+	seen   map[string]bool
+	result *ScanResult
+}
+
+// collectConsts records package level string constants and variables, so
+// messages such as constants.ErrBadRequest resolve
+func (s *scanner) collectConsts(file *ast.File) {
+	pkg := file.Name.Name
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST && gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value, ok := spec.(*ast.ValueSpec)
+			if !ok || len(value.Names) != len(value.Values) {
+				continue
+			}
+			for i, name := range value.Names {
+				if lit, ok := value.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					if unquoted, err := strconv.Unquote(lit.Value); err == nil {
+						s.consts[pkg+"."+name.Name] = unquoted
+					}
+				}
+			}
+		}
+	}
+}
+
+func (s *scanner) scanFile(file *ast.File) {
+	pkg := file.Name.Name
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.CallExpr:
+			s.scanCall(pkg, n)
+		case *ast.StructType:
+			if pkg == "dto" {
+				s.scanFields(n)
+			}
+		}
+		return true
+	})
+}
+
+func (s *scanner) scanCall(pkg string, call *ast.CallExpr) {
+	index := -1
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		// Calls inside the helpers and i18n packages themselves
+		if i, ok := messageArgs[pkg][fun.Name]; ok {
+			index = i
+		}
+	case *ast.SelectorExpr:
+		if x, ok := fun.X.(*ast.Ident); ok {
+			if i, ok := messageArgs[x.Name][fun.Sel.Name]; ok {
+				index = i
+			}
+		}
+		// AppError.WithField(field, message), told apart from logrus'
+		// WithField by the helpers call it is chained to
+		if fun.Sel.Name == "WithField" && rootedAtHelpers(fun.X) {
+			index = 1
+		}
+	}
+	if index < 0 || index >= len(call.Args) {
+		return
+	}
+
+	arg := call.Args[index]
+	if key, ok := s.resolve(pkg, arg); ok {
+		s.add(key, false, arg.Pos())
+		return
+	}
+	// Concatenations and calls such as err.Error() cannot be translated,
+	// conversions such as string(m) pass a key through and so does a
+	// wrapper formatting its own format parameter
+	switch a := arg.(type) {
+	case *ast.BinaryExpr:
+		s.result.Dynamic = append(s.result.Dynamic, s.position(arg.Pos()))
+	case *ast.CallExpr:
+		fun, ok := a.Fun.(*ast.SelectorExpr)
+		if !ok || isPackage(fun.X, "i18n") || s.formatsParameter(pkg, a) {
+			return
+		}
+		s.result.Dynamic = append(s.result.Dynamic, s.position(arg.Pos()))
+	}
+}
+
+func (s *scanner) scanFields(st *ast.StructType) {
+	for _, field := range st.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+		tagValue, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+		tag := reflect.StructTag(tagValue)
+		if tag.Get("validate") == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		s.add(name, true, field.Pos())
+	}
+}
+
+func (s *scanner) add(key string, field bool, pos token.Pos) {
+	id := strconv.FormatBool(field) + key
+	if s.seen[id] {
+		return
+	}
+	s.seen[id] = true
+	s.result.Messages = append(s.result.Messages, SourceMessage{Key: key, Field: field, Position: s.position(pos)})
+}
+
+func (s *scanner) position(pos token.Pos) string {
+	p := s.fset.Position(pos)
+	if rel, err := filepath.Rel(s.dir, p.Filename); err == nil {
+		p.Filename = rel
+	}
+	return p.Filename + ":" + strconv.Itoa(p.Line)
+}
+
+// resolve evaluates constant string expressions
+func (s *scanner) resolve(pkg string, expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		unquoted, err := strconv.Unquote(e.Value)
+		return unquoted, err == nil
+	case *ast.ParenExpr:
+		return s.resolve(pkg, e.X)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		left, ok := s.resolve(pkg, e.X)
+		if !ok {
+			return "", false
+		}
+		right, ok := s.resolve(pkg, e.Y)
+		return left + right, ok
+	case *ast.Ident:
+		value, ok := s.consts[pkg+"."+e.Name]
+		return value, ok
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			value, ok := s.consts[x.Name+"."+e.Sel.Name]
+			return value, ok
+		}
+	}
+	return "", false
+}
+
+// formatsParameter reports whether call is fmt.Sprintf of a format that is
+// not a constant, i.e. a parameter whose callers are scanned instead
+func (s *scanner) formatsParameter(pkg string, call *ast.CallExpr) bool {
+	fun, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !isPackage(fun.X, "fmt") || fun.Sel.Name != "Sprintf" || len(call.Args) == 0 {
+		return false
+	}
+	format, ok := call.Args[0].(*ast.Ident)
+	if !ok {
+		return false
+	}
+	_, constant := s.consts[pkg+"."+format.Name]
+	return !constant
+}
+
+func isPackage(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+// rootedAtHelpers reports whether a method chain starts with a helpers call,
+// e.g. helpers.NewCodeError(...).WithField(...)
+func rootedAtHelpers(expr ast.Expr) bool {
+	for {
+		switch e := expr.(type) {
+		case *ast.CallExpr:
+			expr = e.Fun
+		case *ast.SelectorExpr:
+			if _, ok := e.X.(*ast.Ident); ok {
+				return isPackage(e.X, "helpers")
+			}
+			expr = e.X
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+)
+
+type IAccountDeletionService interface {
+	RequestDeletion(ctx context.Context, userID int, req *dto.DeleteAccountRequest) (*dto.DeleteAccountResponse, error)
+	PurgeDue(ctx context.Context) (int, error)
+}
+
+type IAccountDeletionRepository interface {
+	ScheduleDeletion(ctx context.Context, userID int, at time.Time) error
+	FindDue(ctx context.Context, now time.Time, limit int) ([]models.User, error)
+	Anonymize(ctx context.Context, user *models.User) error
+}
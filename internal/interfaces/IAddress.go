@@ -0,0 +1,24 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+)
+
+type IAddressService interface {
+	List(ctx context.Context, userID int) ([]dto.AddressResponse, error)
+	Get(ctx context.Context, userID, id int) (*dto.AddressResponse, error)
+	Create(ctx context.Context, userID int, req *dto.AddressRequest) (*dto.AddressResponse, error)
+	Update(ctx context.Context, userID, id int, req *dto.UpdateAddressRequest) (*dto.AddressResponse, error)
+	Delete(ctx context.Context, userID, id int) error
+}
+
+type IAddressRepository interface {
+	ListByUserID(ctx context.Context, userID int) ([]models.Address, error)
+	CountByUserID(ctx context.Context, userID int) (int64, error)
+	FindByID(ctx context.Context, userID, id int) (*models.Address, error)
+	Save(ctx context.Context, address *models.Address) error
+	Delete(ctx context.Context, address *models.Address) error
+}
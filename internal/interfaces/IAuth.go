@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
@@ -16,19 +17,22 @@ type IAuthService interface {
 	ChangePassword(ctx context.Context, userID int, req *dto.ChangePasswordRequest) error
 	Logout(ctx context.Context, userID int, token string) error
 	GetProfile(ctx context.Context, userID int) (*dto.UserResponse, error)
+	UpdateProfile(ctx context.Context, userID int, req *dto.UpdateProfileRequest) (*dto.UserResponse, error)
 }
 
 type IAuthRepository interface {
-	CreateUser(ctx context.Context, user *models.User) error
-	FindByEmail(ctx context.Context, email string) (*models.User, error)
-	FindByPhone(ctx context.Context, phone string) (*models.User, error)
-	FindByUsername(ctx context.Context, username string) (*models.User, error)
-	FindByEmailOrUsername(ctx context.Context, emailOrUsername string) (*models.User, error)
+	RegisterUser(ctx context.Context, user *models.User, roleID int, consents []models.Consent) error
+	FindByEmail(ctx context.Context, tenantID int, email string) (*models.User, error)
+	FindByPhone(ctx context.Context, tenantID int, phone string) (*models.User, error)
+	FindByUsername(ctx context.Context, tenantID int, username string) (*models.User, error)
+	FindByEmailOrUsername(ctx context.Context, tenantID int, emailOrUsername string) (*models.User, error)
 	FindByID(ctx context.Context, id int) (*models.User, error)
 	UpdatePassword(ctx context.Context, userID int, hashedPassword string) error
+	UpdateProfile(ctx context.Context, userID int, updates map[string]interface{}) error
 	SaveResetToken(ctx context.Context, userID int, token string, expiry string) error
-	FindByResetToken(ctx context.Context, token string) (*models.User, error)
+	FindByResetToken(ctx context.Context, tenantID int, token string) (*models.User, error)
 	ClearResetToken(ctx context.Context, userID int) error
+	CancelDeletion(ctx context.Context, userID int) error
 
 	// Session management
 	CreateSession(ctx context.Context, session *models.UserSession) error
@@ -37,4 +41,6 @@ type IAuthRepository interface {
 	UpdateSession(ctx context.Context, session *models.UserSession) error
 	DeleteSession(ctx context.Context, token string) error
 	DeleteSessionsByUserID(ctx context.Context, userID int) error
+	DeleteExpiredSessions(ctx context.Context, before time.Time) (int64, error)
+	CountActiveSessions(ctx context.Context, now time.Time) (int64, error)
 }
@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+)
+
+type IAuthzService interface {
+	Check(ctx context.Context, claims *helpers.JWTClaims, req *dto.AuthzCheckRequest) *dto.AuthzCheckResponse
+}
@@ -0,0 +1,34 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+)
+
+type IConsentService interface {
+	ListCurrentDocuments(ctx context.Context) ([]dto.LegalDocumentResponse, error)
+	PublishDocument(ctx context.Context, req *dto.PublishLegalDocumentRequest) (*dto.LegalDocumentResponse, error)
+	GetStatus(ctx context.Context, userID int) (*dto.ConsentStatusResponse, error)
+	UpdateConsent(ctx context.Context, userID int, req *dto.UpdateConsentRequest) (*dto.ConsentStatusResponse, error)
+	ListMarketingConsents(ctx context.Context, query *dto.MarketingConsentQuery) ([]models.MarketingConsent, error)
+}
+
+type IConsentRepository interface {
+	FindCurrentDocuments(ctx context.Context, tenantID int) ([]models.LegalDocument, error)
+	CreateDocument(ctx context.Context, document *models.LegalDocument) error
+	CreateConsents(ctx context.Context, consents []models.Consent) error
+	FindLatestConsents(ctx context.Context, userID int) ([]models.Consent, error)
+	ListMarketingConsents(ctx context.Context, tenantID int, filter MarketingConsentFilter) ([]models.MarketingConsent, error)
+}
+
+// MarketingConsentFilter narrows the marketing consent listing
+type MarketingConsentFilter struct {
+	Types        []string
+	Granted      *bool
+	UpdatedSince *time.Time
+	Offset       int
+	Limit        int
+}
@@ -0,0 +1,31 @@
+package interfaces
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+)
+
+type IDataExportService interface {
+	RequestExport(ctx context.Context, userID int) (*dto.DataExportResponse, error)
+	ListExports(ctx context.Context, userID int) ([]dto.DataExportResponse, error)
+	Download(ctx context.Context, req *dto.DataExportDownloadRequest) (io.ReadCloser, string, error)
+	ProcessPending(ctx context.Context) (int, error)
+	PurgeExpired(ctx context.Context) (int, error)
+}
+
+type IDataExportRepository interface {
+	Create(ctx context.Context, export *models.DataExport) error
+	FindByID(ctx context.Context, id int) (*models.DataExport, error)
+	FindActiveByUserID(ctx context.Context, userID int) (*models.DataExport, error)
+	ListByUserID(ctx context.Context, userID int) ([]models.DataExport, error)
+	ClaimPending(ctx context.Context, limit int) ([]models.DataExport, error)
+	MarkReady(ctx context.Context, export *models.DataExport) error
+	MarkFailed(ctx context.Context, id int, reason string) error
+	FindExpired(ctx context.Context, now time.Time, limit int) ([]models.DataExport, error)
+	MarkExpired(ctx context.Context, id int) error
+	FindUserData(ctx context.Context, userID int) (*models.UserData, error)
+}
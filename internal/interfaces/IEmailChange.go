@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+)
+
+type IEmailChangeService interface {
+	RequestChange(ctx context.Context, userID int, req *dto.RequestEmailChangeRequest) error
+	Confirm(ctx context.Context, req *dto.EmailChangeTokenRequest) error
+	Cancel(ctx context.Context, req *dto.EmailChangeTokenRequest) error
+}
+
+type IEmailChangeRepository interface {
+	Create(ctx context.Context, request *models.EmailChangeRequest) error
+	FindByConfirmTokenHash(ctx context.Context, tenantID int, tokenHash string) (*models.EmailChangeRequest, error)
+	FindByCancelTokenHash(ctx context.Context, tenantID int, tokenHash string) (*models.EmailChangeRequest, error)
+	Apply(ctx context.Context, request *models.EmailChangeRequest) error
+	Cancel(ctx context.Context, request *models.EmailChangeRequest) error
+	Revert(ctx context.Context, request *models.EmailChangeRequest) error
+}
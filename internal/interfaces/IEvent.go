@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/events"
+)
+
+// IEventPublisher publishes domain events for other services
+type IEventPublisher interface {
+	Publish(ctx context.Context, event events.Event) error
+}
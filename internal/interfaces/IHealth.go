@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+)
+
+type IHealthService interface {
+	Live(ctx context.Context) *dto.HealthResponse
+	Ready(ctx context.Context) (*dto.HealthResponse, bool)
+	SetShuttingDown()
+}
+
+type IHealthRepository interface {
+	Ping(ctx context.Context) error
+}
@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+)
+
+type IIdempotencyService interface {
+	Begin(ctx context.Context, key *models.IdempotencyKey) (*models.IdempotencyKey, error)
+	Complete(ctx context.Context, key *models.IdempotencyKey) error
+	Release(ctx context.Context, key *models.IdempotencyKey) error
+	PurgeExpired(ctx context.Context) (int, error)
+}
+
+type IIdempotencyRepository interface {
+	Reserve(ctx context.Context, key *models.IdempotencyKey, staleBefore time.Time) (bool, *models.IdempotencyKey, error)
+	Complete(ctx context.Context, key *models.IdempotencyKey) error
+	Delete(ctx context.Context, id int) error
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+)
+
+type IKeyRotationService interface {
+	Rotate(ctx context.Context) (*dto.KeyRotationResult, error)
+	RotateAll(ctx context.Context) (int, error)
+}
+
+type IKeyRotationRepository interface {
+	FindUsersToReencrypt(ctx context.Context, activePrefix string, afterID, limit int) ([]int, error)
+	ReencryptUser(ctx context.Context, activePrefix string, id int) (bool, error)
+}
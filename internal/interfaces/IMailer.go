@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/mailer"
+)
+
+// IMailer sends transactional emails
+type IMailer interface {
+	Send(ctx context.Context, msg mailer.Message) error
+	// Ping checks the mail server can be reached
+	Ping(ctx context.Context) error
+}
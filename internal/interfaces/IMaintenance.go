@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+)
+
+// IMaintenanceService holds the operations run from the command line by operators
+type IMaintenanceService interface {
+	CreateAdmin(ctx context.Context, req *dto.CreateAdminRequest) (*models.User, error)
+	SetRole(ctx context.Context, tenantID int, login, role string) (*models.User, error)
+	ActivateUser(ctx context.Context, tenantID int, login string) (*models.User, error)
+	DeactivateUser(ctx context.Context, tenantID int, login string) (*models.User, error)
+	PurgeExpiredSessions(ctx context.Context) (int, error)
+}
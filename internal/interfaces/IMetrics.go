@@ -0,0 +1,7 @@
+package interfaces
+
+import "context"
+
+type ISessionMetricsService interface {
+	Collect(ctx context.Context) (int, error)
+}
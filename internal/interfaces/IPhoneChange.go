@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+)
+
+type IPhoneChangeService interface {
+	RequestChange(ctx context.Context, userID int, req *dto.RequestPhoneChangeRequest) error
+	Verify(ctx context.Context, userID int, req *dto.VerifyPhoneChangeRequest) error
+}
+
+type IPhoneChangeRepository interface {
+	Create(ctx context.Context, request *models.PhoneChangeRequest) error
+	FindPendingByUserID(ctx context.Context, userID int) (*models.PhoneChangeRequest, error)
+	IncrementAttempts(ctx context.Context, id int) error
+	Apply(ctx context.Context, request *models.PhoneChangeRequest) error
+}
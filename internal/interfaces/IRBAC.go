@@ -0,0 +1,38 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+)
+
+type IRBACService interface {
+	ListRoles(ctx context.Context) ([]dto.RoleResponse, error)
+	ListPermissions(ctx context.Context) ([]dto.PermissionResponse, error)
+	CreatePermission(ctx context.Context, actor *helpers.JWTClaims, req *dto.CreatePermissionRequest) (*dto.PermissionResponse, error)
+	SetRolePermissions(ctx context.Context, actor *helpers.JWTClaims, roleName string, req *dto.SetRolePermissionsRequest) (*dto.RoleResponse, error)
+	GrantPermission(ctx context.Context, actor *helpers.JWTClaims, roleName string, req *dto.GrantPermissionRequest) (*dto.RoleResponse, error)
+	RevokePermission(ctx context.Context, actor *helpers.JWTClaims, roleName, permissionName string) (*dto.RoleResponse, error)
+	GetUserRoles(ctx context.Context, userID int) (*dto.UserAccessResponse, error)
+	AssignUserRole(ctx context.Context, actor *helpers.JWTClaims, userID int, req *dto.AssignRoleRequest) (*dto.UserAccessResponse, error)
+	RevokeUserRole(ctx context.Context, actor *helpers.JWTClaims, userID int, roleName string) (*dto.UserAccessResponse, error)
+}
+
+type IRBACRepository interface {
+	ListRoles(ctx context.Context) ([]models.Role, error)
+	FindRoleByName(ctx context.Context, name string) (*models.Role, error)
+	ListPermissions(ctx context.Context) ([]models.Permission, error)
+	FindPermissionsByNames(ctx context.Context, names []string) ([]models.Permission, error)
+	CreatePermission(ctx context.Context, permission *models.Permission) error
+	ReplaceRolePermissions(ctx context.Context, role *models.Role, permissions []models.Permission) error
+	AddRolePermission(ctx context.Context, role *models.Role, permission *models.Permission) error
+	RemoveRolePermission(ctx context.Context, role *models.Role, permission *models.Permission) error
+
+	// User role assignments
+	FindRolesByUserID(ctx context.Context, userID int) ([]models.Role, error)
+	AssignRole(ctx context.Context, userID, roleID int) error
+	RemoveRole(ctx context.Context, userID, roleID int) error
+	SetRole(ctx context.Context, userID, roleID int) ([]models.Role, error)
+}
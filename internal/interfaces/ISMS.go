@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/sms"
+)
+
+// ISMSSender sends text messages such as OTPs
+type ISMSSender interface {
+	Send(ctx context.Context, msg sms.Message) error
+}
@@ -0,0 +1,30 @@
+package interfaces
+
+import (
+	"context"
+	"io"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+)
+
+type ISellerService interface {
+	Apply(ctx context.Context, userID int, req *dto.SellerApplicationRequest, documents []dto.DocumentUpload) (*dto.SellerApplicationResponse, error)
+	GetMyApplication(ctx context.Context, userID int) (*dto.SellerApplicationResponse, error)
+	ListApplications(ctx context.Context, status string) ([]dto.SellerApplicationResponse, error)
+	GetApplication(ctx context.Context, id int) (*dto.SellerApplicationResponse, error)
+	Approve(ctx context.Context, reviewerID, id int, req *dto.ReviewSellerApplicationRequest) (*dto.SellerApplicationResponse, error)
+	Reject(ctx context.Context, reviewerID, id int, req *dto.ReviewSellerApplicationRequest) (*dto.SellerApplicationResponse, error)
+	OpenDocument(ctx context.Context, applicationID, documentID int) (*models.SellerDocument, io.ReadCloser, error)
+	PurgeDeletedDocuments(ctx context.Context) (int, error)
+}
+
+type ISellerRepository interface {
+	CreateApplication(ctx context.Context, application *models.SellerApplication) error
+	FindApplicationByID(ctx context.Context, tenantID, id int) (*models.SellerApplication, error)
+	FindLatestApplicationByUserID(ctx context.Context, userID int) (*models.SellerApplication, error)
+	ListApplications(ctx context.Context, tenantID int, status string) ([]models.SellerApplication, error)
+	ReviewApplication(ctx context.Context, application *models.SellerApplication, grantRoleID int) error
+	FindDeletedDocuments(ctx context.Context, limit int) ([]models.SellerDocument, error)
+	DeleteDocument(ctx context.Context, id int) error
+}
@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"io"
+)
+
+// IBlobStore stores opaque files such as KYC documents and data exports
+type IBlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
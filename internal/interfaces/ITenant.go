@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+)
+
+type ITenantService interface {
+	Resolve(ctx context.Context, tenantHeader, host string) (*models.Tenant, error)
+}
+
+type ITenantRepository interface {
+	FindByID(ctx context.Context, id int) (*models.Tenant, error)
+	FindBySlug(ctx context.Context, slug string) (*models.Tenant, error)
+	FindByDomain(ctx context.Context, domain string) (*models.Tenant, error)
+}
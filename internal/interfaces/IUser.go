@@ -2,19 +2,33 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
-	"github.com/labstack/echo/v4"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
 )
 
-type IUserRepository interface {
-	InsertNewUser(ctx context.Context, user *models.User) error
+// IUserLifecycleService owns every change to the existence, status and roles
+// of a user. Sign-up, the admin API, account deletion and the command line
+// all go through it.
+type IUserLifecycleService interface {
+	Create(ctx context.Context, tenant *models.Tenant, input *dto.CreateUserInput) (*models.User, error)
+	Find(ctx context.Context, tenantID, userID int) (*models.User, error)
+	Activate(ctx context.Context, user *models.User) error
+	Deactivate(ctx context.Context, user *models.User) error
+	AssignRole(ctx context.Context, user *models.User, roleName string) error
+	AssignRoleWith(ctx context.Context, user *models.User, roleName string, grant func(ctx context.Context, roleID int) error) error
+	RevokeRole(ctx context.Context, user *models.User, roleName string) error
+	SetRole(ctx context.Context, user *models.User, roleName string) error
+	ScheduleDeletion(ctx context.Context, user *models.User, at time.Time) error
+	CancelDeletion(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, user *models.User) error
 }
 
-type IUserService interface {
-	Register(ctx context.Context, req *models.User, role string) (*models.User, error)
-}
-
-type IUserAPI interface {
-	RegisterUser(e echo.Context) error
+// IUserHook extends account creation. BeforeCreate runs before the user is
+// stored and rejects it by returning an error, e.g. an *helpers.AppError with
+// field errors; AfterCreate runs once the user is committed.
+type IUserHook interface {
+	BeforeCreate(ctx context.Context, user *models.User, input *dto.CreateUserInput) error
+	AfterCreate(ctx context.Context, user *models.User)
 }
@@ -0,0 +1,102 @@
+// Package logging has the logrus hooks of the service: masking credentials
+// and one-time codes before log entries are written, and adding trace IDs.
+package logging
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Redacted replaces a masked value
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are field name fragments whose values are always masked
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "otp", "authorization", "cookie", "signature", "api_key"}
+
+// valuePatterns mask secrets embedded in free text such as email bodies,
+// URLs or error messages
+var valuePatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	// JSON web tokens
+	{regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`), Redacted},
+	// Authorization header values
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]+`), "$1 " + Redacted},
+	// Query string and form parameters, e.g. links with ?token=
+	{regexp.MustCompile(`(?i)\b(token|refresh_token|access_token|signature|otp|code|password)=[^&\s"']+`), "$1=" + Redacted},
+	// JSON fields
+	{regexp.MustCompile(`(?i)"(password|new_password|old_password|token|refresh_token|access_token|otp|code|secret)"\s*:\s*"[^"]*"`), `"$1":"` + Redacted + `"`},
+	// One-time codes in text messages, "123456 is your verification code"
+	{regexp.MustCompile(`(?i)\b\d{4,8}(\s+is your (?:verification |one-time )?code)`), Redacted + "$1"},
+	{regexp.MustCompile(`(?i)\b(code|otp)(\s*(?:is|:|=)\s*)\d{4,8}\b`), "$1$2" + Redacted},
+}
+
+// IsSensitiveKey reports whether values of the field key are masked
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, fragment := range sensitiveKeys {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString masks secrets found in free text
+func RedactString(s string) string {
+	for _, p := range valuePatterns {
+		s = p.pattern.ReplaceAllString(s, p.replacement)
+	}
+	return s
+}
+
+// RedactHook masks sensitive fields and secrets in messages. Register it
+// last so fields added by other hooks are covered too.
+type RedactHook struct{}
+
+func (RedactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (RedactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = RedactString(entry.Message)
+	for key, value := range entry.Data {
+		entry.Data[key] = redactValue(key, value)
+	}
+	return nil
+}
+
+func redactValue(key string, value interface{}) interface{} {
+	if IsSensitiveKey(key) {
+		return Redacted
+	}
+
+	switch v := value.(type) {
+	case string:
+		return RedactString(v)
+	case error:
+		return RedactString(v.Error())
+	case map[string]interface{}:
+		// Copy, the map may still be used by the caller
+		redacted := make(map[string]interface{}, len(v))
+		for k, nested := range v {
+			redacted[k] = redactValue(k, nested)
+		}
+		return redacted
+	case map[string]string:
+		redacted := make(map[string]string, len(v))
+		for k, nested := range v {
+			if IsSensitiveKey(k) {
+				redacted[k] = Redacted
+			} else {
+				redacted[k] = RedactString(nested)
+			}
+		}
+		return redacted
+	default:
+		return value
+	}
+}
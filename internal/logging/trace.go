@@ -0,0 +1,26 @@
+package logging
+
+import (
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceHook adds trace_id and span_id to entries logged with WithContext
+// inside a span
+type TraceHook struct{}
+
+func (TraceHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (TraceHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	sc := trace.SpanContextFromContext(entry.Context)
+	if sc.IsValid() {
+		entry.Data["trace_id"] = sc.TraceID().String()
+		entry.Data["span_id"] = sc.SpanID().String()
+	}
+	return nil
+}
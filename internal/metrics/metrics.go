@@ -0,0 +1,97 @@
+// Package metrics defines the Prometheus metrics of the service and the
+// handler exposing them.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Reasons a login fails, the values of auth_login_failures_total
+const (
+	LoginReasonUnknownUser     = "unknown_user"
+	LoginReasonInvalidPassword = "invalid_password"
+	LoginReasonInactive        = "inactive"
+)
+
+// Registry holds the metrics served on /metrics, with the Go runtime and
+// process collectors
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+// Latency buckets in seconds, from 5ms to 10s
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Label values must come from a small fixed set, such as route templates or
+// reasons, never from user input
+var (
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method, route template and status code.",
+		Buckets: durationBuckets,
+	}, []string{"method", "route", "status"})
+
+	LoginAttempts = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_login_attempts_total",
+		Help: "Login attempts by result (success or failure).",
+	}, []string{"result"})
+	LoginFailures = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_login_failures_total",
+		Help: "Failed logins by reason.",
+	}, []string{"reason"})
+	Registrations = factory.NewCounter(prometheus.CounterOpts{
+		Name: "auth_registrations_total",
+		Help: "Accounts registered.",
+	})
+	TokenRefreshes = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_token_refreshes_total",
+		Help: "Refresh token exchanges by result (success or failure).",
+	}, []string{"result"})
+	PasswordResets = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_password_resets_total",
+		Help: "Password resets by stage (requested or completed).",
+	}, []string{"stage"})
+	ActiveSessions = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "auth_active_sessions",
+		Help: "Sessions whose refresh token has not expired, updated every minute.",
+	})
+	PasswordHashDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "auth_password_hash_duration_seconds",
+		Help:    "Time spent in bcrypt by operation (hash or compare).",
+		Buckets: []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+	}, []string{"operation"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// RegisterDBStats exposes the connection pool statistics of db as the
+// go_sql_* metrics
+func RegisterDBStats(db *sql.DB, dbName string) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Since returns the seconds elapsed since start, for histograms
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// AccessLog writes one structured entry per request. The query string is
+// left out, it may carry tokens from email links.
+func AccessLog() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			// Write the error response here so the status code is known
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			req := c.Request()
+			res := c.Response()
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			fields := logrus.Fields{
+				"method":     req.Method,
+				"route":      route,
+				"path":       req.URL.Path,
+				"status":     res.Status,
+				"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+				"bytes_out":  res.Size,
+				"ip":         c.RealIP(),
+				"user_agent": req.UserAgent(),
+			}
+
+			// The request logger already carries request_id, user_id and tenant
+			entry := helpers.Log(req.Context()).WithFields(fields)
+			switch {
+			case res.Status >= http.StatusInternalServerError:
+				entry.Error("Request failed")
+			case res.Status >= http.StatusBadRequest:
+				entry.Warn("Request rejected")
+			default:
+				entry.Info("Request completed")
+			}
+			return nil
+		}
+	}
+}
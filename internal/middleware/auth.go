@@ -1,10 +1,11 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
 	"github.com/labstack/echo/v4"
 )
 
@@ -15,29 +16,31 @@ func JWTMiddleware() echo.MiddlewareFunc {
 			// Get token from Authorization header
 			authHeader := c.Request().Header.Get("Authorization")
 			if authHeader == "" {
-				return helpers.ResponseHttp(c, http.StatusUnauthorized, "Missing authorization header", nil)
+				return helpers.NewCodeError(helpers.CodeAuthTokenMissing, "Missing authorization header")
 			}
 
 			// Check if it's a Bearer token
 			parts := strings.Split(authHeader, " ")
 			if len(parts) != 2 || parts[0] != "Bearer" {
-				return helpers.ResponseHttp(c, http.StatusUnauthorized, "Invalid authorization header format", nil)
+				return helpers.NewCodeError(helpers.CodeAuthTokenInvalid, "Invalid authorization header format")
 			}
 
 			token := parts[1]
 
-			// Validate token
-			claims, err := helpers.ValidateToken(token)
+			// Validate token against the tenant key
+			tenant := helpers.TenantFromContext(c.Request().Context())
+			claims, err := helpers.ValidateToken(token, tenantSigningKey(tenant))
 			if err != nil {
-				return helpers.ResponseHttp(c, http.StatusUnauthorized, "Invalid or expired token", nil)
+				return helpers.NewCodeError(helpers.CodeAuthTokenInvalid, "Invalid or expired token")
+			}
+
+			// Reject tokens issued for another tenant
+			if tenant != nil && claims.TenantID != tenant.ID {
+				return helpers.NewCodeError(helpers.CodeAuthTokenInvalid, "Token is not valid for this tenant")
 			}
 
 			// Add user info to context
-			c.Set("user_id", claims.UserID)
-			c.Set("email", claims.Email)
-			c.Set("username", claims.Username)
-			c.Set("role", claims.Role)
-			c.Set("token", token)
+			setClaims(c, claims, token)
 
 			return next(c)
 		}
@@ -57,14 +60,11 @@ func OptionalJWTMiddleware() echo.MiddlewareFunc {
 					token := parts[1]
 
 					// Validate token
-					claims, err := helpers.ValidateToken(token)
-					if err == nil {
+					tenant := helpers.TenantFromContext(c.Request().Context())
+					claims, err := helpers.ValidateToken(token, tenantSigningKey(tenant))
+					if err == nil && (tenant == nil || claims.TenantID == tenant.ID) {
 						// Add user info to context
-						c.Set("user_id", claims.UserID)
-						c.Set("email", claims.Email)
-						c.Set("username", claims.Username)
-						c.Set("role", claims.Role)
-						c.Set("token", token)
+						setClaims(c, claims, token)
 					}
 				}
 			}
@@ -78,19 +78,66 @@ func OptionalJWTMiddleware() echo.MiddlewareFunc {
 func RoleMiddleware(allowedRoles ...string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			userRole, ok := c.Get("role").(string)
+			claims, ok := c.Get("claims").(*helpers.JWTClaims)
+			if !ok {
+				return helpers.ErrUnauthorized("Unauthorized")
+			}
+
+			// Check if any of the user roles is in allowed roles
+			if claims.HasRole(allowedRoles...) {
+				return next(c)
+			}
+
+			return helpers.NewCodeError(helpers.CodeAuthPermissionDenied, "Insufficient permissions")
+		}
+	}
+}
+
+// RequirePermission checks if the user's token grants every required permission.
+// It must be used after JWTMiddleware.
+func RequirePermission(permissions ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, ok := c.Get("claims").(*helpers.JWTClaims)
 			if !ok {
-				return helpers.ResponseHttp(c, http.StatusUnauthorized, "Unauthorized", nil)
+				return helpers.ErrUnauthorized("Unauthorized")
 			}
 
-			// Check if user role is in allowed roles
-			for _, role := range allowedRoles {
-				if userRole == role {
-					return next(c)
+			for _, permission := range permissions {
+				if !claims.HasPermission(permission) {
+					return helpers.NewCodeError(helpers.CodeAuthPermissionDenied, "Insufficient permissions")
 				}
 			}
 
-			return helpers.ResponseHttp(c, http.StatusForbidden, "Insufficient permissions", nil)
+			return next(c)
 		}
 	}
 }
+
+// tenantSigningKey returns the tenant's own JWT key, empty means JWT_SECRET
+func tenantSigningKey(tenant *models.Tenant) string {
+	if tenant == nil {
+		return ""
+	}
+	return tenant.JWTSecret
+}
+
+// setClaims adds the token claims to the request context
+func setClaims(c echo.Context, claims *helpers.JWTClaims, token string) {
+	c.Set("user_id", claims.UserID)
+	c.Set("tenant_id", claims.TenantID)
+	c.Set("email", claims.Email)
+	c.Set("username", claims.Username)
+	c.Set("role", claims.Role)
+	c.Set("roles", claims.Roles)
+	c.Set("permissions", claims.Permissions)
+	c.Set("claims", claims)
+	c.Set("token", token)
+
+	addLogFields(c, map[string]interface{}{"user_id": claims.UserID})
+
+	// The saved preference applies unless the request asked for a language
+	if locale, ok := i18n.Parse(claims.Locale); ok && !i18n.IsExplicit(c.Request().Context()) {
+		setLocale(c, locale, false)
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/labstack/echo/v4"
+)
+
+// ResourceAttributes extracts resource attributes from the request, e.g. path params
+type ResourceAttributes func(c echo.Context) map[string]interface{}
+
+// Authorize evaluates the policy engine for the action and resource type.
+// It must be used after JWTMiddleware.
+func Authorize(authzService interfaces.IAuthzService, action, resourceType string, attributes ResourceAttributes) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, ok := c.Get("claims").(*helpers.JWTClaims)
+			if !ok {
+				return helpers.ErrUnauthorized("Unauthorized")
+			}
+
+			req := &dto.AuthzCheckRequest{
+				Action:      action,
+				Resource:    dto.AuthzResource{Type: resourceType},
+				Environment: map[string]interface{}{"ip": c.RealIP()},
+			}
+			if attributes != nil {
+				req.Resource.Attributes = attributes(c)
+			}
+
+			decision := authzService.Check(c.Request().Context(), claims, req)
+			if !decision.Allowed {
+				return helpers.NewCodeError(helpers.CodeAuthPermissionDenied, "Access denied by policy")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// PathParams exposes every path parameter as a resource attribute
+func PathParams(c echo.Context) map[string]interface{} {
+	names := c.ParamNames()
+	attributes := make(map[string]interface{}, len(names))
+	for i, name := range names {
+		attributes[name] = c.ParamValues()[i]
+	}
+	return attributes
+}
@@ -1,67 +1,130 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/constants"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
 	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// ErrorHandler handles all errors in a standardized way
+// ErrorHandler writes every error with its error code, as a BaseResponse or
+// as application/problem+json when configured or accepted by the client
 func ErrorHandler(err error, c echo.Context) {
 	if c.Response().Committed {
 		return
 	}
 
-	// Check if it's our custom AppError
-	if appErr, ok := err.(*helpers.AppError); ok {
-		_ = helpers.ResponseHttp(c, appErr.Code, appErr.Message, map[string]string{
-			"details": appErr.Details,
-		})
-		return
-	}
+	appErr := toAppError(err, i18n.FromContext(c.Request().Context()))
 
-	// Check if it's echo.HTTPError
-	if he, ok := err.(*echo.HTTPError); ok {
-		code := he.Code
-		message := "An error occurred"
+	// Client errors are expected, only server errors fail the request span
+	// and are logged with their cause
+	if appErr.Code >= http.StatusInternalServerError {
+		ctx := c.Request().Context()
+		helpers.RecordSpanError(trace.SpanFromContext(ctx), err)
 
-		if msg, ok := he.Message.(string); ok {
-			message = msg
+		// err keeps the English message for the logs
+		entry := helpers.Log(ctx)
+		if cause := errors.Unwrap(appErr); cause != nil && cause != err {
+			entry = entry.WithField("cause", cause.Error())
 		}
+		entry.WithError(err).Error("Request failed with a server error")
+	}
 
-		_ = helpers.ResponseHttp(c, code, message, nil)
+	if wantsProblem(c) {
+		_ = helpers.ProblemResponseHttp(c, appErr)
 		return
 	}
+	_ = helpers.ErrorResponseHttp(c, appErr)
+}
 
-	// Check if it's validation error
-	if validationErrs, ok := err.(validator.ValidationErrors); ok {
-		errors := make(map[string]string)
+// toAppError maps any error to an AppError with an error code, translated
+// into locale
+func toAppError(err error, locale i18n.Locale) *helpers.AppError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		appErr := helpers.NewCodeError(helpers.CodeValidation, i18n.Translate(locale, "Validation failed"))
 		for _, fieldErr := range validationErrs {
-			errors[fieldErr.Field()] = getValidationErrorMessage(fieldErr)
+			appErr = appErr.WithField(fieldErr.Field(), getValidationErrorMessage(fieldErr, locale))
 		}
-		_ = helpers.ResponseHttp(c, http.StatusBadRequest, "Validation failed", errors)
-		return
+		return appErr
 	}
 
-	// Default internal server error
-	_ = helpers.ResponseHttp(c, http.StatusInternalServerError, "Internal server error", nil)
+	return codedError(err).Localize(locale)
+}
+
+// codedError maps errors other than validation errors to an AppError
+func codedError(err error) *helpers.AppError {
+	var appErr *helpers.AppError
+	if errors.As(err, &appErr) {
+		if appErr.ErrorCode == "" {
+			appErr = appErr.Wrap(appErr.Cause)
+			appErr.ErrorCode = helpers.CodeForStatus(appErr.Code)
+		}
+		return appErr
+	}
+
+	// echo's messages are replaced, they are not translated
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return helpers.NewAppError(he.Code, string(httpErrorMessage(he.Code)), "").Wrap(he.Internal)
+	}
+
+	var conflict *constants.ConflictError
+	if errors.As(err, &conflict) {
+		return helpers.ErrAlreadyInUse(conflict.Fields...)
+	}
+
+	return helpers.ErrInternalServer("Internal server error").Wrap(err)
+}
+
+func httpErrorMessage(status int) i18n.Message {
+	switch status {
+	case http.StatusNotFound:
+		return i18n.Message("Resource not found")
+	case http.StatusMethodNotAllowed:
+		return i18n.Message("Method not allowed")
+	case http.StatusRequestEntityTooLarge:
+		return i18n.Message("Request body is too large")
+	case http.StatusTooManyRequests:
+		return i18n.Message("Too many requests, please try again later")
+	case http.StatusServiceUnavailable:
+		return i18n.Message("Service is temporarily unavailable")
+	}
+	if status >= http.StatusInternalServerError {
+		return i18n.Message("Internal server error")
+	}
+	return i18n.Message("Invalid request")
+}
+
+func wantsProblem(c echo.Context) bool {
+	if helpers.Config.HTTP.ErrorFormat == "problem" {
+		return true
+	}
+	return strings.Contains(c.Request().Header.Get(echo.HeaderAccept), helpers.MIMEProblemJSON)
 }
 
-func getValidationErrorMessage(fe validator.FieldError) string {
+func getValidationErrorMessage(fe validator.FieldError, locale i18n.Locale) string {
+	field := i18n.FieldLabel(locale, fe.Field())
 	switch fe.Tag() {
 	case "required":
-		return fe.Field() + " is required"
+		return i18n.Translate(locale, "%s is required", field)
 	case "email":
-		return "Invalid email format"
+		return i18n.Translate(locale, "Invalid email format")
 	case "min":
-		return fe.Field() + " must be at least " + fe.Param() + " characters"
+		return i18n.Translate(locale, "%s must be at least %s characters", field, fe.Param())
 	case "max":
-		return fe.Field() + " must be at most " + fe.Param() + " characters"
+		return i18n.Translate(locale, "%s must be at most %s characters", field, fe.Param())
 	case "datetime":
-		return "Invalid date format, expected " + fe.Param()
+		return i18n.Translate(locale, "Invalid date format, expected %s", fe.Param())
+	case "phone":
+		return i18n.Translate(locale, "Invalid phone number, use a mobile number such as 081234567890 or +6281234567890")
 	default:
-		return fe.Field() + " is invalid"
+		return i18n.Translate(locale, "%s is invalid", field)
 	}
 }
@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/constants"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// newErrorServer returns a server whose only route fails with err, and the
+// buffer its server errors are logged to
+func newErrorServer(t *testing.T, err error) (*echo.Echo, *bytes.Buffer) {
+	t.Helper()
+	previousLogger, previousHTTP := helpers.Logger, helpers.Config.HTTP
+	t.Cleanup(func() {
+		helpers.Logger = previousLogger
+		helpers.Config.HTTP = previousHTTP
+	})
+	var logs bytes.Buffer
+	helpers.Logger = logrus.New()
+	helpers.Logger.SetOutput(&logs)
+	helpers.Config.HTTP.ErrorFormat = "json"
+	helpers.Config.HTTP.ProblemTypeBaseURL = "https://docs.example.com/errors/"
+
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler
+	e.GET("/fail", func(c echo.Context) error { return err })
+	return e, &logs
+}
+
+func validationError(t *testing.T) error {
+	t.Helper()
+	input := struct {
+		Email string `validate:"required,email"`
+	}{Email: "not-an-email"}
+	err := helpers.GetValidator().Struct(input)
+	if err == nil {
+		t.Fatal("validation succeeded, want an error")
+	}
+	return err
+}
+
+func TestErrorHandlerProblemDetails(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		want   helpers.ProblemDetails
+		hidden string
+	}{
+		{
+			name: "validation error",
+			err:  validationError(t),
+			want: helpers.ProblemDetails{
+				Type: "https://docs.example.com/errors/validation-failed", Title: "Bad Request", Status: http.StatusBadRequest,
+				Detail: "Validation failed", Instance: "/fail", Code: helpers.CodeValidation,
+				Errors: map[string]string{"Email": "Invalid email format"},
+			},
+		},
+		{
+			name: "echo error",
+			err:  echo.NewHTTPError(http.StatusRequestEntityTooLarge, "body of 30MB over limit").SetInternal(errors.New("http: request body too large")),
+			want: helpers.ProblemDetails{
+				Type:  "https://docs.example.com/errors/payload-too-large",
+				Title: "Request Entity Too Large", Status: http.StatusRequestEntityTooLarge,
+				Detail: "Request body is too large", Instance: "/fail", Code: helpers.CodePayloadTooLarge,
+			},
+			hidden: "30MB",
+		},
+		{
+			name: "conflict error",
+			err:  &constants.ConflictError{Fields: []string{"email", "phone_number"}},
+			want: helpers.ProblemDetails{
+				Type: "https://docs.example.com/errors/user-email-taken", Title: "Conflict", Status: http.StatusConflict,
+				Detail: "Some of these details are already in use", Instance: "/fail", Code: helpers.CodeUserEmailTaken,
+				Errors: map[string]string{"email": "already in use", "phone_number": "already in use"},
+			},
+		},
+		{
+			name: "unexpected error",
+			err:  errors.New("pq: password authentication failed for user auth_db"),
+			want: helpers.ProblemDetails{
+				Type: "https://docs.example.com/errors/internal-error", Title: "Internal Server Error", Status: http.StatusInternalServerError,
+				Detail: "Internal server error", Instance: "/fail", Code: helpers.CodeInternal,
+			},
+			hidden: "password authentication",
+		},
+		{
+			name: "wrapped server error",
+			err:  helpers.ErrInternalServer("Failed to list roles").Wrap(errors.New("dial tcp 10.0.0.5:5432: connection refused")),
+			want: helpers.ProblemDetails{
+				Type: "https://docs.example.com/errors/internal-error", Title: "Internal Server Error", Status: http.StatusInternalServerError,
+				Detail: "Failed to list roles", Instance: "/fail", Code: helpers.CodeInternal,
+			},
+			hidden: "10.0.0.5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, logs := newErrorServer(t, tt.err)
+			req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+			req.Header.Set(echo.HeaderAccept, helpers.MIMEProblemJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want.Status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want.Status)
+			}
+			if got := rec.Header().Get(echo.HeaderContentType); got != helpers.MIMEProblemJSON {
+				t.Errorf("Content-Type = %q, want %q", got, helpers.MIMEProblemJSON)
+			}
+			var problem helpers.ProblemDetails
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(problem, tt.want) {
+				t.Errorf("problem = %+v, want %+v", problem, tt.want)
+			}
+			if tt.hidden != "" && strings.Contains(rec.Body.String(), tt.hidden) {
+				t.Errorf("body %s exposes %q", rec.Body, tt.hidden)
+			}
+
+			// Only server errors are logged, with their cause
+			serverError := tt.want.Status >= http.StatusInternalServerError
+			if logged := logs.Len() > 0; logged != serverError {
+				t.Errorf("logged = %v, want %v", logged, serverError)
+			}
+			if serverError && !strings.Contains(logs.String(), tt.hidden) {
+				t.Errorf("log %s is missing the cause %q", logs, tt.hidden)
+			}
+		})
+	}
+}
+
+func TestErrorHandlerJSONFormat(t *testing.T) {
+	e, _ := newErrorServer(t, &constants.ConflictError{Fields: []string{"username"}})
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	if rec.Code != http.StatusConflict || !strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		t.Fatalf("response = %d %q, want a 409 JSON body", rec.Code, rec.Header().Get(echo.HeaderContentType))
+	}
+	var body helpers.BaseResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != http.StatusConflict || body.ErrorCode != helpers.CodeUserUsernameTaken || body.Message != "Username already taken" {
+		t.Errorf("body = %+v, want USER_USERNAME_TAKEN", body)
+	}
+	if body.Errors["username"] != "already in use" {
+		t.Errorf("errors = %v, want username already in use", body.Errors)
+	}
+
+	// The configured format applies without an Accept header
+	helpers.Config.HTTP.ErrorFormat = "problem"
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	if got := rec.Header().Get(echo.HeaderContentType); got != helpers.MIMEProblemJSON {
+		t.Errorf("Content-Type with error_format problem = %q, want %q", got, helpers.MIMEProblemJSON)
+	}
+}
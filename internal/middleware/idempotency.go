@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/pii"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// IdempotencyKeyHeader makes a POST safe to retry
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed from a previous request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// Idempotency replays the stored response to a POST retried with the same
+// Idempotency-Key and body. Keys are scoped to the tenant, the path and the
+// Authorization header. Server errors are not stored so the retry runs again.
+//
+// It is added to the JSON POST routes that need it rather than to a group:
+// the body is buffered to hash it, and other bodies such as multipart
+// uploads are passed through without a key.
+func Idempotency(idempotencyService interfaces.IIdempotencyService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			req := c.Request()
+
+			keyValue := req.Header.Get(IdempotencyKeyHeader)
+			if req.Method != http.MethodPost || keyValue == "" ||
+				!strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+				return next(c)
+			}
+			if len(keyValue) > maxIdempotencyKeyLength {
+				return helpers.ErrBadRequestf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength)
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return err
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			key := &models.IdempotencyKey{
+				Key:         keyValue,
+				Scope:       req.Method + " " + req.URL.Path,
+				RequestHash: hashHex(body),
+			}
+			if tenant := helpers.TenantFromContext(req.Context()); tenant != nil {
+				key.TenantID = tenant.ID
+			}
+			if authorization := req.Header.Get(echo.HeaderAuthorization); authorization != "" {
+				key.Principal = hashHex([]byte(authorization))
+			}
+
+			stored, err := idempotencyService.Begin(req.Context(), key)
+			if err != nil {
+				return err
+			}
+			if stored != nil {
+				c.Response().Header().Set(IdempotentReplayedHeader, "true")
+				if stored.ContentType == "" {
+					return c.NoContent(stored.StatusCode)
+				}
+				return c.Blob(stored.StatusCode, stored.ContentType, []byte(stored.ResponseBody))
+			}
+
+			res := c.Response()
+			recorder := &responseRecorder{ResponseWriter: res.Writer}
+			res.Writer = recorder
+
+			// The request context may be cancelled once the response is written
+			ctx := context.WithoutCancel(req.Context())
+			release := func() {
+				if err := idempotencyService.Release(ctx, key); err != nil {
+					helpers.Log(ctx).WithError(err).Error("Failed to release idempotency key")
+				}
+			}
+
+			// A panicking handler must not leave the key in progress until the
+			// lock times out
+			defer func() {
+				res.Writer = recorder.ResponseWriter
+				if r := recover(); r != nil {
+					release()
+					panic(r)
+				}
+			}()
+
+			// Write the error response here so it is stored as well
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			if res.Status >= http.StatusInternalServerError {
+				release()
+				return nil
+			}
+
+			key.StatusCode = res.Status
+			key.ContentType = res.Header().Get(echo.HeaderContentType)
+			key.ResponseBody = pii.EncryptedString(recorder.body.String())
+			if err := idempotencyService.Complete(ctx, key); err != nil {
+				helpers.Log(ctx).WithError(err).Error("Failed to store idempotent response")
+			}
+			return nil
+		}
+	}
+}
+
+// responseRecorder copies the response body as it is written
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func hashHex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
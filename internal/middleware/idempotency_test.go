@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/services"
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeIdempotencyRepo keeps keys in memory with the semantics of
+// repository.IdempotencyRepository
+type fakeIdempotencyRepo struct {
+	mu     sync.Mutex
+	nextID int
+	keys   map[string]*models.IdempotencyKey
+}
+
+func newFakeIdempotencyRepo() *fakeIdempotencyRepo {
+	return &fakeIdempotencyRepo{keys: map[string]*models.IdempotencyKey{}}
+}
+
+func (r *fakeIdempotencyRepo) Reserve(_ context.Context, key *models.IdempotencyKey, staleBefore time.Time) (bool, *models.IdempotencyKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := fmt.Sprint(key.TenantID, "\xff", key.Scope, "\xff", key.Principal, "\xff", key.Key)
+	if stored, ok := r.keys[id]; ok {
+		if !stored.ExpiresAt.After(time.Now()) || !stored.Completed() && !stored.CreatedAt.After(staleBefore) {
+			delete(r.keys, id)
+		} else {
+			existing := *stored
+			return false, &existing, nil
+		}
+	}
+
+	r.nextID++
+	key.ID = r.nextID
+	key.CreatedAt = time.Now()
+	stored := *key
+	r.keys[id] = &stored
+	return true, nil, nil
+}
+
+func (r *fakeIdempotencyRepo) Complete(_ context.Context, key *models.IdempotencyKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, stored := range r.keys {
+		if stored.ID == key.ID {
+			stored.StatusCode = key.StatusCode
+			stored.ContentType = key.ContentType
+			stored.ResponseBody = key.ResponseBody
+			stored.CompletedAt = key.CompletedAt
+		}
+	}
+	return nil
+}
+
+func (r *fakeIdempotencyRepo) Delete(_ context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, stored := range r.keys {
+		if stored.ID == id {
+			delete(r.keys, k)
+		}
+	}
+	return nil
+}
+
+func (r *fakeIdempotencyRepo) DeleteExpired(_ context.Context, now time.Time) (int, error) {
+	return 0, nil
+}
+
+func (r *fakeIdempotencyRepo) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.keys)
+}
+
+// newIdempotentServer serves handler on POST /register behind Idempotency
+func newIdempotentServer(t *testing.T, handler echo.HandlerFunc) (*echo.Echo, *fakeIdempotencyRepo) {
+	t.Helper()
+	previous := helpers.Logger
+	t.Cleanup(func() { helpers.Logger = previous })
+	helpers.Logger = logrus.New()
+	helpers.Logger.SetOutput(io.Discard)
+
+	repo := newFakeIdempotencyRepo()
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler
+	e.Use(echomiddleware.Recover())
+	e.POST("/register", handler, Idempotency(services.NewIdempotencyService(repo, time.Hour, time.Minute)))
+	return e, repo
+}
+
+func postJSON(e *echo.Echo, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(IdempotencyKeyHeader, key)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	calls := 0
+	e, _ := newIdempotentServer(t, func(c echo.Context) error {
+		calls++
+		return c.JSON(http.StatusCreated, map[string]int{"call": calls})
+	})
+
+	first := postJSON(e, "key-1", `{"username":"budi"}`)
+	retry := postJSON(e, "key-1", `{"username":"budi"}`)
+
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry = %d %q, want %d %q", retry.Code, retry.Body, first.Code, first.Body)
+	}
+	if first.Header().Get(IdempotentReplayedHeader) != "" || retry.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("%s header not only set on the replay", IdempotentReplayedHeader)
+	}
+
+	if other := postJSON(e, "key-2", `{"username":"budi"}`); other.Code != http.StatusCreated || calls != 2 {
+		t.Errorf("request with another key = %d after %d calls, want it to run", other.Code, calls)
+	}
+}
+
+func TestIdempotencyReplaysClientErrors(t *testing.T) {
+	calls := 0
+	e, _ := newIdempotentServer(t, func(c echo.Context) error {
+		calls++
+		return helpers.ErrBadRequest("Invalid request")
+	})
+
+	postJSON(e, "key-1", `{}`)
+	if retry := postJSON(e, "key-1", `{}`); retry.Code != http.StatusBadRequest || calls != 1 {
+		t.Errorf("retry = %d after %d calls, want the stored 400", retry.Code, calls)
+	}
+}
+
+func TestIdempotencyKeyReused(t *testing.T) {
+	calls := 0
+	e, _ := newIdempotentServer(t, func(c echo.Context) error {
+		calls++
+		return c.NoContent(http.StatusCreated)
+	})
+
+	postJSON(e, "key-1", `{"username":"budi"}`)
+	rec := postJSON(e, "key-1", `{"username":"andi"}`)
+
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), string(helpers.CodeIdempotencyKeyReused)) {
+		t.Errorf("reused key = %d %s, want 422 %s", rec.Code, rec.Body, helpers.CodeIdempotencyKeyReused)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+}
+
+func TestIdempotencyKeyInProgress(t *testing.T) {
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	e, _ := newIdempotentServer(t, func(c echo.Context) error {
+		close(started)
+		<-finish
+		return c.NoContent(http.StatusCreated)
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postJSON(e, "key-1", `{}`) }()
+	<-started
+
+	rec := postJSON(e, "key-1", `{}`)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), string(helpers.CodeIdempotencyKeyInProgress)) {
+		t.Errorf("concurrent retry = %d %s, want 409 %s", rec.Code, rec.Body, helpers.CodeIdempotencyKeyInProgress)
+	}
+
+	close(finish)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Errorf("first request = %d, want 201", first.Code)
+	}
+}
+
+func TestIdempotencyReleasesKeyOnFailure(t *testing.T) {
+	tests := map[string]func() error{
+		"server error": func() error { return helpers.ErrInternalServer("Internal server error") },
+		"panic":        func() error { panic("handler bug") },
+	}
+	for name, fail := range tests {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			e, repo := newIdempotentServer(t, func(c echo.Context) error {
+				calls++
+				if calls == 1 {
+					return fail()
+				}
+				return c.NoContent(http.StatusCreated)
+			})
+
+			if rec := postJSON(e, "key-1", `{}`); rec.Code != http.StatusInternalServerError {
+				t.Fatalf("first request = %d, want 500", rec.Code)
+			}
+			if repo.count() != 0 {
+				t.Fatal("key still stored after the request failed")
+			}
+			if rec := postJSON(e, "key-1", `{}`); rec.Code != http.StatusCreated || calls != 2 {
+				t.Errorf("retry = %d after %d calls, want it to run again", rec.Code, calls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyPassesThroughOtherBodies(t *testing.T) {
+	var body io.ReadCloser
+	e, repo := newIdempotentServer(t, func(c echo.Context) error {
+		body = c.Request().Body
+		return c.NoContent(http.StatusCreated)
+	})
+
+	for range 2 {
+		req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader("--x--\r\n"))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEMultipartForm+"; boundary=x")
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated || rec.Header().Get(IdempotentReplayedHeader) != "" {
+			t.Errorf("multipart request = %d, want it to run without a key", rec.Code)
+		}
+		if body != req.Body {
+			t.Error("multipart body was buffered")
+		}
+	}
+	if repo.count() != 0 {
+		t.Error("key stored for a multipart request")
+	}
+}
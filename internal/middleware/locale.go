@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
+	"github.com/labstack/echo/v4"
+)
+
+// Locale picks the language of the response from Accept-Language, else the
+// default locale. JWTMiddleware then falls back to the user's saved
+// preference.
+func Locale() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			locale, explicit := i18n.FromAcceptLanguage(req.Header.Get("Accept-Language"))
+			if !explicit {
+				locale = i18n.Default()
+			}
+			setLocale(c, locale, explicit)
+			c.Response().Header().Add(echo.HeaderVary, "Accept-Language")
+
+			return next(c)
+		}
+	}
+}
+
+func setLocale(c echo.Context, locale i18n.Locale, explicit bool) {
+	req := c.Request()
+	c.SetRequest(req.WithContext(i18n.WithLocale(req.Context(), locale, explicit)))
+	c.Response().Header().Set("Content-Language", string(locale))
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/metrics"
+	"github.com/labstack/echo/v4"
+)
+
+// knownMethods bounds the method label, clients can send any token
+var knownMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true,
+	http.MethodPatch: true, http.MethodDelete: true, http.MethodOptions: true,
+}
+
+// Metrics counts requests and records their latency by route template, e.g.
+// /api/v1/admin/users/:id/roles, so raw IDs never become label values
+func Metrics() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			// Write the error response here so the status code is known
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			method := c.Request().Method
+			if !knownMethods[method] {
+				method = "OTHER"
+			}
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			status := strconv.Itoa(c.Response().Status)
+
+			metrics.HTTPRequests.WithLabelValues(method, route, status).Inc()
+			metrics.HTTPRequestDuration.WithLabelValues(method, route, status).Observe(metrics.Since(start))
+			return nil
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/labstack/echo/v4"
+)
+
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-Id"
+
+// validRequestID limits inbound IDs, they end up in every log line
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID keeps the X-Request-Id of a gateway or generates one, returns it
+// in the response and adds it to the request logger, see helpers.Log
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			id := req.Header.Get(RequestIDHeader)
+			if !validRequestID.MatchString(id) {
+				id = newRequestID()
+			}
+			c.Set("request_id", id)
+			c.Response().Header().Set(RequestIDHeader, id)
+
+			ctx := req.Context()
+			ctx = helpers.WithLogger(ctx, helpers.Logger.WithField("request_id", id))
+			c.SetRequest(req.WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// addLogFields adds fields to the request logger for the rest of the request
+func addLogFields(c echo.Context, fields map[string]interface{}) {
+	ctx := c.Request().Context()
+	ctx = helpers.WithLogger(ctx, helpers.Log(ctx).WithFields(fields))
+	c.SetRequest(c.Request().WithContext(ctx))
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/constants"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/labstack/echo/v4"
+)
+
+// TenantMiddleware resolves the tenant from the X-Tenant-ID header or host
+// and stores it in the request context
+func TenantMiddleware(tenantService interfaces.ITenantService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			tenant, err := tenantService.Resolve(req.Context(), req.Header.Get(constants.TenantHeader), req.Host)
+			if err != nil {
+				return err
+			}
+
+			c.SetRequest(req.WithContext(helpers.WithTenant(req.Context(), tenant)))
+			c.Set("tenant", tenant)
+			if tenant != nil {
+				addLogFields(c, map[string]interface{}{"tenant": tenant.Slug})
+			}
+
+			return next(c)
+		}
+	}
+}
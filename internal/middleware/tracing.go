@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+)
+
+// TraceIDHeader returns the trace ID so clients can quote it in bug reports
+const TraceIDHeader = "X-Trace-Id"
+
+// Tracing starts a server span per request with otelecho, continuing the
+// trace of the caller when the request has a W3C traceparent header, and
+// returns the trace ID in TraceIDHeader
+func Tracing(serviceName string) echo.MiddlewareFunc {
+	otelMiddleware := otelecho.Middleware(serviceName)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return otelMiddleware(func(c echo.Context) error {
+			if traceID := helpers.TraceID(c.Request().Context()); traceID != "" {
+				c.Response().Header().Set(TraceIDHeader, traceID)
+			}
+			return next(c)
+		})
+	}
+}
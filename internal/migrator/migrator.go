@@ -0,0 +1,366 @@
+// Package migrator applies the versioned SQL migrations embedded in the
+// migrations package and records them in the schema_migrations table.
+package migrator
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// lockKey is the Postgres advisory lock held while migrating, so replicas
+// starting at the same time apply each migration once
+const lockKey = 72616760
+
+// legacyMigrations are the migrations a database created before versioned
+// migrations existed may already have: the baseline server created users and
+// user_sessions with GORM AutoMigrate and `make migrate` applied the auth
+// fields with psql. Each is recorded as applied only when its objects exist,
+// everything after runs normally.
+var legacyMigrations = []struct {
+	Version int
+	Applied func(schema gorm.Migrator) bool
+}{
+	{Version: 0, Applied: func(schema gorm.Migrator) bool {
+		return schema.HasTable("users") && schema.HasTable("user_sessions")
+	}},
+	{Version: 1, Applied: func(schema gorm.Migrator) bool {
+		for _, column := range []string{"reset_password_token", "reset_password_expiry", "email_verification_token", "email_verified", "is_active"} {
+			if !schema.HasColumn("users", column) {
+				return false
+			}
+		}
+		return true
+	}},
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+var ErrIrreversible = errors.New("migration cannot be reverted")
+
+// Migration is one version of the schema
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	// Down is empty for irreversible migrations
+	Down string
+}
+
+// Status is a migration and when it was applied, nil while pending
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type appliedMigration struct {
+	Version   int       `gorm:"column:version;primaryKey"`
+	Name      string    `gorm:"column:name"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+func (*appliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	logger     *logrus.Logger
+}
+
+func New(db *gorm.DB, fsys fs.FS, logger *logrus.Logger) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations, logger: logger}, nil
+}
+
+// Load reads NNN_name.up.sql and NNN_name.down.sql files, ordered by version
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		m := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || m == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(m[1])
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = migration
+		} else if migration.Name != m[2] {
+			return nil, fmt.Errorf("migration %03d has two names: %s and %s", version, migration.Name, m[2])
+		}
+
+		if m[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %03d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Status lists every known migration with the time it was applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(m.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if a, ok := applied[migration.Version]; ok {
+			appliedAt := a.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending returns the migrations that have not been applied yet
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(m.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies up to steps pending migrations, all of them when steps <= 0.
+// Each migration runs in its own transaction.
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		if err := m.adoptLegacySchema(conn); err != nil {
+			return err
+		}
+
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if steps > 0 && len(done) == steps {
+				break
+			}
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			m.logger.Infof("Applying migration %03d_%s", migration.Version, migration.Name)
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Up).Error; err != nil {
+					return err
+				}
+				return tx.Create(&appliedMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %03d_%s: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the last steps applied migrations, newest first
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if strings.TrimSpace(migration.Down) == "" {
+				return fmt.Errorf("migration %03d_%s: %w", migration.Version, migration.Name, ErrIrreversible)
+			}
+
+			m.logger.Infof("Reverting migration %03d_%s", migration.Version, migration.Name)
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Down).Error; err != nil {
+					return err
+				}
+				return tx.Delete(&appliedMigration{}, "version = ?", migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %03d_%s: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory
+// lock. The lock belongs to the session, so when it cannot be released the
+// connection is closed instead of going back to the pool still holding it.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return err
+	}
+	sqlConn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer sqlConn.Close()
+
+	conn := m.db.WithContext(ctx)
+	conn.Statement.ConnPool = sqlConn
+
+	if err := conn.Exec("SELECT pg_advisory_lock(?)", lockKey).Error; err != nil {
+		return err
+	}
+	defer func() {
+		// Release the lock even when ctx was canceled mid migration
+		_, err := sqlConn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", lockKey)
+		if err != nil {
+			m.logger.WithError(err).Error("Failed to release the migration lock, closing the connection")
+			_ = sqlConn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+
+	err = conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`).Error
+	if err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// adoptLegacySchema records the legacy migrations whose objects exist as
+// applied when the database already has a users table but no migration
+// history
+func (m *Migrator) adoptLegacySchema(conn *gorm.DB) error {
+	var count int64
+	if err := conn.Model(&appliedMigration{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 || !conn.Migrator().HasTable("users") {
+		return nil
+	}
+
+	byVersion := map[int]Migration{}
+	for _, migration := range m.migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	return conn.Transaction(func(tx *gorm.DB) error {
+		for _, legacy := range legacyMigrations {
+			migration, ok := byVersion[legacy.Version]
+			if !ok || !legacy.Applied(tx.Migrator()) {
+				break
+			}
+			m.logger.Warnf("Existing schema without migration history, recording %03d_%s as applied", migration.Version, migration.Name)
+			if err := tx.Create(&appliedMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (m *Migrator) applied(db *gorm.DB) (map[int]appliedMigration, error) {
+	applied := map[int]appliedMigration{}
+	if !db.Migrator().HasTable(&appliedMigration{}) {
+		return applied, nil
+	}
+
+	var rows []appliedMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// Create writes empty up and down files for the next version into dir and
+// returns their paths
+func Create(dir, name string) ([]string, error) {
+	name = strings.Trim(strings.ToLower(regexp.MustCompile(`[^A-Za-z0-9]+`).ReplaceAllString(name, "_")), "_")
+	if name == "" {
+		return nil, errors.New("migration name is required")
+	}
+
+	migrations, err := Load(os.DirFS(dir))
+	if err != nil {
+		return nil, err
+	}
+	version := 0
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+
+	title := strings.ReplaceAll(name, "_", " ")
+	files := map[string]string{
+		"up":   fmt.Sprintf("-- Migration: %s\n-- Created: %s\n\n", strings.ToUpper(title[:1])+title[1:], time.Now().Format("2006-01-02")),
+		"down": "",
+	}
+
+	var paths []string
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%03d_%s.%s.sql", version, name, direction))
+		if err := os.WriteFile(path, []byte(files[direction]), 0o644); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
@@ -0,0 +1,231 @@
+package migrator
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/testdb"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/migrations"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"001_add_b.up.sql":      {Data: []byte("B")},
+		"000_create_a.up.sql":   {Data: []byte("A")},
+		"000_create_a.down.sql": {Data: []byte("drop A")},
+		"README.md":             {Data: []byte("ignored")},
+	}
+
+	got, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d migrations, want 2", len(got))
+	}
+	if got[0].Version != 0 || got[0].Name != "create_a" || got[0].Up != "A" || got[0].Down != "drop A" {
+		t.Errorf("first migration = %+v", got[0])
+	}
+	if got[1].Version != 1 || got[1].Down != "" {
+		t.Errorf("second migration = %+v", got[1])
+	}
+}
+
+func TestLoadRejectsInconsistentFiles(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"two names": {
+			"000_create_a.up.sql":   {Data: []byte("A")},
+			"000_create_b.down.sql": {Data: []byte("B")},
+		},
+		"no up file": {
+			"000_create_a.down.sql": {Data: []byte("A")},
+		},
+	}
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load(fsys); err == nil {
+				t.Error("Load succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	got, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, migration := range got {
+		if migration.Version != i {
+			t.Fatalf("migration %03d_%s follows version %03d, versions must have no gaps", migration.Version, migration.Name, i-1)
+		}
+	}
+}
+
+func newTestMigrator(t *testing.T) (*Migrator, context.Context) {
+	db := testdb.Open(t)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	m, err := New(db, migrations.FS, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, context.Background()
+}
+
+func TestUpAndDown(t *testing.T) {
+	m, ctx := newTestMigrator(t)
+
+	done, err := m.Up(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != len(m.migrations) {
+		t.Fatalf("applied %d migrations, want %d", len(done), len(m.migrations))
+	}
+	if pending, err := m.Pending(ctx); err != nil || len(pending) != 0 {
+		t.Fatalf("Pending() = %v, %v, want none", pending, err)
+	}
+
+	last := m.migrations[len(m.migrations)-1]
+	reverted, err := m.Down(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reverted) != 1 || reverted[0].Version != last.Version {
+		t.Fatalf("Down(1) reverted %v, want %03d", reverted, last.Version)
+	}
+	if pending, _ := m.Pending(ctx); len(pending) != 1 || pending[0].Version != last.Version {
+		t.Fatalf("Pending() = %v after Down(1)", pending)
+	}
+
+	if done, err := m.Up(ctx, 0); err != nil || len(done) != 1 {
+		t.Fatalf("Up() = %v, %v, want the reverted migration again", done, err)
+	}
+}
+
+func TestDownStopsAtIrreversibleMigration(t *testing.T) {
+	m, ctx := newTestMigrator(t)
+	if _, err := m.Up(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := m.Down(ctx, len(m.migrations))
+	if err == nil || !strings.Contains(err.Error(), ErrIrreversible.Error()) {
+		t.Fatalf("Down() error = %v, want %v", err, ErrIrreversible)
+	}
+}
+
+func TestLockReleasedWhenCanceled(t *testing.T) {
+	m, ctx := newTestMigrator(t)
+
+	ctx, cancel := context.WithCancel(ctx)
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		cancel()
+		return ctx.Err()
+	})
+	if err == nil {
+		t.Fatal("withLock() succeeded, want the cancellation")
+	}
+
+	var held int64
+	if err := m.db.Raw("SELECT COUNT(*) FROM pg_locks WHERE locktype = 'advisory' AND objid = ?", lockKey).Scan(&held).Error; err != nil {
+		t.Fatal(err)
+	}
+	if held != 0 {
+		t.Error("migration lock still held after the context was canceled")
+	}
+}
+
+// baselineSchema is what the baseline server created with AutoMigrate
+const baselineSchema = `
+CREATE TABLE users (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR(20) NOT NULL,
+    email VARCHAR(100) NOT NULL,
+    phone_number VARCHAR(15) NOT NULL,
+    full_name VARCHAR(100) NOT NULL,
+    address TEXT,
+    dob DATE,
+    password VARCHAR(255) NOT NULL,
+    role VARCHAR(10) NOT NULL DEFAULT 'user',
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX ux_users_username ON users(username);
+CREATE UNIQUE INDEX ux_users_email ON users(email);
+CREATE UNIQUE INDEX ux_users_phone ON users(phone_number);
+CREATE TABLE user_sessions (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    user_id INT,
+    token TEXT,
+    refresh_token TEXT,
+    token_expired TIMESTAMPTZ,
+    refresh_token_expired TIMESTAMPTZ
+);
+INSERT INTO users (username, email, phone_number, full_name, password, role)
+VALUES ('budi', 'budi@example.com', '081234567890', 'Budi', 'x', 'user');
+`
+
+func TestUpAdoptsLegacySchema(t *testing.T) {
+	tests := []struct {
+		name       string
+		authFields bool
+		adopted    []int
+	}{
+		{name: "baseline", adopted: []int{0}},
+		{name: "baseline with auth fields", authFields: true, adopted: []int{0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, ctx := newTestMigrator(t)
+			if err := m.db.Exec(baselineSchema).Error; err != nil {
+				t.Fatal(err)
+			}
+			if tt.authFields {
+				if err := m.db.Exec(m.migrations[1].Up).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// The plaintext phone index stays until `keys rotate` filled the blind index
+			done, err := m.Up(ctx, 0)
+			if err == nil || !strings.Contains(err.Error(), "keys rotate") {
+				t.Fatalf("Up() error = %v, want a hint to run keys rotate", err)
+			}
+			if done[0].Version != len(tt.adopted) {
+				t.Errorf("first migration run is %03d, want %03d", done[0].Version, len(tt.adopted))
+			}
+			if err := m.db.Exec("UPDATE users SET phone_number_index = md5(phone_number)").Error; err != nil {
+				t.Fatal(err)
+			}
+			rest, err := m.Up(ctx, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := len(m.migrations) - len(tt.adopted); len(done)+len(rest) != want {
+				t.Fatalf("ran %d migrations, want %d", len(done)+len(rest), want)
+			}
+
+			// Tables of later migrations exist and the legacy user was carried over
+			for _, table := range []string{"roles", "tenants", "seller_applications", "consents", "idempotency_keys"} {
+				if !m.db.Migrator().HasTable(table) {
+					t.Errorf("table %s is missing", table)
+				}
+			}
+			var roles int64
+			m.db.Table("user_roles").Count(&roles)
+			if roles != 1 {
+				t.Errorf("legacy user has %d roles, want 1", roles)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+type Address struct {
+	ID                int       `json:"id" gorm:"primaryKey"`
+	UserID            int       `json:"-" gorm:"column:user_id;not null;index:idx_user_addresses_user_id"`
+	Label             string    `json:"label" gorm:"column:label;type:varchar(50);not null"`
+	RecipientName     string    `json:"recipient_name" gorm:"column:recipient_name;type:varchar(100);not null"`
+	PhoneNumber       string    `json:"phone_number" gorm:"column:phone_number;type:varchar(20);not null"`
+	AddressLine       string    `json:"address_line" gorm:"column:address_line;type:text;not null"`
+	Province          string    `json:"province" gorm:"column:province;type:varchar(100)"`
+	City              string    `json:"city" gorm:"column:city;type:varchar(100)"`
+	District          string    `json:"district" gorm:"column:district;type:varchar(100)"`
+	PostalCode        string    `json:"postal_code" gorm:"column:postal_code;type:varchar(10)"`
+	Latitude          *float64  `json:"latitude,omitempty" gorm:"column:latitude"`
+	Longitude         *float64  `json:"longitude,omitempty" gorm:"column:longitude"`
+	IsDefaultShipping bool      `json:"is_default_shipping" gorm:"column:is_default_shipping;default:false"`
+	IsDefaultBilling  bool      `json:"is_default_billing" gorm:"column:is_default_billing;default:false"`
+	CreatedAt         time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (*Address) TableName() string {
+	return "user_addresses"
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/pii"
+)
+
+// LegalDocument is a published version of a legal document such as the terms
+// of service. The newest published version of each type is the current one.
+type LegalDocument struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	TenantID    int       `json:"tenant_id" gorm:"column:tenant_id;not null;uniqueIndex:ux_legal_documents_version,priority:1"`
+	Type        string    `json:"type" gorm:"column:type;type:varchar(30);not null;uniqueIndex:ux_legal_documents_version,priority:2"`
+	Version     string    `json:"version" gorm:"column:version;type:varchar(20);not null;uniqueIndex:ux_legal_documents_version,priority:3"`
+	Title       string    `json:"title" gorm:"column:title;type:varchar(150);not null"`
+	URL         string    `json:"url" gorm:"column:url;type:varchar(255);not null"`
+	PublishedAt time.Time `json:"published_at" gorm:"column:published_at;not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (*LegalDocument) TableName() string {
+	return "legal_documents"
+}
+
+// Consent records that a user accepted a legal document or granted or
+// withdrew an optional consent. Records are append-only; the latest record
+// of a type is the user's current choice.
+type Consent struct {
+	ID              int       `json:"id" gorm:"primaryKey"`
+	TenantID        int       `json:"tenant_id" gorm:"column:tenant_id;not null"`
+	UserID          int       `json:"user_id" gorm:"column:user_id;not null;index:idx_consents_user_type,priority:1"`
+	Type            string    `json:"type" gorm:"column:type;type:varchar(30);not null;index:idx_consents_user_type,priority:2"`
+	DocumentID      *int      `json:"document_id,omitempty" gorm:"column:document_id"`
+	DocumentVersion string    `json:"document_version,omitempty" gorm:"column:document_version;type:varchar(20)"`
+	Granted         bool      `json:"granted" gorm:"column:granted;not null"`
+	IPAddress       string    `json:"ip_address" gorm:"column:ip_address;type:varchar(45)"`
+	UserAgent       string    `json:"user_agent" gorm:"column:user_agent;type:varchar(255)"`
+	CreatedAt       time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime;index:idx_consents_created_at"`
+}
+
+func (*Consent) TableName() string {
+	return "consents"
+}
+
+// MarketingConsent is the current marketing choice of a user, for CRM sync
+type MarketingConsent struct {
+	UserID      int                 `json:"user_id"`
+	Email       string              `json:"email"`
+	PhoneNumber pii.EncryptedString `json:"phone_number"`
+	FullName    string              `json:"full_name"`
+	Type        string              `json:"type"`
+	Granted     bool                `json:"granted"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
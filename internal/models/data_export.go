@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+const (
+	DataExportPending    = "pending"
+	DataExportProcessing = "processing"
+	DataExportReady      = "ready"
+	DataExportFailed     = "failed"
+	DataExportExpired    = "expired"
+)
+
+type DataExport struct {
+	ID          int        `json:"id" gorm:"primaryKey"`
+	TenantID    int        `json:"tenant_id" gorm:"column:tenant_id;not null"`
+	UserID      int        `json:"user_id" gorm:"column:user_id;not null;index:idx_data_exports_user_id"`
+	Status      string     `json:"status" gorm:"column:status;type:varchar(20);not null;default:'pending';index:idx_data_exports_status"`
+	BlobKey     string     `json:"-" gorm:"column:blob_key;type:varchar(255)"`
+	Size        int64      `json:"size,omitempty" gorm:"column:size"`
+	Error       string     `json:"-" gorm:"column:error;type:text"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" gorm:"column:completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" gorm:"column:expires_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (*DataExport) TableName() string {
+	return "data_exports"
+}
+
+// UserData is everything this service stores about a user, as included in a
+// data export
+type UserData struct {
+	User               User
+	Roles              []Role
+	Addresses          []Address
+	Sessions           []UserSession
+	EmailChanges       []EmailChangeRequest
+	PhoneChanges       []PhoneChangeRequest
+	SellerApplications []SellerApplication
+	Consents           []Consent
+	DataExports        []DataExport
+}
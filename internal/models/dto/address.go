@@ -0,0 +1,54 @@
+package dto
+
+import "time"
+
+// AddressRequest represents create address request
+type AddressRequest struct {
+	Label             string   `json:"label" validate:"required,max=50" example:"Rumah"`
+	RecipientName     string   `json:"recipient_name" validate:"required,min=3,max=100" example:"Budi Santoso"`
+	PhoneNumber       string   `json:"phone_number" validate:"required,phone" example:"081234567890"`
+	AddressLine       string   `json:"address_line" validate:"required,max=500" example:"Jl. Patiunus No. 1"`
+	Province          string   `json:"province" validate:"required,max=100" example:"DKI Jakarta"`
+	City              string   `json:"city" validate:"required,max=100" example:"Jakarta Selatan"`
+	District          string   `json:"district" validate:"required,max=100" example:"Kebayoran Baru"`
+	PostalCode        string   `json:"postal_code" validate:"required,numeric,len=5" example:"12110"`
+	Latitude          *float64 `json:"latitude" validate:"omitempty,latitude" example:"-6.2443"`
+	Longitude         *float64 `json:"longitude" validate:"omitempty,longitude" example:"106.8006"`
+	IsDefaultShipping bool     `json:"is_default_shipping"`
+	IsDefaultBilling  bool     `json:"is_default_billing"`
+}
+
+// UpdateAddressRequest represents partial address update, omitted fields are unchanged
+type UpdateAddressRequest struct {
+	Label             *string  `json:"label" validate:"omitempty,max=50"`
+	RecipientName     *string  `json:"recipient_name" validate:"omitempty,min=3,max=100"`
+	PhoneNumber       *string  `json:"phone_number" validate:"omitempty,phone"`
+	AddressLine       *string  `json:"address_line" validate:"omitempty,max=500"`
+	Province          *string  `json:"province" validate:"omitempty,max=100"`
+	City              *string  `json:"city" validate:"omitempty,max=100"`
+	District          *string  `json:"district" validate:"omitempty,max=100"`
+	PostalCode        *string  `json:"postal_code" validate:"omitempty,numeric,len=5"`
+	Latitude          *float64 `json:"latitude" validate:"omitempty,latitude"`
+	Longitude         *float64 `json:"longitude" validate:"omitempty,longitude"`
+	IsDefaultShipping *bool    `json:"is_default_shipping"`
+	IsDefaultBilling  *bool    `json:"is_default_billing"`
+}
+
+// AddressResponse represents an address book entry
+type AddressResponse struct {
+	ID                int       `json:"id"`
+	Label             string    `json:"label"`
+	RecipientName     string    `json:"recipient_name"`
+	PhoneNumber       string    `json:"phone_number"`
+	AddressLine       string    `json:"address_line"`
+	Province          string    `json:"province"`
+	City              string    `json:"city"`
+	District          string    `json:"district"`
+	PostalCode        string    `json:"postal_code"`
+	Latitude          *float64  `json:"latitude,omitempty"`
+	Longitude         *float64  `json:"longitude,omitempty"`
+	IsDefaultShipping bool      `json:"is_default_shipping"`
+	IsDefaultBilling  bool      `json:"is_default_billing"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
@@ -36,6 +36,11 @@ type AuthResponse struct {
 	AccessToken  string       `json:"access_token"`
 	RefreshToken string       `json:"refresh_token"`
 	ExpiresAt    time.Time    `json:"expires_at"`
+
+	// ReconsentRequired is set on login when a newer terms of service or
+	// privacy policy was published than the user accepted
+	ReconsentRequired bool                    `json:"reconsent_required"`
+	PendingDocuments  []LegalDocumentResponse `json:"pending_documents,omitempty"`
 }
 
 // UserResponse represents user data in response
@@ -48,6 +53,7 @@ type UserResponse struct {
 	Address     string     `json:"address,omitempty"`
 	Dob         *time.Time `json:"dob,omitempty"`
 	Role        string     `json:"role"`
+	Locale      string     `json:"locale,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 }
 
@@ -55,3 +61,45 @@ type UserResponse struct {
 type MessageResponse struct {
 	Message string `json:"message"`
 }
+
+// UpdateProfileRequest represents partial profile update, omitted fields are unchanged
+type UpdateProfileRequest struct {
+	FullName *string `json:"full_name" validate:"omitempty,min=3,max=100" example:"Budi Santoso"`
+	Dob      *string `json:"dob" validate:"omitempty,datetime=2006-01-02" example:"1999-01-01"`
+	Address  *string `json:"address" validate:"omitempty,max=500" example:"Jl Patiunus 1"`
+	// Locale is the language of messages, emails and SMS, empty clears it
+	Locale *string `json:"locale" validate:"omitempty,oneof=id en" example:"id"`
+}
+
+// RequestEmailChangeRequest represents email change request
+type RequestEmailChangeRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email,max=100" example:"new@example.com"`
+	Password string `json:"password" validate:"required" example:"password"`
+}
+
+// EmailChangeTokenRequest represents the token from an email change link
+type EmailChangeTokenRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// RequestPhoneChangeRequest represents phone number change request
+type RequestPhoneChangeRequest struct {
+	NewPhone string `json:"new_phone" validate:"required,phone" example:"081234567890"`
+	Password string `json:"password" validate:"required" example:"password"`
+}
+
+// VerifyPhoneChangeRequest represents the OTP sent to the new phone number
+type VerifyPhoneChangeRequest struct {
+	OTP string `json:"otp" validate:"required,len=6,numeric" example:"123456"`
+}
+
+// DeleteAccountRequest represents account deletion request
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required" example:"password"`
+}
+
+// DeleteAccountResponse tells when the account will be deleted
+type DeleteAccountResponse struct {
+	Message     string    `json:"message"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+// AuthzCheckRequest asks whether the token subject may perform an action on a resource
+type AuthzCheckRequest struct {
+	Action      string                 `json:"action" validate:"required,max=100" example:"stores:update"`
+	Resource    AuthzResource          `json:"resource"`
+	Environment map[string]interface{} `json:"environment,omitempty"`
+}
+
+// AuthzResource describes the resource an action targets
+type AuthzResource struct {
+	Type       string                 `json:"type" validate:"required,max=50" example:"store"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// AuthzCheckResponse represents an authorization decision
+type AuthzCheckResponse struct {
+	Allowed bool   `json:"allowed"`
+	Effect  string `json:"effect"`
+	Rule    string `json:"rule,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
@@ -0,0 +1,50 @@
+package dto
+
+import "time"
+
+// LegalDocumentResponse represents a published legal document version
+type LegalDocumentResponse struct {
+	ID          int       `json:"id"`
+	Type        string    `json:"type"`
+	Version     string    `json:"version"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// PublishLegalDocumentRequest represents a new legal document version
+type PublishLegalDocumentRequest struct {
+	Type    string `json:"type" validate:"required,oneof=terms_of_service privacy_policy" example:"terms_of_service"`
+	Version string `json:"version" validate:"required,max=20" example:"2.0"`
+	Title   string `json:"title" validate:"required,max=150" example:"Syarat dan Ketentuan"`
+	URL     string `json:"url" validate:"required,url,max=255" example:"https://example.com/legal/terms-v2"`
+}
+
+// UpdateConsentRequest accepts legal documents and/or changes marketing
+// consent. Omitted fields are unchanged
+type UpdateConsentRequest struct {
+	TermsVersion   string `json:"terms_version" validate:"omitempty,max=20" example:"2.0"`
+	PrivacyVersion string `json:"privacy_version" validate:"omitempty,max=20" example:"2.0"`
+	MarketingEmail *bool  `json:"marketing_email" example:"true"`
+	MarketingSMS   *bool  `json:"marketing_sms" example:"false"`
+	IPAddress      string `json:"-"`
+	UserAgent      string `json:"-"`
+}
+
+// ConsentStatusResponse represents the user's current consents
+type ConsentStatusResponse struct {
+	ReconsentRequired bool                    `json:"reconsent_required"`
+	PendingDocuments  []LegalDocumentResponse `json:"pending_documents,omitempty"`
+	AcceptedVersions  map[string]string       `json:"accepted_versions"`
+	MarketingEmail    bool                    `json:"marketing_email"`
+	MarketingSMS      bool                    `json:"marketing_sms"`
+}
+
+// MarketingConsentQuery filters marketing consents for CRM sync
+type MarketingConsentQuery struct {
+	Type         string `query:"type" validate:"omitempty,oneof=marketing_email marketing_sms"`
+	Granted      string `query:"granted" validate:"omitempty,oneof=true false"`
+	UpdatedSince string `query:"updated_since" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Page         int    `query:"page" validate:"omitempty,min=1"`
+	Limit        int    `query:"limit" validate:"omitempty,min=1,max=1000"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+// DataExportResponse represents a personal data export
+type DataExportResponse struct {
+	ID          int        `json:"id"`
+	Status      string     `json:"status"`
+	Size        int64      `json:"size,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// DataExportDownloadRequest represents a signed download link
+type DataExportDownloadRequest struct {
+	ID        int    `param:"id" validate:"required"`
+	Expires   int64  `query:"expires" validate:"required"`
+	Signature string `query:"signature" validate:"required,hexadecimal"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+// HealthResponse represents the state of the service and its dependencies
+type HealthResponse struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentHealth `json:"components,omitempty"`
+}
+
+// ComponentHealth represents the result of one dependency check
+type ComponentHealth struct {
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
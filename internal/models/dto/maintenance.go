@@ -0,0 +1,25 @@
+package dto
+
+// CreateAdminRequest represents the bootstrap admin account created from the CLI
+type CreateAdminRequest struct {
+	TenantID    int    `validate:"required,min=1"`
+	Username    string `validate:"required,min=3,max=20,alphanum"`
+	Email       string `validate:"required,email,max=100"`
+	PhoneNumber string `validate:"required,phone"`
+	FullName    string `validate:"required,max=100"`
+	Password    string `validate:"required,min=8"`
+	Role        string `validate:"required,oneof=admin superadmin"`
+}
+
+// KeyRotationResult reports a key rotation run. Failed users are skipped and
+// keep their old encryption until the cause is fixed.
+type KeyRotationResult struct {
+	Rotated int
+	Failed  []KeyRotationFailure
+}
+
+// KeyRotationFailure is a user whose personal data could not be re-encrypted
+type KeyRotationFailure struct {
+	UserID int
+	Err    error
+}
@@ -0,0 +1,44 @@
+package dto
+
+// RoleResponse represents a role with its permissions
+type RoleResponse struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+// PermissionResponse represents a permission
+type PermissionResponse struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// CreatePermissionRequest represents create permission request
+type CreatePermissionRequest struct {
+	Name        string `json:"name" validate:"required,max=60" example:"orders:refund"`
+	Description string `json:"description" validate:"omitempty,max=255" example:"Refund a paid order"`
+}
+
+// SetRolePermissionsRequest replaces every permission of a role
+type SetRolePermissionsRequest struct {
+	Permissions []string `json:"permissions" validate:"required,dive,required,max=60"`
+}
+
+// GrantPermissionRequest adds a single permission to a role
+type GrantPermissionRequest struct {
+	Permission string `json:"permission" validate:"required,max=60" example:"orders:refund"`
+}
+
+// AssignRoleRequest assigns a role to a user
+type AssignRoleRequest struct {
+	Role string `json:"role" validate:"required,max=30" example:"seller"`
+}
+
+// UserAccessResponse represents the roles and effective permissions of a user
+type UserAccessResponse struct {
+	UserID      int      `json:"user_id"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"mime/multipart"
+	"time"
+)
+
+// SellerApplicationRequest represents a seller application form.
+// ID documents are sent as multipart files named "ktp" and "npwp".
+type SellerApplicationRequest struct {
+	StoreName string `form:"store_name" validate:"required,min=3,max=100" example:"Toko Budi"`
+	NIK       string `form:"nik" validate:"required,numeric,len=16" example:"3174091234560001"`
+	NPWP      string `form:"npwp" validate:"required,numeric,min=15,max=16" example:"0912345678901000"`
+}
+
+// DocumentUpload is an uploaded KYC document
+type DocumentUpload struct {
+	Type string
+	File *multipart.FileHeader
+}
+
+// ReviewSellerApplicationRequest represents reviewer decision notes
+type ReviewSellerApplicationRequest struct {
+	Notes string `json:"notes" validate:"omitempty,max=1000" example:"Documents verified"`
+}
+
+// SellerDocumentResponse represents an uploaded document
+type SellerDocumentResponse struct {
+	ID          int       `json:"id"`
+	Type        string    `json:"type"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SellerApplicationResponse represents a seller application.
+// NIK and NPWP are masked except for reviewers.
+type SellerApplicationResponse struct {
+	ID            int                      `json:"id"`
+	UserID        int                      `json:"user_id"`
+	StoreName     string                   `json:"store_name"`
+	NIK           string                   `json:"nik"`
+	NPWP          string                   `json:"npwp"`
+	Status        string                   `json:"status"`
+	ReviewerID    *int                     `json:"reviewer_id,omitempty"`
+	ReviewerNotes string                   `json:"reviewer_notes,omitempty"`
+	ReviewedAt    *time.Time               `json:"reviewed_at,omitempty"`
+	Documents     []SellerDocumentResponse `json:"documents"`
+	CreatedAt     time.Time                `json:"created_at"`
+}
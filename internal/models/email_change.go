@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+const (
+	EmailChangePending   = "pending"
+	EmailChangeConfirmed = "confirmed"
+	EmailChangeCancelled = "cancelled"
+	EmailChangeReverted  = "reverted"
+)
+
+type EmailChangeRequest struct {
+	ID               int        `json:"id" gorm:"primaryKey"`
+	TenantID         int        `json:"tenant_id" gorm:"column:tenant_id;not null"`
+	UserID           int        `json:"user_id" gorm:"column:user_id;not null;index:idx_email_change_requests_user_id"`
+	OldEmail         string     `json:"old_email" gorm:"column:old_email;type:varchar(100);not null"`
+	NewEmail         string     `json:"new_email" gorm:"column:new_email;type:varchar(100);not null"`
+	ConfirmTokenHash string     `json:"-" gorm:"column:confirm_token_hash;type:varchar(64);not null;uniqueIndex:ux_email_change_requests_confirm"`
+	CancelTokenHash  string     `json:"-" gorm:"column:cancel_token_hash;type:varchar(64);not null;uniqueIndex:ux_email_change_requests_cancel"`
+	Status           string     `json:"status" gorm:"column:status;type:varchar(20);not null;default:'pending'"`
+	ExpiresAt        time.Time  `json:"expires_at" gorm:"column:expires_at;not null"`
+	ConfirmedAt      *time.Time `json:"confirmed_at,omitempty" gorm:"column:confirmed_at"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt        time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (*EmailChangeRequest) TableName() string {
+	return "email_change_requests"
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/pii"
+)
+
+// IdempotencyKey is a request sent with an Idempotency-Key header and, once
+// completed, the response replayed to retries
+type IdempotencyKey struct {
+	ID       int    `json:"id" gorm:"primaryKey"`
+	TenantID int    `json:"tenant_id" gorm:"column:tenant_id;not null;default:0;uniqueIndex:ux_idempotency_keys_key,priority:1"`
+	Key      string `json:"key" gorm:"column:key;type:varchar(255);not null;uniqueIndex:ux_idempotency_keys_key,priority:4"`
+	// Scope is the method and path the key was used on
+	Scope string `json:"scope" gorm:"column:scope;type:varchar(512);not null;uniqueIndex:ux_idempotency_keys_key,priority:2"`
+	// Principal is a hash of the Authorization header, empty when absent
+	Principal    string              `json:"-" gorm:"column:principal;type:varchar(64);not null;default:'';uniqueIndex:ux_idempotency_keys_key,priority:3"`
+	RequestHash  string              `json:"-" gorm:"column:request_hash;type:char(64);not null"`
+	StatusCode   int                 `json:"status_code,omitempty" gorm:"column:status_code"`
+	ContentType  string              `json:"-" gorm:"column:content_type;type:varchar(255)"`
+	ResponseBody pii.EncryptedString `json:"-" gorm:"column:response_body;type:text"`
+	CompletedAt  *time.Time          `json:"completed_at,omitempty" gorm:"column:completed_at"`
+	ExpiresAt    time.Time           `json:"expires_at" gorm:"column:expires_at;not null;index:idx_idempotency_keys_expires_at"`
+	CreatedAt    time.Time           `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (*IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+// Completed reports whether the response is stored and can be replayed
+func (k *IdempotencyKey) Completed() bool {
+	return k.CompletedAt != nil
+}
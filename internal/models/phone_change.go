@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const (
+	PhoneChangePending   = "pending"
+	PhoneChangeConfirmed = "confirmed"
+	PhoneChangeCancelled = "cancelled"
+)
+
+type PhoneChangeRequest struct {
+	ID          int        `json:"id" gorm:"primaryKey"`
+	TenantID    int        `json:"tenant_id" gorm:"column:tenant_id;not null"`
+	UserID      int        `json:"user_id" gorm:"column:user_id;not null;index:idx_phone_change_requests_user_id"`
+	OldPhone    string     `json:"old_phone" gorm:"column:old_phone;type:varchar(16);not null"`
+	NewPhone    string     `json:"new_phone" gorm:"column:new_phone;type:varchar(16);not null"`
+	OTPHash     string     `json:"-" gorm:"column:otp_hash;type:varchar(64);not null"`
+	Attempts    int        `json:"attempts" gorm:"column:attempts;not null;default:0"`
+	Status      string     `json:"status" gorm:"column:status;type:varchar(20);not null;default:'pending'"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"column:expires_at;not null"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty" gorm:"column:confirmed_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (*PhoneChangeRequest) TableName() string {
+	return "phone_change_requests"
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+type Role struct {
+	ID          int          `json:"id" gorm:"primaryKey"`
+	Name        string       `json:"name" gorm:"column:name;type:varchar(30);not null;uniqueIndex:ux_roles_name"`
+	Description string       `json:"description" gorm:"column:description;type:varchar(255)"`
+	Permissions []Permission `json:"permissions,omitempty" gorm:"many2many:role_permissions;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time    `json:"-" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time    `json:"-" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (*Role) TableName() string {
+	return "roles"
+}
+
+type Permission struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"column:name;type:varchar(60);not null;uniqueIndex:ux_permissions_name"`
+	Description string    `json:"description" gorm:"column:description;type:varchar(255)"`
+	CreatedAt   time.Time `json:"-" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time `json:"-" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (*Permission) TableName() string {
+	return "permissions"
+}
+
+type UserRole struct {
+	UserID    int       `json:"user_id" gorm:"primaryKey;column:user_id"`
+	RoleID    int       `json:"role_id" gorm:"primaryKey;column:role_id"`
+	CreatedAt time.Time `json:"-" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (*UserRole) TableName() string {
+	return "user_roles"
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+const (
+	SellerApplicationPending  = "pending"
+	SellerApplicationApproved = "approved"
+	SellerApplicationRejected = "rejected"
+)
+
+type SellerApplication struct {
+	ID            int              `json:"id" gorm:"primaryKey"`
+	TenantID      int              `json:"tenant_id" gorm:"column:tenant_id;not null;index:idx_seller_applications_tenant_status,priority:1"`
+	UserID        int              `json:"user_id" gorm:"column:user_id;not null;index:idx_seller_applications_user_id"`
+	StoreName     string           `json:"store_name" gorm:"column:store_name;type:varchar(100);not null"`
+	NIK           string           `json:"-" gorm:"column:nik;type:varchar(16);not null"`
+	NPWP          string           `json:"-" gorm:"column:npwp;type:varchar(16);not null"`
+	Status        string           `json:"status" gorm:"column:status;type:varchar(20);not null;default:'pending';index:idx_seller_applications_tenant_status,priority:2"`
+	ReviewerID    *int             `json:"reviewer_id,omitempty" gorm:"column:reviewer_id"`
+	ReviewerNotes string           `json:"reviewer_notes,omitempty" gorm:"column:reviewer_notes;type:text"`
+	ReviewedAt    *time.Time       `json:"reviewed_at,omitempty" gorm:"column:reviewed_at"`
+	Documents     []SellerDocument `json:"documents,omitempty" gorm:"foreignKey:ApplicationID;constraint:OnDelete:CASCADE"`
+	CreatedAt     time.Time        `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt     time.Time        `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (*SellerApplication) TableName() string {
+	return "seller_applications"
+}
+
+type SellerDocument struct {
+	ID            int       `json:"id" gorm:"primaryKey"`
+	ApplicationID int       `json:"application_id" gorm:"column:application_id;not null;index"`
+	Type          string    `json:"type" gorm:"column:type;type:varchar(20);not null"`
+	BlobKey       string    `json:"-" gorm:"column:blob_key;type:varchar(255);not null"`
+	FileName      string    `json:"file_name" gorm:"column:file_name;type:varchar(255)"`
+	ContentType   string    `json:"content_type" gorm:"column:content_type;type:varchar(100)"`
+	Size          int64     `json:"size" gorm:"column:size"`
+	CreatedAt     time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	// DeletedAt is set when the applicant's account was deleted, the cleanup
+	// worker then removes the file
+	DeletedAt *time.Time `json:"-" gorm:"column:deleted_at"`
+}
+
+func (*SellerDocument) TableName() string {
+	return "seller_documents"
+}
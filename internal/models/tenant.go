@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type Tenant struct {
+	ID                   int       `json:"id" gorm:"primaryKey"`
+	Slug                 string    `json:"slug" gorm:"column:slug;type:varchar(50);not null;uniqueIndex:ux_tenants_slug"`
+	Name                 string    `json:"name" gorm:"column:name;type:varchar(100);not null"`
+	Domain               *string   `json:"domain,omitempty" gorm:"column:domain;type:varchar(255);uniqueIndex:ux_tenants_domain"`
+	JWTSecret            string    `json:"-" gorm:"column:jwt_secret;type:varchar(255)"`
+	PasswordMinLength    int       `json:"password_min_length" gorm:"column:password_min_length;not null;default:8"`
+	PasswordRequireMixed bool      `json:"password_require_mixed" gorm:"column:password_require_mixed;default:false"`
+	IsActive             bool      `json:"is_active" gorm:"column:is_active;default:true"`
+	CreatedAt            time.Time `json:"-" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt            time.Time `json:"-" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (*Tenant) TableName() string {
+	return "tenants"
+}
@@ -4,25 +4,33 @@ import (
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/pii"
 )
 
 type User struct {
-	ID                     int        `json:"-" gorm:"primaryKey"`
-	Username               string     `json:"username" gorm:"column:username;type:varchar(20);not null;uniqueIndex:ux_users_username"`
-	Email                  string     `json:"email" gorm:"column:email;type:varchar(100);not null;uniqueIndex:ux_users_email"`
-	PhoneNumber            string     `json:"phone_number" gorm:"column:phone_number;type:varchar(15);not null;uniqueIndex:ux_users_phone"`
-	FullName               string     `json:"full_name" gorm:"column:full_name;type:varchar(100);not null"`
-	Address                string     `json:"address" gorm:"column:address;type:text"`
-	Dob                    *time.Time `json:"dob,omitempty" gorm:"column:dob;type:date"`
-	Password               string     `json:"-" gorm:"column:password;type:varchar(255);not null"`
-	Role                   string     `json:"role,omitempty" gorm:"column:role;type:varchar(10);not null;default:'user'"`
-	ResetPasswordToken     *string    `json:"-" gorm:"column:reset_password_token;type:varchar(255)"`
-	ResetPasswordExpiry    *time.Time `json:"-" gorm:"column:reset_password_expiry;type:timestamp"`
-	EmailVerificationToken *string    `json:"-" gorm:"column:email_verification_token;type:varchar(255)"`
-	EmailVerified          bool       `json:"email_verified" gorm:"column:email_verified;default:false"`
-	IsActive               bool       `json:"is_active" gorm:"column:is_active;default:true"`
-	CreatedAt              time.Time  `json:"-" gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt              time.Time  `json:"-" gorm:"column:updated_at;autoUpdateTime"`
+	ID                     int                 `json:"-" gorm:"primaryKey"`
+	TenantID               int                 `json:"tenant_id" gorm:"column:tenant_id;not null;default:1;uniqueIndex:ux_users_username,priority:1;uniqueIndex:ux_users_email,priority:1;uniqueIndex:ux_users_phone_number_index,priority:1;uniqueIndex:ux_users_email_canonical,priority:1;uniqueIndex:ux_users_username_canonical,priority:1"`
+	Username               string              `json:"username" gorm:"column:username;type:varchar(20);not null;uniqueIndex:ux_users_username,priority:2"`
+	Email                  string              `json:"email" gorm:"column:email;type:varchar(100);not null;uniqueIndex:ux_users_email,priority:2"`
+	EmailCanonical         string              `json:"-" gorm:"column:email_canonical;type:varchar(100);uniqueIndex:ux_users_email_canonical,priority:2"`
+	UsernameCanonical      string              `json:"-" gorm:"column:username_canonical;type:varchar(80);uniqueIndex:ux_users_username_canonical,priority:2"`
+	PhoneNumber            pii.EncryptedString `json:"phone_number" gorm:"column:phone_number;type:text;not null"`
+	PhoneNumberIndex       string              `json:"-" gorm:"column:phone_number_index;type:varchar(64);uniqueIndex:ux_users_phone_number_index,priority:2"`
+	FullName               string              `json:"full_name" gorm:"column:full_name;type:varchar(100);not null"`
+	Address                pii.EncryptedString `json:"address" gorm:"column:address;type:text"`
+	Dob                    *pii.EncryptedDate  `json:"dob,omitempty" gorm:"column:dob;type:text"`
+	Password               string              `json:"-" gorm:"column:password;type:varchar(255);not null"`
+	Role                   string              `json:"role,omitempty" gorm:"column:role;type:varchar(30);not null;default:'customer'"`
+	Locale                 string              `json:"locale,omitempty" gorm:"column:locale;type:varchar(10)"`
+	ResetPasswordToken     *string             `json:"-" gorm:"column:reset_password_token;type:varchar(255)"`
+	ResetPasswordExpiry    *time.Time          `json:"-" gorm:"column:reset_password_expiry;type:timestamp"`
+	EmailVerificationToken *string             `json:"-" gorm:"column:email_verification_token;type:varchar(255)"`
+	EmailVerified          bool                `json:"email_verified" gorm:"column:email_verified;default:false"`
+	IsActive               bool                `json:"is_active" gorm:"column:is_active;default:true"`
+	DeletionScheduledAt    *time.Time          `json:"deletion_scheduled_at,omitempty" gorm:"column:deletion_scheduled_at;index:idx_users_deletion_scheduled_at"`
+	AnonymizedAt           *time.Time          `json:"-" gorm:"column:anonymized_at"`
+	CreatedAt              time.Time           `json:"-" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt              time.Time           `json:"-" gorm:"column:updated_at;autoUpdateTime"`
 }
 
 func (*User) TableName() string {
@@ -34,11 +42,30 @@ func (l User) Validate() error {
 	return validate.Struct(l)
 }
 
+// IdentityCollision records accounts whose email or username collide with an
+// older account once canonicalized. They keep their exact-match login until
+// support resolves the duplicate.
+type IdentityCollision struct {
+	ID                int        `json:"id" gorm:"primaryKey"`
+	TenantID          int        `json:"tenant_id" gorm:"column:tenant_id;not null"`
+	Field             string     `json:"field" gorm:"column:field;type:varchar(20);not null;uniqueIndex:ux_identity_collisions_user_field,priority:2"`
+	CanonicalValue    string     `json:"canonical_value" gorm:"column:canonical_value;type:varchar(100);not null"`
+	UserID            int        `json:"user_id" gorm:"column:user_id;not null;uniqueIndex:ux_identity_collisions_user_field,priority:1"`
+	ConflictingUserID int        `json:"conflicting_user_id" gorm:"column:conflicting_user_id;not null"`
+	DetectedAt        time.Time  `json:"detected_at" gorm:"column:detected_at;autoCreateTime"`
+	ResolvedAt        *time.Time `json:"resolved_at,omitempty" gorm:"column:resolved_at"`
+}
+
+func (*IdentityCollision) TableName() string {
+	return "identity_collisions"
+}
+
 type UserSession struct {
 	ID                  int `gorm:"primarykey"`
 	CreatedAt           time.Time
 	UpdatedAt           time.Time
-	UserID              int       `json:"user_id" gorm:"type:int" validate:"required"`
+	TenantID            int       `json:"tenant_id" gorm:"type:int;not null;default:1;index:idx_user_sessions_tenant_user,priority:1"`
+	UserID              int       `json:"user_id" gorm:"type:int;index:idx_user_sessions_tenant_user,priority:2" validate:"required"`
 	Token               string    `json:"token" gorm:"type:text" validate:"required"`
 	RefreshToken        string    `json:"refresh_token" gorm:"type:text" validate:"required"`
 	TokenExpired        time.Time `json:"-" validate:"required"`
@@ -0,0 +1,237 @@
+// Package pii encrypts personal data stored in the database. Every value is
+// sealed with its own AES-256-GCM data key, and the data key is wrapped with
+// a master key (envelope encryption). Deterministic blind indexes make
+// encrypted columns searchable by exact value.
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// prefix marks encrypted values. Values without it are legacy plaintext
+// that the key rotation job encrypts.
+const prefix = "enc:v1:"
+
+const keySize = 32
+
+var (
+	ErrNoKeyring     = errors.New("pii: keyring not configured")
+	ErrUnknownKey    = errors.New("pii: unknown master key")
+	ErrMalformed     = errors.New("pii: malformed ciphertext")
+	ErrInvalidKeyLen = errors.New("pii: keys must be 32 bytes")
+)
+
+// Keyring holds the master keys and the blind index key. Old master keys
+// stay in the keyring until rotation re-encrypted every row sealed with them.
+type Keyring struct {
+	keys     map[string]cipher.AEAD
+	activeID string
+	indexKey []byte
+}
+
+// NewKeyring builds a keyring that encrypts with the master key activeID
+func NewKeyring(keys map[string][]byte, activeID string, indexKey []byte) (*Keyring, error) {
+	if _, ok := keys[activeID]; !ok {
+		return nil, fmt.Errorf("%w: active key %q", ErrUnknownKey, activeID)
+	}
+	if len(indexKey) != keySize {
+		return nil, ErrInvalidKeyLen
+	}
+
+	k := &Keyring{keys: make(map[string]cipher.AEAD, len(keys)), activeID: activeID, indexKey: indexKey}
+	for id, key := range keys {
+		if strings.Contains(id, ":") || id == "" {
+			return nil, fmt.Errorf("pii: invalid key id %q", id)
+		}
+		if len(key) != keySize {
+			return nil, ErrInvalidKeyLen
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = aead
+	}
+	return k, nil
+}
+
+// ParseKeys parses master keys written as "id:base64key" separated by commas
+// or newlines, the format of PII_MASTER_KEYS and PII_MASTER_KEY_FILE. It also
+// returns the ids in the order they were listed.
+func ParseKeys(s string) (map[string][]byte, []string, error) {
+	keys := map[string][]byte{}
+	var ids []string
+	for _, entry := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, nil, fmt.Errorf("pii: key entry %q must be id:base64key", entry)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, nil, fmt.Errorf("pii: key %q: %w", id, err)
+		}
+		id = strings.TrimSpace(id)
+		if _, exists := keys[id]; !exists {
+			ids = append(ids, id)
+		}
+		keys[id] = key
+	}
+	return keys, ids, nil
+}
+
+// ActiveKeyID returns the id of the master key new values are sealed with
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeID
+}
+
+// KeyIDs returns the ids of every master key in the keyring
+func (k *Keyring) KeyIDs() []string {
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ActivePrefix is the prefix of values sealed with the active master key,
+// used to find rows that still need rotation
+func (k *Keyring) ActivePrefix() string {
+	return prefix + k.activeID + ":"
+}
+
+// Encrypt seals plaintext with a fresh data key wrapped by the active master key
+func (k *Keyring) Encrypt(plaintext []byte) (string, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+
+	wrapped, err := seal(k.keys[k.activeID], dataKey, []byte(k.activeID))
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(aead, plaintext, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return k.ActivePrefix() +
+		base64.RawURLEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt. Values without the encryption
+// prefix are returned as is.
+func (k *Keyring) Decrypt(value string) ([]byte, error) {
+	if !IsEncrypted(value) {
+		return []byte(value), nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+	master, ok := k.keys[parts[0]]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, parts[0])
+	}
+
+	wrapped, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	dataKey, err := open(master, wrapped, []byte(parts[0]))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return open(aead, sealed, nil)
+}
+
+// BlindIndex returns a deterministic HMAC-SHA256 of value. It does not depend
+// on the master keys, so rotation leaves indexes untouched.
+func (k *Keyring) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsEncrypted reports whether value was produced by Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+var defaultKeyring atomic.Pointer[Keyring]
+
+// SetDefault sets the keyring used by the encrypted column types
+func SetDefault(k *Keyring) {
+	defaultKeyring.Store(k)
+}
+
+// Default returns the keyring set by SetDefault, or nil
+func Default() *Keyring {
+	return defaultKeyring.Load()
+}
+
+// BlindIndex computes a blind index with the default keyring. It panics when
+// no keyring is configured, which is a startup error.
+func BlindIndex(value string) string {
+	k := Default()
+	if k == nil {
+		panic(ErrNoKeyring)
+	}
+	return k.BlindIndex(value)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
@@ -0,0 +1,206 @@
+package pii
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, keySize)
+}
+
+func newTestKeyring(t *testing.T, activeID string, keys map[string][]byte) *Keyring {
+	t.Helper()
+	k, err := NewKeyring(keys, activeID, testKey(9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	k := newTestKeyring(t, "k1", map[string][]byte{"k1": testKey(1)})
+
+	first, err := k.Encrypt([]byte("+6281234567890"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := k.Encrypt([]byte("+6281234567890"))
+	if first == second {
+		t.Error("encrypting the same value twice gave the same ciphertext")
+	}
+	if !strings.HasPrefix(first, k.ActivePrefix()) || !IsEncrypted(first) {
+		t.Errorf("ciphertext %q does not start with %q", first, k.ActivePrefix())
+	}
+
+	got, err := k.Decrypt(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "+6281234567890" {
+		t.Errorf("Decrypt() = %q", got)
+	}
+
+	// Legacy plaintext is returned as is
+	if got, err := k.Decrypt("081234567890"); err != nil || string(got) != "081234567890" {
+		t.Errorf("Decrypt(plaintext) = %q, %v", got, err)
+	}
+}
+
+func TestDecryptAfterRotation(t *testing.T) {
+	old := newTestKeyring(t, "k1", map[string][]byte{"k1": testKey(1)})
+	sealed, err := old.Encrypt([]byte("Jl. Merdeka 1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := newTestKeyring(t, "k2", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	if got, err := rotated.Decrypt(sealed); err != nil || string(got) != "Jl. Merdeka 1" {
+		t.Fatalf("Decrypt() with the old key still listed = %q, %v", got, err)
+	}
+	if strings.HasPrefix(sealed, rotated.ActivePrefix()) {
+		t.Error("value sealed with k1 has the active prefix of k2")
+	}
+
+	retired := newTestKeyring(t, "k2", map[string][]byte{"k2": testKey(2)})
+	if _, err := retired.Decrypt(sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Decrypt() after removing k1 error = %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+func TestDecryptRejectsTamperedValues(t *testing.T) {
+	k := newTestKeyring(t, "k1", map[string][]byte{"k1": testKey(1)})
+	sealed, err := k.Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(sealed, ":")
+
+	tests := map[string]string{
+		"missing part":    prefix + "k1:abc",
+		"invalid base64":  prefix + "k1:!!:!!",
+		"short payload":   prefix + "k1:" + parts[3] + ":AA",
+		"swapped payload": strings.Join(append(parts[:4:4], parts[3]), ":"),
+	}
+	for name, value := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := k.Decrypt(value); err == nil {
+				t.Error("Decrypt succeeded, want an error")
+			}
+		})
+	}
+
+	otherMaster := newTestKeyring(t, "k1", map[string][]byte{"k1": testKey(3)})
+	if _, err := otherMaster.Decrypt(sealed); err == nil {
+		t.Error("Decrypt with a different master key under the same id succeeded")
+	}
+}
+
+func TestNewKeyringValidatesKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     map[string][]byte
+		activeID string
+		indexKey []byte
+		wantErr  error
+	}{
+		{name: "unknown active key", keys: map[string][]byte{"k1": testKey(1)}, activeID: "k2", indexKey: testKey(9), wantErr: ErrUnknownKey},
+		{name: "short master key", keys: map[string][]byte{"k1": testKey(1)[:16]}, activeID: "k1", indexKey: testKey(9), wantErr: ErrInvalidKeyLen},
+		{name: "short index key", keys: map[string][]byte{"k1": testKey(1)}, activeID: "k1", indexKey: testKey(9)[:16], wantErr: ErrInvalidKeyLen},
+		{name: "id with colon", keys: map[string][]byte{"k1": testKey(1), "a:b": testKey(2)}, activeID: "k1", indexKey: testKey(9)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyring(tt.keys, tt.activeID, tt.indexKey)
+			if err == nil {
+				t.Fatal("NewKeyring succeeded, want an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	one := base64.StdEncoding.EncodeToString(testKey(1))
+	two := base64.StdEncoding.EncodeToString(testKey(2))
+
+	keys, ids, err := ParseKeys("# rotated 2025-11\nk2:" + two + "\n k1 : " + one + ",k2:" + two)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != "k2" || ids[1] != "k1" {
+		t.Errorf("ids = %v, want [k2 k1]", ids)
+	}
+	if !bytes.Equal(keys["k1"], testKey(1)) || !bytes.Equal(keys["k2"], testKey(2)) {
+		t.Errorf("keys = %v", keys)
+	}
+
+	for _, invalid := range []string{"k1", "k1:not base64"} {
+		if _, _, err := ParseKeys(invalid); err == nil {
+			t.Errorf("ParseKeys(%q) succeeded, want an error", invalid)
+		}
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	k := newTestKeyring(t, "k1", map[string][]byte{"k1": testKey(1)})
+
+	index := k.BlindIndex("+6281234567890")
+	if len(index) != 64 {
+		t.Errorf("index %q is not a hex SHA-256", index)
+	}
+	if k.BlindIndex("+6281234567890") != index {
+		t.Error("blind index is not deterministic")
+	}
+	if k.BlindIndex("+6281234567891") == index {
+		t.Error("different values have the same blind index")
+	}
+
+	// Rotating master keys keeps indexes, a different index key does not
+	rotated := newTestKeyring(t, "k2", map[string][]byte{"k2": testKey(2)})
+	if rotated.BlindIndex("+6281234567890") != index {
+		t.Error("blind index changed with the master key")
+	}
+	other, err := NewKeyring(map[string][]byte{"k1": testKey(1)}, "k1", testKey(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.BlindIndex("+6281234567890") == index {
+		t.Error("blind index does not depend on the index key")
+	}
+}
+
+func TestEncryptedColumns(t *testing.T) {
+	previous := Default()
+	t.Cleanup(func() { SetDefault(previous) })
+	SetDefault(newTestKeyring(t, "k1", map[string][]byte{"k1": testKey(1)}))
+
+	stored, err := EncryptedString("Jl. Merdeka 1").Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var address EncryptedString
+	if err := address.Scan([]byte(stored.(string))); err != nil || address != "Jl. Merdeka 1" {
+		t.Errorf("Scan() = %q, %v", address, err)
+	}
+	if empty, _ := EncryptedString("").Value(); empty != "" {
+		t.Errorf("empty string stored as %q", empty)
+	}
+
+	birthday := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+	dob := NewEncryptedDate(&birthday)
+	stored, err = dob.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var scanned EncryptedDate
+	if err := scanned.Scan(stored); err != nil || !scanned.Equal(dob.Time) {
+		t.Errorf("Scan() = %v, %v, want %v", scanned.Time, err, dob.Time)
+	}
+}
@@ -0,0 +1,128 @@
+package pii
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// EncryptedString is a string column encrypted with the default keyring.
+// Empty strings are stored as is.
+type EncryptedString string
+
+// Value implements driver.Valuer
+func (s EncryptedString) Value() (driver.Value, error) {
+	if s == "" {
+		return "", nil
+	}
+	k := Default()
+	if k == nil {
+		return nil, ErrNoKeyring
+	}
+	return k.Encrypt([]byte(s))
+}
+
+// Scan implements sql.Scanner
+func (s *EncryptedString) Scan(src interface{}) error {
+	value, err := scanText(src)
+	if err != nil {
+		return err
+	}
+	plaintext, err := decrypt(value)
+	if err != nil {
+		return err
+	}
+	*s = EncryptedString(plaintext)
+	return nil
+}
+
+// String returns the plaintext
+func (s EncryptedString) String() string {
+	return string(s)
+}
+
+// EncryptedDate is a date column encrypted with the default keyring. It
+// marshals to JSON like time.Time.
+type EncryptedDate struct {
+	time.Time
+}
+
+// NewEncryptedDate wraps t, keeping nil as nil
+func NewEncryptedDate(t *time.Time) *EncryptedDate {
+	if t == nil {
+		return nil
+	}
+	return &EncryptedDate{Time: *t}
+}
+
+// TimePtr returns the date as *time.Time, nil when d is nil
+func (d *EncryptedDate) TimePtr() *time.Time {
+	if d == nil {
+		return nil
+	}
+	t := d.Time
+	return &t
+}
+
+// Value implements driver.Valuer
+func (d EncryptedDate) Value() (driver.Value, error) {
+	k := Default()
+	if k == nil {
+		return nil, ErrNoKeyring
+	}
+	return k.Encrypt([]byte(d.Format(dateLayout)))
+}
+
+// Scan implements sql.Scanner. Plain DATE values from before the column was
+// encrypted are accepted as well.
+func (d *EncryptedDate) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		d.Time = time.Time{}
+		return nil
+	case time.Time:
+		d.Time = v
+		return nil
+	}
+
+	value, err := scanText(src)
+	if err != nil {
+		return err
+	}
+	plaintext, err := decrypt(value)
+	if err != nil {
+		return err
+	}
+	t, err := time.Parse(dateLayout, string(plaintext))
+	if err != nil {
+		return fmt.Errorf("pii: invalid date: %w", err)
+	}
+	d.Time = t
+	return nil
+}
+
+func scanText(src interface{}) (string, error) {
+	switch v := src.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		return "", fmt.Errorf("pii: cannot scan %T", src)
+	}
+}
+
+func decrypt(value string) ([]byte, error) {
+	if !IsEncrypted(value) {
+		return []byte(value), nil
+	}
+	k := Default()
+	if k == nil {
+		return nil, ErrNoKeyring
+	}
+	return k.Decrypt(value)
+}
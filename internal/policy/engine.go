@@ -0,0 +1,105 @@
+package policy
+
+import (
+	"strings"
+	"time"
+)
+
+// Engine evaluates authorization requests against compiled policy rules
+type Engine struct {
+	rules         []Rule
+	defaultEffect string
+	location      *time.Location
+	now           func() time.Time
+}
+
+// Request is an authorization question: may the subject perform the action
+// on the resource in the given environment?
+type Request struct {
+	Subject     map[string]any
+	Action      string
+	Resource    Resource
+	Environment map[string]any
+}
+
+// Resource describes the target of an action
+type Resource struct {
+	Type       string
+	Attributes map[string]any
+}
+
+// Decision is the outcome of an evaluation. RuleID is empty when no rule
+// matched and the default effect was applied.
+type Decision struct {
+	Allowed bool
+	Effect  string
+	RuleID  string
+	Reason  string
+}
+
+// Evaluate evaluates a request. Deny rules override allow rules.
+func (e *Engine) Evaluate(req Request) Decision {
+	input := map[string]any{
+		"subject":  nonNil(req.Subject),
+		"resource": resourceInput(req.Resource),
+		"env":      e.environment(req.Environment),
+		"action":   req.Action,
+	}
+
+	var allowed *Rule
+	for i := range e.rules {
+		rule := &e.rules[i]
+		if !rule.matches(input, req.Action, req.Resource.Type) {
+			continue
+		}
+		if rule.Effect == EffectDeny {
+			return Decision{Allowed: false, Effect: EffectDeny, RuleID: rule.ID, Reason: rule.Description}
+		}
+		if allowed == nil {
+			allowed = rule
+		}
+	}
+
+	if allowed != nil {
+		return Decision{Allowed: true, Effect: EffectAllow, RuleID: allowed.ID, Reason: allowed.Description}
+	}
+
+	return Decision{
+		Allowed: e.defaultEffect == EffectAllow,
+		Effect:  e.defaultEffect,
+		Reason:  "no matching rule",
+	}
+}
+
+// environment builds the env attributes. The clock derived values cannot be
+// overridden by the caller.
+func (e *Engine) environment(extra map[string]any) map[string]any {
+	env := make(map[string]any, len(extra)+4)
+	for k, v := range extra {
+		env[k] = v
+	}
+
+	now := e.now().In(e.location)
+	env["time"] = now.Format("15:04")
+	env["date"] = now.Format("2006-01-02")
+	env["hour"] = now.Hour()
+	env["weekday"] = strings.ToLower(now.Weekday().String())
+
+	return env
+}
+
+func resourceInput(resource Resource) map[string]any {
+	input := make(map[string]any, len(resource.Attributes)+1)
+	for k, v := range resource.Attributes {
+		input[k] = v
+	}
+	input["type"] = resource.Type
+	return input
+}
+
+func nonNil(m map[string]any) map[string]any {
+	if m == nil {
+		return map[string]any{}
+	}
+	return m
+}
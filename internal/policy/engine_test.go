@@ -0,0 +1,164 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadRejectsInvalidPolicies(t *testing.T) {
+	tests := map[string]string{
+		"default":     "default: maybe",
+		"timezone":    "timezone: Mars/Olympus",
+		"missing id":  "rules: [{effect: allow, actions: [a]}]",
+		"duplicate":   "rules: [{id: r, effect: allow, actions: [a]}, {id: r, effect: deny, actions: [b]}]",
+		"effect":      "rules: [{id: r, effect: permit, actions: [a]}]",
+		"no actions":  "rules: [{id: r, effect: allow}]",
+		"expression":  `rules: [{id: r, effect: allow, actions: [a], when: {all: ["subject.roles has x"]}}]`,
+		"invalid yml": "rules: [",
+	}
+	for name, doc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load([]byte(doc)); err == nil {
+				t.Error("Load succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestRepositoryPoliciesLoad(t *testing.T) {
+	if _, err := LoadFile("../../policies/policies.yaml"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const testPolicy = `
+timezone: Asia/Jakarta
+rules:
+  - id: seller-own-store
+    description: Sellers edit their own store
+    effect: allow
+    actions: ["stores:*"]
+    resources: ["store"]
+    when:
+      all:
+        - subject.roles contains "seller"
+        - resource.owner_id == subject.user_id
+  - id: support-pii
+    effect: allow
+    actions: ["pii:read", "pii:export"]
+    when:
+      any:
+        - subject.roles contains "support"
+        - subject.roles contains "admin"
+  - id: support-no-export
+    effect: deny
+    actions: ["pii:export"]
+    when:
+      all:
+        - subject.roles contains "support"
+  - id: business-hours
+    effect: deny
+    actions: ["orders:refund"]
+    when:
+      any:
+        - env.hour < 9
+        - env.weekday in ["saturday", "sunday"]
+  - id: refund
+    effect: Allow
+    actions: ["orders:refund"]
+`
+
+func TestEvaluate(t *testing.T) {
+	engine, err := Load([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Monday 10:00 in Jakarta, 03:00 UTC
+	engine.now = func() time.Time { return time.Date(2025, 12, 1, 3, 0, 0, 0, time.UTC) }
+
+	seller := map[string]any{"user_id": 7, "roles": []string{"seller"}}
+	support := map[string]any{"user_id": 8, "roles": []string{"support"}}
+	admin := map[string]any{"user_id": 9, "roles": []string{"admin"}}
+	store := func(ownerID any) Resource {
+		return Resource{Type: "store", Attributes: map[string]any{"owner_id": ownerID}}
+	}
+
+	tests := []struct {
+		name    string
+		req     Request
+		allowed bool
+		ruleID  string
+	}{
+		{"own store", Request{Subject: seller, Action: "stores:update", Resource: store(7)}, true, "seller-own-store"},
+		{"own store as string id", Request{Subject: seller, Action: "stores:read", Resource: store("7")}, true, "seller-own-store"},
+		{"other store", Request{Subject: seller, Action: "stores:update", Resource: store(8)}, false, ""},
+		{"wrong resource type", Request{Subject: seller, Action: "stores:update", Resource: Resource{Type: "order", Attributes: map[string]any{"owner_id": 7}}}, false, ""},
+		{"any condition", Request{Subject: admin, Action: "pii:export"}, true, "support-pii"},
+		{"deny overrides allow", Request{Subject: support, Action: "pii:export"}, false, "support-no-export"},
+		{"allow without deny", Request{Subject: support, Action: "pii:read"}, true, "support-pii"},
+		{"business hours", Request{Subject: support, Action: "orders:refund"}, true, "refund"},
+		{"no subject", Request{Action: "pii:read"}, false, ""},
+		{"unknown action", Request{Subject: admin, Action: "users:delete"}, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := engine.Evaluate(tt.req)
+			if decision.Allowed != tt.allowed || decision.RuleID != tt.ruleID {
+				t.Errorf("Evaluate() = %+v, want allowed %v by %q", decision, tt.allowed, tt.ruleID)
+			}
+		})
+	}
+
+	// Saturday 10:00 in Jakarta
+	engine.now = func() time.Time { return time.Date(2025, 12, 6, 10, 0, 0, 0, jakarta) }
+	if decision := engine.Evaluate(Request{Subject: support, Action: "orders:refund"}); decision.Allowed || decision.RuleID != "business-hours" {
+		t.Errorf("refund on a Saturday = %+v, want denied by business-hours", decision)
+	}
+
+	// The clock cannot be overridden by the caller, 08:00 in Jakarta
+	engine.now = func() time.Time { return time.Date(2025, 12, 1, 1, 0, 0, 0, time.UTC) }
+	decision := engine.Evaluate(Request{Subject: support, Action: "orders:refund", Environment: map[string]any{"hour": 12}})
+	if decision.Allowed {
+		t.Errorf("refund at 08:00 with env.hour set by the caller = %+v, want denied", decision)
+	}
+}
+
+func TestEvaluateDefaultEffect(t *testing.T) {
+	engine, err := Load([]byte("default: allow\nrules: [{id: no-delete, effect: deny, actions: [\"users:delete\"]}]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decision := engine.Evaluate(Request{Action: "users:read"})
+	if !decision.Allowed || decision.RuleID != "" || !strings.Contains(decision.Reason, "no matching rule") {
+		t.Errorf("unmatched request = %+v, want the default allow", decision)
+	}
+	if decision := engine.Evaluate(Request{Action: "users:delete"}); decision.Allowed {
+		t.Errorf("denied action = %+v, want denied", decision)
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		value    string
+		want     bool
+	}{
+		{[]string{"*"}, "anything", true},
+		{[]string{"admin:*"}, "admin:manage_roles", true},
+		{[]string{"admin:*"}, "administer", false},
+		{[]string{"stores:read", "stores:update"}, "stores:update", true},
+		{[]string{"stores:read"}, "stores:reader", false},
+		{nil, "stores:read", false},
+	}
+	for _, tt := range tests {
+		if got := matchAny(tt.patterns, tt.value); got != tt.want {
+			t.Errorf("matchAny(%q, %q) = %v, want %v", tt.patterns, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package policy
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+var operators = map[string]bool{
+	"==": true, "!=": true,
+	"<": true, "<=": true, ">": true, ">=": true,
+	"in": true, "not_in": true,
+	"contains": true, "not_contains": true,
+}
+
+var roots = map[string]bool{"subject": true, "resource": true, "env": true, "action": true}
+
+type operand struct {
+	path    []string
+	literal any
+}
+
+type expression struct {
+	source string
+	left   operand
+	op     string
+	right  operand
+}
+
+func parseExpression(src string) (expression, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return expression{}, fmt.Errorf("expression %q: %w", src, err)
+	}
+	if len(tokens) != 3 {
+		return expression{}, fmt.Errorf("expression %q: expected \"<operand> <operator> <operand>\"", src)
+	}
+	if !operators[tokens[1]] {
+		return expression{}, fmt.Errorf("expression %q: unknown operator %q", src, tokens[1])
+	}
+
+	left, err := parseOperand(tokens[0])
+	if err != nil {
+		return expression{}, fmt.Errorf("expression %q: %w", src, err)
+	}
+	right, err := parseOperand(tokens[2])
+	if err != nil {
+		return expression{}, fmt.Errorf("expression %q: %w", src, err)
+	}
+
+	return expression{source: src, left: left, op: tokens[1], right: right}, nil
+}
+
+// tokenize splits on whitespace, keeping quoted strings and [lists] intact
+func tokenize(src string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	var quote rune
+	depth := 0
+
+	for _, r := range src {
+		switch {
+		case quote != 0:
+			current.WriteRune(r)
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			current.WriteRune(r)
+		case r == '[':
+			depth++
+			current.WriteRune(r)
+		case r == ']':
+			depth--
+			current.WriteRune(r)
+		case (r == ' ' || r == '\t') && depth == 0:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if quote != 0 || depth != 0 {
+		return nil, fmt.Errorf("unterminated string or list")
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens, nil
+}
+
+// parseOperand parses an attribute path (subject.user_id) or a YAML literal
+func parseOperand(token string) (operand, error) {
+	path := strings.Split(token, ".")
+	if roots[path[0]] && !strings.ContainsAny(token, "\"'[") {
+		return operand{path: path}, nil
+	}
+
+	var literal any
+	if err := yaml.Unmarshal([]byte(token), &literal); err != nil {
+		return operand{}, fmt.Errorf("invalid literal %s", token)
+	}
+	return operand{literal: normalize(literal)}, nil
+}
+
+func (o operand) resolve(input map[string]any) any {
+	if o.path == nil {
+		return o.literal
+	}
+
+	var current any = input
+	for _, key := range o.path {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = m[key]
+	}
+	return normalize(current)
+}
+
+func (e expression) eval(input map[string]any) bool {
+	left := e.left.resolve(input)
+	right := e.right.resolve(input)
+
+	switch e.op {
+	case "==":
+		return equal(left, right)
+	case "!=":
+		return !equal(left, right)
+	case "<", "<=", ">", ">=":
+		return compare(left, right, e.op)
+	case "in":
+		return contains(right, left)
+	case "not_in":
+		return !contains(right, left)
+	case "contains":
+		return contains(left, right)
+	case "not_contains":
+		return !contains(left, right)
+	}
+	return false
+}
+
+// normalize converts numbers to float64 and slices to []any so values coming
+// from JWT claims, JSON bodies and YAML literals compare consistently
+func normalize(v any) any {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case string, bool, float64:
+		return val
+	case []any:
+		out := make([]any, len(val))
+		for i := range val {
+			out[i] = normalize(val[i])
+		}
+		return out
+	case []string:
+		out := make([]any, len(val))
+		for i := range val {
+			out[i] = val[i]
+		}
+		return out
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32:
+		return rv.Float()
+	}
+	return v
+}
+
+func equal(a, b any) bool {
+	if as, ok := a.(string); ok {
+		if bf, ok := b.(float64); ok {
+			af, err := strconv.ParseFloat(as, 64)
+			return err == nil && af == bf
+		}
+	}
+	if bs, ok := b.(string); ok {
+		if _, ok := a.(float64); ok {
+			return equal(bs, a)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func compare(a, b any, op string) bool {
+	af, aok := a.(float64)
+	bf, bok := b.(float64)
+	if aok && bok {
+		switch op {
+		case "<":
+			return af < bf
+		case "<=":
+			return af <= bf
+		case ">":
+			return af > bf
+		case ">=":
+			return af >= bf
+		}
+	}
+
+	as, aok := a.(string)
+	bs, bok := b.(string)
+	if aok && bok {
+		switch op {
+		case "<":
+			return as < bs
+		case "<=":
+			return as <= bs
+		case ">":
+			return as > bs
+		case ">=":
+			return as >= bs
+		}
+	}
+
+	return false
+}
+
+func contains(collection, item any) bool {
+	switch c := collection.(type) {
+	case []any:
+		for _, v := range c {
+			if equal(v, item) {
+				return true
+			}
+		}
+	case string:
+		s, ok := item.(string)
+		return ok && strings.Contains(c, s)
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package policy
+
+import "testing"
+
+func TestParseExpressionErrors(t *testing.T) {
+	for _, src := range []string{
+		"subject.user_id",
+		"subject.user_id == ",
+		"subject.user_id equals 1",
+		`subject.name == "budi`,
+		`subject.roles contains ["seller"`,
+		"subject.user_id == 1 extra",
+		"subject.user_id == [1, }",
+	} {
+		if _, err := parseExpression(src); err == nil {
+			t.Errorf("parseExpression(%q) succeeded, want an error", src)
+		}
+	}
+}
+
+func TestExpressionEval(t *testing.T) {
+	input := map[string]any{
+		"subject": map[string]any{
+			"user_id":     7,
+			"name":        "budi",
+			"roles":       []string{"customer", "seller"},
+			"permissions": []any{"orders:refund"},
+			"active":      true,
+		},
+		"resource": map[string]any{
+			"owner_id": "7",
+			"amount":   int64(150000),
+			"tags":     []any{1, 2},
+		},
+		"env":    map[string]any{"hour": 9, "weekday": "monday", "time": "09:30"},
+		"action": "orders:refund",
+	}
+
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		// Numbers compare across int, int64, float and numeric strings
+		{"resource.owner_id == subject.user_id", true},
+		{"subject.user_id == 7", true},
+		{"subject.user_id != 8", true},
+		{"resource.amount > 100000", true},
+		{"resource.amount <= 100000", false},
+		{"env.hour >= 9", true},
+		{"env.hour < 9", false},
+		// Strings compare lexically, e.g. clock times
+		{`env.time < "17:00"`, true},
+		{`env.time >= '10:00'`, false},
+		{`subject.name == "budi"`, true},
+		{"subject.active == true", true},
+		{"action == orders:refund", true},
+		// Lists
+		{`subject.roles contains "seller"`, true},
+		{`subject.roles not_contains "admin"`, true},
+		{`subject.permissions contains "orders:refund"`, true},
+		{"resource.tags contains 2", true},
+		{`env.weekday in ["saturday", "sunday"]`, false},
+		{`env.weekday not_in ["saturday", "sunday"]`, true},
+		{"subject.user_id in [1, 7, 9]", true},
+		// Substrings
+		{`subject.name contains "ud"`, true},
+		// Missing attributes are nil and never equal or ordered
+		{"subject.missing == 0", false},
+		{"subject.missing.deeper == 0", false},
+		{"subject.missing < 1", false},
+		{`subject.missing contains "x"`, false},
+		// Mixed types do not compare
+		{`subject.name > 1`, false},
+	}
+	for _, tt := range tests {
+		expr, err := parseExpression(tt.src)
+		if err != nil {
+			t.Errorf("parseExpression(%q): %v", tt.src, err)
+			continue
+		}
+		if got := expr.eval(input); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
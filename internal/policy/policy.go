@@ -0,0 +1,161 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// Document is the YAML representation of a policy file
+type Document struct {
+	Default  string `yaml:"default"`
+	Timezone string `yaml:"timezone"`
+	Rules    []Rule `yaml:"rules"`
+}
+
+// Rule is a single policy rule. A rule matches when the action and resource
+// type match and its conditions hold. Deny rules take precedence over allow
+// rules, when no rule matches the document default effect is used.
+type Rule struct {
+	ID          string    `yaml:"id"`
+	Description string    `yaml:"description"`
+	Effect      string    `yaml:"effect"`
+	Actions     []string  `yaml:"actions"`
+	Resources   []string  `yaml:"resources"`
+	When        Condition `yaml:"when"`
+
+	all []expression
+	any []expression
+}
+
+// Condition holds expressions that must all hold (all) and of which at least
+// one must hold (any). Expressions have the form "<operand> <operator> <operand>",
+// e.g. `resource.owner_id == subject.user_id` or `subject.roles contains "seller"`.
+type Condition struct {
+	All []string `yaml:"all"`
+	Any []string `yaml:"any"`
+}
+
+// LoadFile reads and compiles a policy file
+func LoadFile(path string) (*Engine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy file: %w", err)
+	}
+
+	return Load(data)
+}
+
+// Load parses and compiles a policy document
+func Load(data []byte) (*Engine, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse policy file: %w", err)
+	}
+
+	if doc.Default == "" {
+		doc.Default = EffectDeny
+	}
+	if doc.Default != EffectAllow && doc.Default != EffectDeny {
+		return nil, fmt.Errorf("invalid default effect %q", doc.Default)
+	}
+
+	location := time.Local
+	if doc.Timezone != "" {
+		loc, err := time.LoadLocation(doc.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", doc.Timezone, err)
+		}
+		location = loc
+	}
+
+	seen := make(map[string]bool, len(doc.Rules))
+	for i := range doc.Rules {
+		rule := &doc.Rules[i]
+		if rule.ID == "" {
+			return nil, fmt.Errorf("rule #%d has no id", i+1)
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("duplicate rule id %q", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		rule.Effect = strings.ToLower(rule.Effect)
+		if rule.Effect != EffectAllow && rule.Effect != EffectDeny {
+			return nil, fmt.Errorf("rule %q: invalid effect %q", rule.ID, rule.Effect)
+		}
+		if len(rule.Actions) == 0 {
+			return nil, fmt.Errorf("rule %q: at least one action is required", rule.ID)
+		}
+
+		for _, src := range rule.When.All {
+			expr, err := parseExpression(src)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: %w", rule.ID, err)
+			}
+			rule.all = append(rule.all, expr)
+		}
+		for _, src := range rule.When.Any {
+			expr, err := parseExpression(src)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: %w", rule.ID, err)
+			}
+			rule.any = append(rule.any, expr)
+		}
+	}
+
+	return &Engine{
+		rules:         doc.Rules,
+		defaultEffect: doc.Default,
+		location:      location,
+		now:           time.Now,
+	}, nil
+}
+
+// matches reports whether the rule applies to the request
+func (r *Rule) matches(input map[string]any, action, resourceType string) bool {
+	if !matchAny(r.Actions, action) {
+		return false
+	}
+	if len(r.Resources) > 0 && !matchAny(r.Resources, resourceType) {
+		return false
+	}
+
+	for _, expr := range r.all {
+		if !expr.eval(input) {
+			return false
+		}
+	}
+
+	if len(r.any) == 0 {
+		return true
+	}
+	for _, expr := range r.any {
+		if expr.eval(input) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchAny matches a value against patterns where "*" and "prefix*" are wildcards
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == value {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
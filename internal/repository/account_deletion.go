@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/pii"
+	"gorm.io/gorm"
+)
+
+type AccountDeletionRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountDeletionRepository(db *gorm.DB) *AccountDeletionRepository {
+	return &AccountDeletionRepository{db: db}
+}
+
+// ScheduleDeletion marks the account for deletion and signs out every device
+func (r *AccountDeletionRepository) ScheduleDeletion(ctx context.Context, userID int, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).
+			Where("id = ? AND anonymized_at IS NULL", userID).
+			Update("deletion_scheduled_at", at).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", userID).Delete(&models.UserSession{}).Error
+	})
+}
+
+// FindDue finds accounts whose grace period has ended
+func (r *AccountDeletionRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).
+		Where("deletion_scheduled_at <= ? AND anonymized_at IS NULL", now).
+		Order("deletion_scheduled_at").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// Anonymize replaces the user's PII with placeholders that keep the unique
+// indexes satisfied, deletes sessions, credentials, addresses and pending
+// change requests, clears seller KYC data and collision reports, and expires
+// data exports. The row itself is kept so the ID stays valid for foreign
+// references.
+func (r *AccountDeletionRepository) Anonymize(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		username := fmt.Sprintf("deleted_%d", user.ID)
+		email := fmt.Sprintf("deleted+%d@deleted.invalid", user.ID)
+		phone := fmt.Sprintf("deleted:%d", user.ID)
+
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND anonymized_at IS NULL AND deletion_scheduled_at IS NOT NULL", user.ID).
+			Updates(map[string]interface{}{
+				"username":                 username,
+				"username_canonical":       helpers.CanonicalUsername(username),
+				"email":                    email,
+				"email_canonical":          helpers.CanonicalEmail(email),
+				"phone_number":             pii.EncryptedString(phone),
+				"phone_number_index":       pii.BlindIndex(phone),
+				"full_name":                "Deleted User",
+				"address":                  pii.EncryptedString(""),
+				"dob":                      nil,
+				"password":                 "",
+				"reset_password_token":     nil,
+				"reset_password_expiry":    nil,
+				"email_verification_token": nil,
+				"email_verified":           false,
+				"is_active":                false,
+				"anonymized_at":            now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Deletion was cancelled by a login in the meantime
+			return ErrDeletionCancelled
+		}
+
+		for _, model := range []interface{}{
+			&models.UserSession{},
+			&models.Address{},
+			&models.EmailChangeRequest{},
+			&models.PhoneChangeRequest{},
+		} {
+			if err := tx.Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		// Consent records are kept as proof of acceptance, without the
+		// network details that identify the person
+		err := tx.Model(&models.Consent{}).
+			Where("user_id = ?", user.ID).
+			Updates(map[string]interface{}{"ip_address": "", "user_agent": ""}).Error
+		if err != nil {
+			return err
+		}
+
+		// KYC numbers are cleared and the documents marked so the cleanup job
+		// removes the files
+		applications := tx.Model(&models.SellerApplication{}).Select("id").Where("user_id = ?", user.ID)
+		err = tx.Model(&models.SellerDocument{}).
+			Where("application_id IN (?) AND deleted_at IS NULL", applications).
+			Updates(map[string]interface{}{"file_name": "", "deleted_at": now}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.SellerApplication{}).
+			Where("user_id = ?", user.ID).
+			Updates(map[string]interface{}{"nik": "", "npwp": "", "reviewer_notes": ""}).Error
+		if err != nil {
+			return err
+		}
+
+		// Collision reports hold the user's email, username and phone number
+		err = tx.Where("user_id = ? OR conflicting_user_id = ?", user.ID, user.ID).
+			Delete(&models.IdentityCollision{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Exec(`UPDATE phone_normalization_conflicts
+			SET phone_numbers = phone_numbers[:array_position(user_ids, ?) - 1] || phone_numbers[array_position(user_ids, ?) + 1:],
+				user_ids = array_remove(user_ids, ?)
+			WHERE ? = ANY(user_ids)`, user.ID, user.ID, user.ID, user.ID).Error
+		if err != nil {
+			return err
+		}
+		// A conflict with one account left is no conflict anymore
+		err = tx.Exec("DELETE FROM phone_normalization_conflicts WHERE cardinality(user_ids) < 2").Error
+		if err != nil {
+			return err
+		}
+
+		// Expire data exports so the cleanup job removes the archives
+		err = tx.Model(&models.DataExport{}).
+			Where("user_id = ? AND status IN ?", user.ID, []string{models.DataExportPending, models.DataExportProcessing, models.DataExportReady}).
+			Updates(map[string]interface{}{
+				"status":     gorm.Expr("CASE WHEN status = ? THEN status ELSE ? END", models.DataExportReady, models.DataExportFailed),
+				"expires_at": now,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		user.AnonymizedAt = &now
+		return nil
+	})
+}
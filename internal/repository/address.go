@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"gorm.io/gorm"
+)
+
+type AddressRepository struct {
+	db *gorm.DB
+}
+
+func NewAddressRepository(db *gorm.DB) *AddressRepository {
+	return &AddressRepository{db: db}
+}
+
+// ListByUserID lists the addresses of a user, defaults first
+func (r *AddressRepository) ListByUserID(ctx context.Context, userID int) ([]models.Address, error) {
+	var addresses []models.Address
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("is_default_shipping DESC, is_default_billing DESC, id").
+		Find(&addresses).Error
+	return addresses, err
+}
+
+// CountByUserID counts the addresses of a user
+func (r *AddressRepository) CountByUserID(ctx context.Context, userID int) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Address{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// FindByID finds an address owned by the user
+func (r *AddressRepository) FindByID(ctx context.Context, userID, id int) (*models.Address, error) {
+	var address models.Address
+	err := r.db.WithContext(ctx).Where("user_id = ? AND id = ?", userID, id).First(&address).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &address, nil
+}
+
+// Save creates or updates an address. When the address is a default, the
+// flag is cleared on the user's other addresses in the same transaction.
+func (r *AddressRepository) Save(ctx context.Context, address *models.Address) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if address.IsDefaultShipping {
+			if err := tx.Model(&models.Address{}).
+				Where("user_id = ? AND id <> ?", address.UserID, address.ID).
+				Update("is_default_shipping", false).Error; err != nil {
+				return err
+			}
+		}
+		if address.IsDefaultBilling {
+			if err := tx.Model(&models.Address{}).
+				Where("user_id = ? AND id <> ?", address.UserID, address.ID).
+				Update("is_default_billing", false).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Save(address).Error
+	})
+}
+
+// Delete deletes an address. If it was a default, the oldest remaining
+// address inherits the flag.
+func (r *AddressRepository) Delete(ctx context.Context, address *models.Address) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(address).Error; err != nil {
+			return err
+		}
+
+		for column, wasDefault := range map[string]bool{
+			"is_default_shipping": address.IsDefaultShipping,
+			"is_default_billing":  address.IsDefaultBilling,
+		} {
+			if !wasDefault {
+				continue
+			}
+			err := tx.Exec(`UPDATE user_addresses SET `+column+` = TRUE
+				WHERE id = (SELECT id FROM user_addresses WHERE user_id = ? ORDER BY id LIMIT 1)`, address.UserID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -5,8 +5,11 @@ import (
 	"errors"
 	"time"
 
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/pii"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AuthRepository struct {
@@ -17,15 +20,40 @@ func NewAuthRepository(db *gorm.DB) *AuthRepository {
 	return &AuthRepository{db: db}
 }
 
-// CreateUser creates a new user
-func (r *AuthRepository) CreateUser(ctx context.Context, user *models.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+// RegisterUser creates a user with a role and the consents given at sign-up
+// in one transaction, setting the consents' user ID. The unique indexes
+// decide whether the email, username and phone number are free, so
+// concurrent sign-ups cannot both succeed; the losing one gets a
+// *constants.ConflictError listing every taken field.
+func (r *AuthRepository) RegisterUser(ctx context.Context, user *models.User, roleID int, consents []models.Consent) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := insertUser(tx, user); err != nil {
+			return err
+		}
+
+		if roleID != 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&models.UserRole{UserID: user.ID, RoleID: roleID}).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(consents) == 0 {
+			return nil
+		}
+		for i := range consents {
+			consents[i].UserID = user.ID
+		}
+		return tx.Create(&consents).Error
+	})
 }
 
-// FindByEmail finds user by email
-func (r *AuthRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+// FindByEmail finds user by email within a tenant, ignoring case
+func (r *AuthRepository) FindByEmail(ctx context.Context, tenantID int, email string) (*models.User, error) {
 	var user models.User
-	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND email_canonical = ?", tenantID, helpers.CanonicalEmail(email)).
+		First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -35,10 +63,14 @@ func (r *AuthRepository) FindByEmail(ctx context.Context, email string) (*models
 	return &user, nil
 }
 
-// FindByPhone
-func (r *AuthRepository) FindByPhone(ctx context.Context, phone string) (*models.User, error) {
+// FindByPhone finds user by phone number within a tenant. The number is
+// normalized to E.164 first, the format every phone number is stored in, and
+// looked up through its blind index since the column is encrypted
+func (r *AuthRepository) FindByPhone(ctx context.Context, tenantID int, phone string) (*models.User, error) {
 	var user models.User
-	err := r.db.WithContext(ctx).Where("phone_number = ?", phone).First(&user).Error
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND phone_number_index = ?", tenantID, pii.BlindIndex(helpers.NormalizePhoneOrRaw(phone))).
+		First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -50,10 +82,13 @@ func (r *AuthRepository) FindByPhone(ctx context.Context, phone string) (*models
 	return &user, nil
 }
 
-// FindByUsername finds user by username
-func (r *AuthRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+// FindByUsername finds user by username within a tenant, ignoring case and
+// confusable characters
+func (r *AuthRepository) FindByUsername(ctx context.Context, tenantID int, username string) (*models.User, error) {
 	var user models.User
-	err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND username_canonical = ?", tenantID, helpers.CanonicalUsername(username)).
+		First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -63,11 +98,15 @@ func (r *AuthRepository) FindByUsername(ctx context.Context, username string) (*
 	return &user, nil
 }
 
-// FindByEmailOrUsername finds user by email or username
-func (r *AuthRepository) FindByEmailOrUsername(ctx context.Context, emailOrUsername string) (*models.User, error) {
+// FindByEmailOrUsername finds user by email or username within a tenant using
+// the canonical forms. An exact match wins, so accounts listed in
+// identity_collisions can still log in with their own spelling.
+func (r *AuthRepository) FindByEmailOrUsername(ctx context.Context, tenantID int, emailOrUsername string) (*models.User, error) {
 	var user models.User
 	err := r.db.WithContext(ctx).
-		Where("email = ? OR username = ?", emailOrUsername, emailOrUsername).
+		Where("tenant_id = ? AND (email_canonical = ? OR username_canonical = ? OR email = ? OR username = ?)",
+			tenantID, helpers.CanonicalEmail(emailOrUsername), helpers.CanonicalUsername(emailOrUsername), emailOrUsername, emailOrUsername).
+		Order(clause.Expr{SQL: "CASE WHEN email = ? OR username = ? THEN 0 ELSE 1 END", Vars: []interface{}{emailOrUsername, emailOrUsername}}).
 		First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -99,6 +138,14 @@ func (r *AuthRepository) UpdatePassword(ctx context.Context, userID int, hashedP
 		Update("password", hashedPassword).Error
 }
 
+// UpdateProfile updates the given profile columns
+func (r *AuthRepository) UpdateProfile(ctx context.Context, userID int, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(updates).Error
+}
+
 // SaveResetToken saves password reset token
 func (r *AuthRepository) SaveResetToken(ctx context.Context, userID int, token string, expiry string) error {
 	expiryTime, err := time.Parse(time.RFC3339, expiry)
@@ -115,11 +162,11 @@ func (r *AuthRepository) SaveResetToken(ctx context.Context, userID int, token s
 		}).Error
 }
 
-// FindByResetToken finds user by reset token
-func (r *AuthRepository) FindByResetToken(ctx context.Context, token string) (*models.User, error) {
+// FindByResetToken finds user by reset token within a tenant
+func (r *AuthRepository) FindByResetToken(ctx context.Context, tenantID int, token string) (*models.User, error) {
 	var user models.User
 	err := r.db.WithContext(ctx).
-		Where("reset_password_token = ? AND reset_password_expiry > ?", token, time.Now()).
+		Where("tenant_id = ? AND reset_password_token = ? AND reset_password_expiry > ?", tenantID, token, time.Now()).
 		First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -141,6 +188,14 @@ func (r *AuthRepository) ClearResetToken(ctx context.Context, userID int) error
 		}).Error
 }
 
+// CancelDeletion clears a pending account deletion
+func (r *AuthRepository) CancelDeletion(ctx context.Context, userID int) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND anonymized_at IS NULL", userID).
+		Update("deletion_scheduled_at", nil).Error
+}
+
 // CreateSession creates a new user session
 func (r *AuthRepository) CreateSession(ctx context.Context, session *models.UserSession) error {
 	return r.db.WithContext(ctx).Create(session).Error
@@ -191,3 +246,38 @@ func (r *AuthRepository) DeleteSession(ctx context.Context, token string) error
 func (r *AuthRepository) DeleteSessionsByUserID(ctx context.Context, userID int) error {
 	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.UserSession{}).Error
 }
+
+// DeleteExpiredSessions deletes sessions whose refresh token expired before the given time
+func (r *AuthRepository) DeleteExpiredSessions(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("refresh_token_expired < ?", before).Delete(&models.UserSession{})
+	return result.RowsAffected, result.Error
+}
+
+// CountActiveSessions counts sessions whose refresh token is still valid at now
+func (r *AuthRepository) CountActiveSessions(ctx context.Context, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.UserSession{}).Where("refresh_token_expired > ?", now).Count(&count).Error
+	return count, err
+}
+
+// insertUser creates user within the transaction tx. The insert runs in a
+// savepoint, so tx stays usable to look up every conflicting field after a
+// unique violation.
+func insertUser(tx *gorm.DB, user *models.User) error {
+	canonicalize(user)
+	err := tx.Transaction(func(sp *gorm.DB) error {
+		return sp.Create(user).Error
+	})
+	if err != nil {
+		return userConflict(tx, user, err)
+	}
+	return nil
+}
+
+// canonicalize fills the canonical email and username columns and the phone
+// blind index, the columns that carry the unique indexes
+func canonicalize(user *models.User) {
+	user.EmailCanonical = helpers.CanonicalEmail(user.Email)
+	user.UsernameCanonical = helpers.CanonicalUsername(user.Username)
+	user.PhoneNumberIndex = pii.BlindIndex(string(user.PhoneNumber))
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrDocumentVersionExists is returned when the document version was already published
+var ErrDocumentVersionExists = errors.New("legal document version already exists")
+
+type ConsentRepository struct {
+	db *gorm.DB
+}
+
+func NewConsentRepository(db *gorm.DB) *ConsentRepository {
+	return &ConsentRepository{db: db}
+}
+
+// FindCurrentDocuments finds the newest published version of each document type
+func (r *ConsentRepository) FindCurrentDocuments(ctx context.Context, tenantID int) ([]models.LegalDocument, error) {
+	var documents []models.LegalDocument
+	err := r.db.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (type) * FROM legal_documents
+			WHERE tenant_id = ? AND published_at <= NOW()
+			ORDER BY type, published_at DESC, id DESC`, tenantID).
+		Scan(&documents).Error
+	return documents, err
+}
+
+// CreateDocument publishes a legal document version
+func (r *ConsentRepository) CreateDocument(ctx context.Context, document *models.LegalDocument) error {
+	err := r.db.WithContext(ctx).Create(document).Error
+	if _, ok := uniqueViolation(err); ok {
+		return ErrDocumentVersionExists
+	}
+	return err
+}
+
+// CreateConsents appends consent records
+func (r *ConsentRepository) CreateConsents(ctx context.Context, consents []models.Consent) error {
+	if len(consents) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&consents).Error
+}
+
+// FindLatestConsents finds the latest consent record of each type for a user
+func (r *ConsentRepository) FindLatestConsents(ctx context.Context, userID int) ([]models.Consent, error) {
+	var consents []models.Consent
+	err := r.db.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (type) * FROM consents
+			WHERE user_id = ?
+			ORDER BY type, created_at DESC, id DESC`, userID).
+		Scan(&consents).Error
+	return consents, err
+}
+
+// ListMarketingConsents lists the current marketing choice of each user
+func (r *ConsentRepository) ListMarketingConsents(ctx context.Context, tenantID int, filter interfaces.MarketingConsentFilter) ([]models.MarketingConsent, error) {
+	latest := r.db.Table("consents").
+		Select("DISTINCT ON (user_id, type) user_id, type, granted, created_at").
+		Where("tenant_id = ? AND type IN ?", tenantID, filter.Types).
+		Order("user_id, type, created_at DESC, id DESC")
+
+	query := r.db.WithContext(ctx).
+		Table("(?) AS c", latest).
+		Select("c.user_id, u.email, u.phone_number, u.full_name, c.type, c.granted, c.created_at AS updated_at").
+		Joins("JOIN users u ON u.id = c.user_id AND u.anonymized_at IS NULL")
+	if filter.Granted != nil {
+		query = query.Where("c.granted = ?", *filter.Granted)
+	}
+	if filter.UpdatedSince != nil {
+		query = query.Where("c.created_at >= ?", *filter.UpdatedSince)
+	}
+
+	var consents []models.MarketingConsent
+	err := query.Order("c.created_at, c.user_id").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Scan(&consents).Error
+	return consents, err
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DataExportRepository struct {
+	db *gorm.DB
+}
+
+func NewDataExportRepository(db *gorm.DB) *DataExportRepository {
+	return &DataExportRepository{db: db}
+}
+
+// Create creates a new export request
+func (r *DataExportRepository) Create(ctx context.Context, export *models.DataExport) error {
+	return r.db.WithContext(ctx).Create(export).Error
+}
+
+// FindByID finds export by ID
+func (r *DataExportRepository) FindByID(ctx context.Context, id int) (*models.DataExport, error) {
+	var export models.DataExport
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&export).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &export, nil
+}
+
+// FindActiveByUserID finds an export of the user that is still being generated
+func (r *DataExportRepository) FindActiveByUserID(ctx context.Context, userID int) (*models.DataExport, error) {
+	var export models.DataExport
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND status IN ?", userID, []string{models.DataExportPending, models.DataExportProcessing}).
+		First(&export).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &export, nil
+}
+
+// ListByUserID lists exports of the user, newest first
+func (r *DataExportRepository) ListByUserID(ctx context.Context, userID int) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&exports).Error
+	return exports, err
+}
+
+// ClaimPending moves pending exports to processing. Rows locked by another
+// replica are skipped, so every export is generated once.
+func (r *DataExportRepository) ClaimPending(ctx context.Context, limit int) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", models.DataExportPending).
+			Order("created_at").
+			Limit(limit).
+			Find(&exports).Error
+		if err != nil || len(exports) == 0 {
+			return err
+		}
+
+		ids := make([]int, len(exports))
+		for i := range exports {
+			ids[i] = exports[i].ID
+			exports[i].Status = models.DataExportProcessing
+		}
+
+		return tx.Model(&models.DataExport{}).
+			Where("id IN ?", ids).
+			Update("status", models.DataExportProcessing).Error
+	})
+	return exports, err
+}
+
+// MarkReady stores the archive location of a generated export
+func (r *DataExportRepository) MarkReady(ctx context.Context, export *models.DataExport) error {
+	return r.db.WithContext(ctx).
+		Model(&models.DataExport{}).
+		Where("id = ?", export.ID).
+		Updates(map[string]interface{}{
+			"status":       models.DataExportReady,
+			"blob_key":     export.BlobKey,
+			"size":         export.Size,
+			"completed_at": export.CompletedAt,
+			"expires_at":   export.ExpiresAt,
+		}).Error
+}
+
+// MarkFailed records why an export could not be generated
+func (r *DataExportRepository) MarkFailed(ctx context.Context, id int, reason string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.DataExport{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status": models.DataExportFailed,
+			"error":  reason,
+		}).Error
+}
+
+// FindExpired finds ready exports whose archive should be removed
+func (r *DataExportRepository) FindExpired(ctx context.Context, now time.Time, limit int) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at <= ?", models.DataExportReady, now).
+		Limit(limit).
+		Find(&exports).Error
+	return exports, err
+}
+
+// MarkExpired marks an export whose archive was removed
+func (r *DataExportRepository) MarkExpired(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).
+		Model(&models.DataExport{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":   models.DataExportExpired,
+			"blob_key": "",
+		}).Error
+}
+
+// FindUserData loads everything stored about the user
+func (r *DataExportRepository) FindUserData(ctx context.Context, userID int) (*models.UserData, error) {
+	db := r.db.WithContext(ctx)
+	data := &models.UserData{}
+
+	if err := db.Where("id = ?", userID).First(&data.User).Error; err != nil {
+		return nil, err
+	}
+
+	err := db.Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.name").
+		Find(&data.Roles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	queries := []struct {
+		dest  interface{}
+		query *gorm.DB
+	}{
+		{&data.Addresses, db.Order("id")},
+		{&data.Sessions, db.Order("created_at")},
+		{&data.EmailChanges, db.Order("created_at")},
+		{&data.PhoneChanges, db.Order("created_at")},
+		{&data.SellerApplications, db.Preload("Documents", "deleted_at IS NULL").Order("created_at")},
+		{&data.DataExports, db.Order("created_at")},
+		{&data.Consents, db.Order("created_at")},
+	}
+	for _, q := range queries {
+		if err := q.query.Where("user_id = ?", userID).Find(q.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrEmailChangeNotPending is returned when the request was already used, cancelled or expired
+	ErrEmailChangeNotPending = errors.New("email change request is not pending")
+	// ErrEmailTaken is returned when the target email is used by another account
+	ErrEmailTaken = errors.New("email already registered")
+)
+
+type EmailChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailChangeRepository(db *gorm.DB) *EmailChangeRepository {
+	return &EmailChangeRepository{db: db}
+}
+
+// Create stores a new request and cancels older pending requests of the user
+func (r *EmailChangeRepository) Create(ctx context.Context, request *models.EmailChangeRequest) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.EmailChangeRequest{}).
+			Where("user_id = ? AND status = ?", request.UserID, models.EmailChangePending).
+			Update("status", models.EmailChangeCancelled).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(request).Error
+	})
+}
+
+// FindByConfirmTokenHash finds request by confirmation token hash
+func (r *EmailChangeRepository) FindByConfirmTokenHash(ctx context.Context, tenantID int, tokenHash string) (*models.EmailChangeRequest, error) {
+	return r.findOne(ctx, "tenant_id = ? AND confirm_token_hash = ?", tenantID, tokenHash)
+}
+
+// FindByCancelTokenHash finds request by cancel token hash
+func (r *EmailChangeRepository) FindByCancelTokenHash(ctx context.Context, tenantID int, tokenHash string) (*models.EmailChangeRequest, error) {
+	return r.findOne(ctx, "tenant_id = ? AND cancel_token_hash = ?", tenantID, tokenHash)
+}
+
+// Apply switches the user to the new email and revokes every session. The
+// unique index on users is the final uniqueness check, so a concurrent
+// registration of the same email makes the whole change fail.
+func (r *EmailChangeRepository) Apply(ctx context.Context, request *models.EmailChangeRequest) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.EmailChangeRequest{}).
+			Where("id = ? AND status = ? AND expires_at > ?", request.ID, models.EmailChangePending, now).
+			Updates(map[string]interface{}{
+				"status":       models.EmailChangeConfirmed,
+				"confirmed_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrEmailChangeNotPending
+		}
+
+		if err := r.switchEmail(tx, request.UserID, request.OldEmail, request.NewEmail); err != nil {
+			return err
+		}
+
+		request.Status = models.EmailChangeConfirmed
+		request.ConfirmedAt = &now
+		return nil
+	})
+}
+
+// Cancel cancels a pending request
+func (r *EmailChangeRepository) Cancel(ctx context.Context, request *models.EmailChangeRequest) error {
+	result := r.db.WithContext(ctx).Model(&models.EmailChangeRequest{}).
+		Where("id = ? AND status = ?", request.ID, models.EmailChangePending).
+		Update("status", models.EmailChangeCancelled)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEmailChangeNotPending
+	}
+	return nil
+}
+
+// Revert restores the old email of a confirmed request and revokes every session
+func (r *EmailChangeRepository) Revert(ctx context.Context, request *models.EmailChangeRequest) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.EmailChangeRequest{}).
+			Where("id = ? AND status = ?", request.ID, models.EmailChangeConfirmed).
+			Update("status", models.EmailChangeReverted)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrEmailChangeNotPending
+		}
+
+		return r.switchEmail(tx, request.UserID, request.NewEmail, request.OldEmail)
+	})
+}
+
+// switchEmail changes the user's email from -> to and deletes all sessions
+func (r *EmailChangeRepository) switchEmail(tx *gorm.DB, userID int, from, to string) error {
+	result := tx.Model(&models.User{}).
+		Where("id = ? AND email = ?", userID, from).
+		Updates(map[string]interface{}{
+			"email":           to,
+			"email_canonical": helpers.CanonicalEmail(to),
+			"email_verified":  true,
+		})
+	if result.Error != nil {
+		if _, ok := uniqueViolation(result.Error); ok {
+			return ErrEmailTaken
+		}
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		// The email was changed by another request in the meantime
+		return ErrEmailChangeNotPending
+	}
+
+	return tx.Where("user_id = ?", userID).Delete(&models.UserSession{}).Error
+}
+
+func (r *EmailChangeRepository) findOne(ctx context.Context, query string, args ...interface{}) (*models.EmailChangeRequest, error) {
+	var request models.EmailChangeRequest
+	err := r.db.WithContext(ctx).Where(query, args...).First(&request).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/constants"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// uniqueViolation returns the Postgres error when err is a unique constraint violation
+func uniqueViolation(err error) (*pgconn.PgError, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == constants.UniqueViolation {
+		return pgErr, true
+	}
+	return nil, false
+}
+
+// userConstraintFields maps the unique indexes of users to the request field
+// they guard
+var userConstraintFields = map[string]string{
+	"ux_users_email":              "email",
+	"ux_users_email_canonical":    "email",
+	"ux_users_username":           "username",
+	"ux_users_username_canonical": "username",
+	"ux_users_phone_number_index": "phone_number",
+}
+
+// userConflict turns a unique violation of inserting user into a
+// ConflictError. Postgres reports only the first violated index, so the other
+// fields are looked up with db, which must not be in an aborted transaction.
+func userConflict(db *gorm.DB, user *models.User, err error) error {
+	pgErr, ok := uniqueViolation(err)
+	if !ok {
+		return err
+	}
+	field, ok := userConstraintFields[pgErr.ConstraintName]
+	if !ok {
+		return err
+	}
+
+	conflicts := map[string]bool{field: true}
+	var existing []models.User
+	lookupErr := db.Select("email_canonical", "username_canonical", "phone_number_index").
+		Where("tenant_id = ? AND (email_canonical = ? OR username_canonical = ? OR phone_number_index = ?)",
+			user.TenantID, user.EmailCanonical, user.UsernameCanonical, user.PhoneNumberIndex).
+		Find(&existing).Error
+	if lookupErr == nil {
+		for _, other := range existing {
+			conflicts["email"] = conflicts["email"] || other.EmailCanonical == user.EmailCanonical
+			conflicts["username"] = conflicts["username"] || other.UsernameCanonical == user.UsernameCanonical
+			conflicts["phone_number"] = conflicts["phone_number"] || other.PhoneNumberIndex == user.PhoneNumberIndex
+		}
+	}
+
+	conflict := &constants.ConflictError{}
+	for _, f := range constants.UniqueUserFields {
+		if conflicts[f] {
+			conflict.Fields = append(conflict.Fields, f)
+		}
+	}
+	return conflict
+}
+
+// ErrDeletionCancelled is returned when an account is no longer pending deletion
+var ErrDeletionCancelled = errors.New("account deletion was cancelled")
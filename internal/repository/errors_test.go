@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/constants"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/migrator"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/pii"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/testdb"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/migrations"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sirupsen/logrus"
+)
+
+func TestUserConflictKeepsOtherErrors(t *testing.T) {
+	tests := map[string]error{
+		"not a postgres error": errors.New("connection reset"),
+		"foreign key":          &pgconn.PgError{Code: "23503", ConstraintName: "fk_users_tenant"},
+		"other unique index":   &pgconn.PgError{Code: constants.UniqueViolation, ConstraintName: "ux_tenants_slug"},
+	}
+	for name, err := range tests {
+		t.Run(name, func(t *testing.T) {
+			// The lookup is only made for user indexes, so no database is needed
+			if got := userConflict(nil, &models.User{}, err); got != err {
+				t.Errorf("userConflict() = %v, want %v unchanged", got, err)
+			}
+		})
+	}
+}
+
+func TestRegisterUserReportsEveryConflict(t *testing.T) {
+	db := testdb.Open(t)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	m, err := migrator.New(db, migrations.FS, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := pii.Default()
+	t.Cleanup(func() { pii.SetDefault(previous) })
+	keyring, err := pii.NewKeyring(map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}, "k1", bytes.Repeat([]byte{2}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pii.SetDefault(keyring)
+
+	repo := NewAuthRepository(db)
+	newUser := func(username, email, phone string) *models.User {
+		return &models.User{TenantID: 1, Username: username, Email: email, PhoneNumber: pii.EncryptedString(phone), FullName: "Test", Password: "hash"}
+	}
+	ctx := context.Background()
+	if err := repo.RegisterUser(ctx, newUser("budi", "budi@example.com", "+6281234567890"), 0, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		user *models.User
+		want []string
+	}{
+		{"email case", newUser("andi", "BUDI@example.com", "+6281111111111"), []string{"email"}},
+		{"confusable username", newUser("BÚDI", "andi@example.com", "+6281111111111"), []string{"username"}},
+		{"phone", newUser("andi", "andi@example.com", "+6281234567890"), []string{"phone_number"}},
+		{"all fields", newUser("budi", "budi@example.com", "+6281234567890"), []string{"email", "username", "phone_number"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := repo.RegisterUser(ctx, tt.user, 0, nil)
+			var conflict *constants.ConflictError
+			if !errors.As(err, &conflict) {
+				t.Fatalf("RegisterUser() error = %v, want a ConflictError", err)
+			}
+			if !reflect.DeepEqual(conflict.Fields, tt.want) {
+				t.Errorf("conflicting fields = %v, want %v", conflict.Fields, tt.want)
+			}
+			if !errors.Is(err, constants.ErrConflict) {
+				t.Error("ConflictError does not match constants.ErrConflict")
+			}
+		})
+	}
+
+	// The failed attempts left nothing behind
+	if err := repo.RegisterUser(ctx, newUser("andi", "andi@example.com", "+6281111111111"), 0, nil); err != nil {
+		t.Errorf("RegisterUser() without conflicts = %v", err)
+	}
+}
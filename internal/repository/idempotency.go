@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve stores key unless the same key is already stored, replacing one
+// that expired or was abandoned before staleBefore. It reports whether key
+// was stored; otherwise the stored key is returned.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, key *models.IdempotencyKey, staleBefore time.Time) (bool, *models.IdempotencyKey, error) {
+	var existing *models.IdempotencyKey
+	reserved := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		same := tx.Where("tenant_id = ? AND scope = ? AND principal = ? AND key = ?", key.TenantID, key.Scope, key.Principal, key.Key).Session(&gorm.Session{})
+
+		err := same.
+			Where("expires_at <= ? OR (completed_at IS NULL AND created_at <= ?)", time.Now(), staleBefore).
+			Delete(&models.IdempotencyKey{}).Error
+		if err != nil {
+			return err
+		}
+
+		// A concurrent retry holding the key makes the insert a no-op
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			reserved = true
+			return nil
+		}
+
+		var stored models.IdempotencyKey
+		if err := same.First(&stored).Error; err != nil {
+			return err
+		}
+		existing = &stored
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The other request released the key meanwhile, let the client retry
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	return reserved, existing, nil
+}
+
+// Complete stores the response of a reserved key
+func (r *IdempotencyRepository) Complete(ctx context.Context, key *models.IdempotencyKey) error {
+	return r.db.WithContext(ctx).
+		Model(&models.IdempotencyKey{}).
+		Where("id = ?", key.ID).
+		Updates(map[string]interface{}{
+			"status_code":   key.StatusCode,
+			"content_type":  key.ContentType,
+			"response_body": key.ResponseBody,
+			"completed_at":  key.CompletedAt,
+		}).Error
+}
+
+// Delete removes a key so the request can be retried
+func (r *IdempotencyRepository) Delete(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.IdempotencyKey{}).Error
+}
+
+// DeleteExpired removes keys whose response is no longer replayed
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{})
+	return int(result.RowsAffected), result.Error
+}
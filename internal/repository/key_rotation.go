@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/pii"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// needsReencryption matches users whose personal data is not sealed with the
+// active master key, including plaintext rows from before encryption, or
+// that miss the phone blind index. It takes the active prefix three times.
+const needsReencryption = "NOT starts_with(phone_number, ?) OR phone_number_index IS NULL OR " +
+	"(address <> '' AND NOT starts_with(address, ?)) OR " +
+	"(dob IS NOT NULL AND NOT starts_with(dob, ?))"
+
+type KeyRotationRepository struct {
+	db *gorm.DB
+}
+
+func NewKeyRotationRepository(db *gorm.DB) *KeyRotationRepository {
+	return &KeyRotationRepository{db: db}
+}
+
+// FindUsersToReencrypt returns, in order, the IDs above afterID of up to
+// limit users that need re-encryption. Nothing is decrypted, so a row that
+// cannot be decrypted does not fail the lookup.
+func (r *KeyRotationRepository) FindUsersToReencrypt(ctx context.Context, activePrefix string, afterID, limit int) ([]int, error) {
+	var ids []int
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id > ?", afterID).
+		Where(needsReencryption, activePrefix, activePrefix, activePrefix).
+		Order("id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// ReencryptUser re-encrypts the personal data of one user with the active
+// master key and fills its phone blind index, in its own transaction. It
+// reports false when the user no longer needs it or is locked by another
+// replica rotating concurrently.
+func (r *KeyRotationRepository) ReencryptUser(ctx context.Context, activePrefix string, id int) (bool, error) {
+	rotated := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var users []models.User
+		err := tx.Select("id", "phone_number", "phone_number_index", "address", "dob").
+			Where("id = ?", id).
+			Where(needsReencryption, activePrefix, activePrefix, activePrefix).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Find(&users).Error
+		if err != nil || len(users) == 0 {
+			return err
+		}
+		user := users[0]
+
+		// Writing the decrypted values back seals them with the active key.
+		// UpdateColumns keeps updated_at untouched.
+		err = tx.Model(&models.User{}).
+			Where("id = ?", user.ID).
+			UpdateColumns(map[string]interface{}{
+				"phone_number":       user.PhoneNumber,
+				"phone_number_index": pii.BlindIndex(string(user.PhoneNumber)),
+				"address":            user.Address,
+				"dob":                user.Dob,
+			}).Error
+		if err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
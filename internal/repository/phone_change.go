@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/pii"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrPhoneChangeNotPending is returned when the request was already used or cancelled
+	ErrPhoneChangeNotPending = errors.New("phone change request is not pending")
+	// ErrPhoneTaken is returned when the target phone number is used by another account
+	ErrPhoneTaken = errors.New("phone number already registered")
+)
+
+type PhoneChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewPhoneChangeRepository(db *gorm.DB) *PhoneChangeRepository {
+	return &PhoneChangeRepository{db: db}
+}
+
+// Create stores a new request and cancels older pending requests of the user
+func (r *PhoneChangeRepository) Create(ctx context.Context, request *models.PhoneChangeRequest) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.PhoneChangeRequest{}).
+			Where("user_id = ? AND status = ?", request.UserID, models.PhoneChangePending).
+			Update("status", models.PhoneChangeCancelled).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(request).Error
+	})
+}
+
+// FindPendingByUserID finds the latest pending request of a user
+func (r *PhoneChangeRepository) FindPendingByUserID(ctx context.Context, userID int) (*models.PhoneChangeRequest, error) {
+	var request models.PhoneChangeRequest
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND status = ?", userID, models.PhoneChangePending).
+		Order("created_at DESC").
+		First(&request).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// IncrementAttempts records a wrong OTP
+func (r *PhoneChangeRepository) IncrementAttempts(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).
+		Model(&models.PhoneChangeRequest{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+// Apply switches the user to the new phone number. The unique index on users
+// is the final uniqueness check, so a concurrent registration of the same
+// number makes the whole change fail.
+func (r *PhoneChangeRepository) Apply(ctx context.Context, request *models.PhoneChangeRequest) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.PhoneChangeRequest{}).
+			Where("id = ? AND status = ? AND expires_at > ?", request.ID, models.PhoneChangePending, now).
+			Updates(map[string]interface{}{
+				"status":       models.PhoneChangeConfirmed,
+				"confirmed_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPhoneChangeNotPending
+		}
+
+		result = tx.Model(&models.User{}).
+			Where("id = ? AND phone_number_index = ?", request.UserID, pii.BlindIndex(request.OldPhone)).
+			Updates(map[string]interface{}{
+				"phone_number":       pii.EncryptedString(request.NewPhone),
+				"phone_number_index": pii.BlindIndex(request.NewPhone),
+			})
+		if result.Error != nil {
+			if _, ok := uniqueViolation(result.Error); ok {
+				return ErrPhoneTaken
+			}
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// The phone number was changed by another request in the meantime
+			return ErrPhoneChangeNotPending
+		}
+
+		request.Status = models.PhoneChangeConfirmed
+		request.ConfirmedAt = &now
+		return nil
+	})
+}
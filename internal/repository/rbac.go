@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RBACRepository struct {
+	db *gorm.DB
+}
+
+func NewRBACRepository(db *gorm.DB) *RBACRepository {
+	return &RBACRepository{db: db}
+}
+
+// ListRoles lists all roles with their permissions
+func (r *RBACRepository) ListRoles(ctx context.Context) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Order("id").Find(&roles).Error
+	return roles, err
+}
+
+// FindRoleByName finds role by name
+func (r *RBACRepository) FindRoleByName(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Where("name = ?", name).First(&role).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+// ListPermissions lists all permissions
+func (r *RBACRepository) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := r.db.WithContext(ctx).Order("name").Find(&permissions).Error
+	return permissions, err
+}
+
+// FindPermissionsByNames finds permissions matching the given names
+func (r *RBACRepository) FindPermissionsByNames(ctx context.Context, names []string) ([]models.Permission, error) {
+	var permissions []models.Permission
+	if len(names) == 0 {
+		return permissions, nil
+	}
+	err := r.db.WithContext(ctx).Where("name IN ?", names).Find(&permissions).Error
+	return permissions, err
+}
+
+// CreatePermission creates a new permission
+func (r *RBACRepository) CreatePermission(ctx context.Context, permission *models.Permission) error {
+	return r.db.WithContext(ctx).Create(permission).Error
+}
+
+// ReplaceRolePermissions replaces every permission of a role
+func (r *RBACRepository) ReplaceRolePermissions(ctx context.Context, role *models.Role, permissions []models.Permission) error {
+	return r.db.WithContext(ctx).Model(role).Association("Permissions").Replace(permissions)
+}
+
+// AddRolePermission grants a permission to a role
+func (r *RBACRepository) AddRolePermission(ctx context.Context, role *models.Role, permission *models.Permission) error {
+	return r.db.WithContext(ctx).Model(role).Association("Permissions").Append(permission)
+}
+
+// RemoveRolePermission revokes a permission from a role
+func (r *RBACRepository) RemoveRolePermission(ctx context.Context, role *models.Role, permission *models.Permission) error {
+	return r.db.WithContext(ctx).Model(role).Association("Permissions").Delete(permission)
+}
+
+// FindRolesByUserID finds the roles assigned to a user with their permissions
+func (r *RBACRepository) FindRolesByUserID(ctx context.Context, userID int) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.WithContext(ctx).
+		Preload("Permissions").
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.id").
+		Find(&roles).Error
+	return roles, err
+}
+
+// AssignRole assigns a role to a user, assigning an existing role is a no-op
+func (r *RBACRepository) AssignRole(ctx context.Context, userID, roleID int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.UserRole{UserID: userID, RoleID: roleID}).Error
+		if err != nil {
+			return err
+		}
+		return syncLegacyRole(tx, userID)
+	})
+}
+
+// RemoveRole removes a role from a user
+func (r *RBACRepository) RemoveRole(ctx context.Context, userID, roleID int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND role_id = ?", userID, roleID).
+			Delete(&models.UserRole{}).Error
+		if err != nil {
+			return err
+		}
+		return syncLegacyRole(tx, userID)
+	})
+}
+
+// SetRole replaces every role of a user with roleID and returns the roles
+// that were removed
+func (r *RBACRepository) SetRole(ctx context.Context, userID, roleID int) ([]models.Role, error) {
+	var removed []models.Role
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the user so concurrent role changes apply one after the other
+		if err := tx.Exec("SELECT id FROM users WHERE id = ? FOR UPDATE", userID).Error; err != nil {
+			return err
+		}
+
+		err := tx.Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+			Where("user_roles.user_id = ? AND roles.id <> ?", userID, roleID).
+			Order("roles.id").
+			Find(&removed).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("user_id = ? AND role_id <> ?", userID, roleID).
+			Delete(&models.UserRole{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.UserRole{UserID: userID, RoleID: roleID}).Error
+		if err != nil {
+			return err
+		}
+		return syncLegacyRole(tx, userID)
+	})
+	return removed, err
+}
+
+// syncLegacyRole sets users.role to the user's most recently assigned role,
+// or empty when none is left so it no longer acts as a fallback role
+func syncLegacyRole(tx *gorm.DB, userID int) error {
+	return tx.Exec(`UPDATE users SET role = COALESCE((
+		SELECT roles.name FROM user_roles JOIN roles ON roles.id = user_roles.role_id
+		WHERE user_roles.user_id = users.id
+		ORDER BY user_roles.created_at DESC, roles.id DESC
+		LIMIT 1
+	), '') WHERE id = ?`, userID).Error
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrApplicationNotPending is returned when reviewing an already reviewed application
+var ErrApplicationNotPending = errors.New("seller application is not pending")
+
+type SellerRepository struct {
+	db *gorm.DB
+}
+
+func NewSellerRepository(db *gorm.DB) *SellerRepository {
+	return &SellerRepository{db: db}
+}
+
+// CreateApplication creates an application together with its documents
+func (r *SellerRepository) CreateApplication(ctx context.Context, application *models.SellerApplication) error {
+	return r.db.WithContext(ctx).Create(application).Error
+}
+
+// FindApplicationByID finds application by ID within a tenant
+func (r *SellerRepository) FindApplicationByID(ctx context.Context, tenantID, id int) (*models.SellerApplication, error) {
+	var application models.SellerApplication
+	err := r.db.WithContext(ctx).
+		Preload("Documents", "deleted_at IS NULL").
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&application).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &application, nil
+}
+
+// FindLatestApplicationByUserID finds the most recent application of a user
+func (r *SellerRepository) FindLatestApplicationByUserID(ctx context.Context, userID int) (*models.SellerApplication, error) {
+	var application models.SellerApplication
+	err := r.db.WithContext(ctx).
+		Preload("Documents", "deleted_at IS NULL").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		First(&application).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &application, nil
+}
+
+// ListApplications lists applications of a tenant, optionally filtered by status
+func (r *SellerRepository) ListApplications(ctx context.Context, tenantID int, status string) ([]models.SellerApplication, error) {
+	var applications []models.SellerApplication
+	query := r.db.WithContext(ctx).Preload("Documents", "deleted_at IS NULL").Where("tenant_id = ?", tenantID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at").Find(&applications).Error
+	return applications, err
+}
+
+// ReviewApplication stores the review decision of a pending application. A
+// grantRoleID other than 0 is assigned to the applicant in the same
+// transaction, keeping users.role in sync.
+func (r *SellerRepository) ReviewApplication(ctx context.Context, application *models.SellerApplication, grantRoleID int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.SellerApplication{}).
+			Where("id = ? AND status = ?", application.ID, models.SellerApplicationPending).
+			Updates(map[string]interface{}{
+				"status":         application.Status,
+				"reviewer_id":    application.ReviewerID,
+				"reviewer_notes": application.ReviewerNotes,
+				"reviewed_at":    application.ReviewedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrApplicationNotPending
+		}
+
+		if grantRoleID == 0 {
+			return nil
+		}
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.UserRole{UserID: application.UserID, RoleID: grantRoleID}).Error
+		if err != nil {
+			return err
+		}
+		return syncLegacyRole(tx, application.UserID)
+	})
+}
+
+// FindDeletedDocuments finds documents of deleted accounts whose files have
+// not been removed yet
+func (r *SellerRepository) FindDeletedDocuments(ctx context.Context, limit int) ([]models.SellerDocument, error) {
+	var documents []models.SellerDocument
+	err := r.db.WithContext(ctx).
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at").
+		Limit(limit).
+		Find(&documents).Error
+	return documents, err
+}
+
+// DeleteDocument deletes a document row once its file is gone
+func (r *SellerRepository) DeleteDocument(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Delete(&models.SellerDocument{}, id).Error
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"gorm.io/gorm"
+)
+
+type TenantRepository struct {
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) *TenantRepository {
+	return &TenantRepository{db: db}
+}
+
+// FindByID finds tenant by ID
+func (r *TenantRepository) FindByID(ctx context.Context, id int) (*models.Tenant, error) {
+	return r.findOne(ctx, "id = ?", id)
+}
+
+// FindBySlug finds tenant by slug
+func (r *TenantRepository) FindBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	return r.findOne(ctx, "slug = ?", slug)
+}
+
+// FindByDomain finds tenant by its storefront host name
+func (r *TenantRepository) FindByDomain(ctx context.Context, domain string) (*models.Tenant, error) {
+	return r.findOne(ctx, "domain = ?", domain)
+}
+
+func (r *TenantRepository) findOne(ctx context.Context, query string, args ...interface{}) (*models.Tenant, error) {
+	var tenant models.Tenant
+	err := r.db.WithContext(ctx).Where(query, args...).First(&tenant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tenant, nil
+}
@@ -0,0 +1,145 @@
+// Package server runs the HTTP server with production timeouts, optional TLS
+// and graceful shutdown.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/config"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/worker"
+	"github.com/sirupsen/logrus"
+)
+
+// Server serves HTTP requests and runs the background workers next to them
+type Server struct {
+	cfg     config.HTTPConfig
+	handler http.Handler
+	workers []*worker.Worker
+	logger  *logrus.Logger
+
+	onShutdown []func()
+}
+
+func New(cfg config.HTTPConfig, handler http.Handler, workers []*worker.Worker, logger *logrus.Logger) *Server {
+	return &Server{
+		cfg:     cfg,
+		handler: handler,
+		workers: workers,
+		logger:  logger,
+	}
+}
+
+// OnShutdown registers fn to be called as soon as shutdown starts, before
+// the shutdown delay and the drain
+func (s *Server) OnShutdown(fn func()) {
+	s.onShutdown = append(s.onShutdown, fn)
+}
+
+// Run serves until ctx is cancelled, then stops accepting connections and
+// waits up to the shutdown timeout for in-flight requests and running jobs
+func (s *Server) Run(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              ":" + strconv.Itoa(s.cfg.Port),
+		Handler:           s.handler,
+		ReadTimeout:       s.cfg.ReadTimeout,
+		ReadHeaderTimeout: s.cfg.ReadHeaderTimeout,
+		WriteTimeout:      s.cfg.WriteTimeout,
+		IdleTimeout:       s.cfg.IdleTimeout,
+		MaxHeaderBytes:    s.cfg.MaxHeaderBytes,
+		ErrorLog:          newErrorLog(s.logger),
+	}
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	if s.cfg.TLS() {
+		certs, err := newCertReloader(s.cfg.TLSCertFile, s.cfg.TLSKeyFile, s.logger)
+		if err != nil {
+			return err
+		}
+		go certs.Watch(workerCtx, s.cfg.TLSReloadInterval)
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}
+	}
+
+	var jobs sync.WaitGroup
+	for _, w := range s.workers {
+		jobs.Add(1)
+		go func(w *worker.Worker) {
+			defer jobs.Done()
+			w.Run(workerCtx)
+		}(w)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		s.logger.WithFields(logrus.Fields{"addr": srv.Addr, "tls": s.cfg.TLS()}).Info("HTTP server started")
+		var err error
+		if s.cfg.TLS() {
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		serveErr <- err
+	}()
+
+	select {
+	case err := <-serveErr:
+		stopWorkers()
+		jobs.Wait()
+		return err
+	case <-ctx.Done():
+	}
+
+	for _, fn := range s.onShutdown {
+		fn()
+	}
+	if s.cfg.ShutdownDelay > 0 {
+		s.logger.WithField("delay", s.cfg.ShutdownDelay).Info("Shutting down, waiting for load balancers to stop routing")
+		time.Sleep(s.cfg.ShutdownDelay)
+	}
+
+	s.logger.WithField("timeout", s.cfg.ShutdownTimeout).Info("Shutting down, draining in-flight requests")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
+
+	// Workers are cancelled together with the drain so a running batch stops
+	// at its next checkpoint instead of holding the shutdown
+	stopWorkers()
+	err := srv.Shutdown(shutdownCtx)
+	if err != nil {
+		s.logger.WithError(err).Warn("HTTP server did not drain before the shutdown timeout")
+		srv.Close()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		jobs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-shutdownCtx.Done():
+		s.logger.Warn("Background workers did not stop before the shutdown timeout")
+	}
+
+	if serveErr := <-serveErr; serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+		return serveErr
+	}
+	return err
+}
+
+// newErrorLog routes net/http's own errors, such as TLS handshake failures,
+// through the application logger
+func newErrorLog(logger *logrus.Logger) *log.Logger {
+	return log.New(logger.WriterLevel(logrus.WarnLevel), "", 0)
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/mailer"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/repository"
+)
+
+// accountDeletionBatchSize limits how many accounts one purge run anonymizes
+const accountDeletionBatchSize = 100
+
+type AccountDeletionService struct {
+	authRepo     interfaces.IAuthRepository
+	deletionRepo interfaces.IAccountDeletionRepository
+	users        interfaces.IUserLifecycleService
+	mailer       interfaces.IMailer
+	gracePeriod  time.Duration
+}
+
+func NewAccountDeletionService(authRepo interfaces.IAuthRepository, deletionRepo interfaces.IAccountDeletionRepository, users interfaces.IUserLifecycleService, mailer interfaces.IMailer, gracePeriod time.Duration) interfaces.IAccountDeletionService {
+	return &AccountDeletionService{
+		authRepo:     authRepo,
+		deletionRepo: deletionRepo,
+		users:        users,
+		mailer:       mailer,
+		gracePeriod:  gracePeriod,
+	}
+}
+
+// RequestDeletion re-authenticates the user and schedules the account for
+// deletion after the grace period. Logging in before then cancels it.
+func (s *AccountDeletionService) RequestDeletion(ctx context.Context, userID int, req *dto.DeleteAccountRequest) (*dto.DeleteAccountResponse, error) {
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to find user").Wrap(err)
+	}
+	if user == nil || user.AnonymizedAt != nil {
+		return nil, helpers.NewCodeError(helpers.CodeUserNotFound, "User not found")
+	}
+
+	if err := helpers.ComparePassword(ctx, user.Password, req.Password); err != nil {
+		return nil, helpers.NewCodeError(helpers.CodeAuthPasswordIncorrect, "Invalid password")
+	}
+
+	scheduledAt := time.Now().Add(s.gracePeriod)
+	if user.DeletionScheduledAt != nil {
+		scheduledAt = *user.DeletionScheduledAt
+	}
+
+	if err := s.users.ScheduleDeletion(ctx, user, scheduledAt); err != nil {
+		return nil, err
+	}
+
+	locale := i18n.ForUser(ctx, user.Locale)
+	if err := s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: i18n.Translate(locale, "Your account is scheduled for deletion"),
+		Body: i18n.Translate(locale, "Hi %s,\n\nYour account and personal data will be permanently deleted on %s.\n\nChanged your mind? Just log in before then and the deletion will be cancelled.\n",
+			user.FullName, i18n.FormatDateTime(locale, scheduledAt)),
+	}); err != nil {
+		helpers.Log(ctx).WithError(err).Error("Failed to send account deletion notice")
+	}
+
+	return &dto.DeleteAccountResponse{
+		Message:     i18n.T(ctx, "Your account will be deleted. Log in before the scheduled time to cancel"),
+		ScheduledAt: scheduledAt,
+	}, nil
+}
+
+// PurgeDue deletes accounts whose grace period has ended through the user
+// lifecycle, returning how many were anonymized
+func (s *AccountDeletionService) PurgeDue(ctx context.Context) (int, error) {
+	users, err := s.deletionRepo.FindDue(ctx, time.Now(), accountDeletionBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for i := range users {
+		user := &users[i]
+		if err := s.users.Delete(ctx, user); err != nil {
+			if errors.Is(err, repository.ErrDeletionCancelled) {
+				continue
+			}
+			return purged, err
+		}
+		purged++
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+)
+
+const maxAddressesPerUser = 20
+
+type AddressService struct {
+	addressRepo interfaces.IAddressRepository
+}
+
+func NewAddressService(addressRepo interfaces.IAddressRepository) interfaces.IAddressService {
+	return &AddressService{
+		addressRepo: addressRepo,
+	}
+}
+
+// List lists the user's address book
+func (s *AddressService) List(ctx context.Context, userID int) ([]dto.AddressResponse, error) {
+	addresses, err := s.addressRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to list addresses").Wrap(err)
+	}
+
+	response := make([]dto.AddressResponse, 0, len(addresses))
+	for i := range addresses {
+		response = append(response, toAddressResponse(&addresses[i]))
+	}
+
+	return response, nil
+}
+
+// Get retrieves a single address
+func (s *AddressService) Get(ctx context.Context, userID, id int) (*dto.AddressResponse, error) {
+	address, err := s.findAddress(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toAddressResponse(address)
+	return &response, nil
+}
+
+// Create adds an address. The first address becomes the default shipping and billing address.
+func (s *AddressService) Create(ctx context.Context, userID int, req *dto.AddressRequest) (*dto.AddressResponse, error) {
+	count, err := s.addressRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to count addresses").Wrap(err)
+	}
+	if count >= maxAddressesPerUser {
+		return nil, helpers.ErrBadRequest("Address book is full")
+	}
+
+	address := &models.Address{
+		UserID:            userID,
+		Label:             req.Label,
+		RecipientName:     req.RecipientName,
+		PhoneNumber:       helpers.NormalizePhoneOrRaw(req.PhoneNumber),
+		AddressLine:       req.AddressLine,
+		Province:          req.Province,
+		City:              req.City,
+		District:          req.District,
+		PostalCode:        req.PostalCode,
+		Latitude:          req.Latitude,
+		Longitude:         req.Longitude,
+		IsDefaultShipping: req.IsDefaultShipping || count == 0,
+		IsDefaultBilling:  req.IsDefaultBilling || count == 0,
+	}
+
+	if err := s.addressRepo.Save(ctx, address); err != nil {
+		return nil, helpers.ErrInternalServer("Failed to create address").Wrap(err)
+	}
+
+	response := toAddressResponse(address)
+	return &response, nil
+}
+
+// Update applies a partial update to an address
+func (s *AddressService) Update(ctx context.Context, userID, id int, req *dto.UpdateAddressRequest) (*dto.AddressResponse, error) {
+	address, err := s.findAddress(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	setString(&address.Label, req.Label)
+	setString(&address.RecipientName, req.RecipientName)
+	setString(&address.PhoneNumber, req.PhoneNumber)
+	address.PhoneNumber = helpers.NormalizePhoneOrRaw(address.PhoneNumber)
+	setString(&address.AddressLine, req.AddressLine)
+	setString(&address.Province, req.Province)
+	setString(&address.City, req.City)
+	setString(&address.District, req.District)
+	setString(&address.PostalCode, req.PostalCode)
+	if req.Latitude != nil {
+		address.Latitude = req.Latitude
+	}
+	if req.Longitude != nil {
+		address.Longitude = req.Longitude
+	}
+
+	// A default can only be moved to another address, not unset
+	if req.IsDefaultShipping != nil && *req.IsDefaultShipping {
+		address.IsDefaultShipping = true
+	}
+	if req.IsDefaultBilling != nil && *req.IsDefaultBilling {
+		address.IsDefaultBilling = true
+	}
+
+	if err := s.addressRepo.Save(ctx, address); err != nil {
+		return nil, helpers.ErrInternalServer("Failed to update address").Wrap(err)
+	}
+
+	response := toAddressResponse(address)
+	return &response, nil
+}
+
+// Delete removes an address
+func (s *AddressService) Delete(ctx context.Context, userID, id int) error {
+	address, err := s.findAddress(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.addressRepo.Delete(ctx, address); err != nil {
+		return helpers.ErrInternalServer("Failed to delete address").Wrap(err)
+	}
+
+	return nil
+}
+
+func (s *AddressService) findAddress(ctx context.Context, userID, id int) (*models.Address, error) {
+	address, err := s.addressRepo.FindByID(ctx, userID, id)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to find address").Wrap(err)
+	}
+	if address == nil {
+		return nil, helpers.ErrNotFound("Address not found")
+	}
+	return address, nil
+}
+
+func setString(dst *string, value *string) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+func toAddressResponse(address *models.Address) dto.AddressResponse {
+	return dto.AddressResponse{
+		ID:                address.ID,
+		Label:             address.Label,
+		RecipientName:     address.RecipientName,
+		PhoneNumber:       address.PhoneNumber,
+		AddressLine:       address.AddressLine,
+		Province:          address.Province,
+		City:              address.City,
+		District:          address.District,
+		PostalCode:        address.PostalCode,
+		Latitude:          address.Latitude,
+		Longitude:         address.Longitude,
+		IsDefaultShipping: address.IsDefaultShipping,
+		IsDefaultBilling:  address.IsDefaultBilling,
+		CreatedAt:         address.CreatedAt,
+		UpdatedAt:         address.UpdatedAt,
+	}
+}
@@ -2,102 +2,98 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"time"
 
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/constants"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/metrics"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/pii"
+	"github.com/sirupsen/logrus"
 )
 
 type AuthService struct {
-	authRepo interfaces.IAuthRepository
+	authRepo    interfaces.IAuthRepository
+	rbacRepo    interfaces.IRBACRepository
+	consentRepo interfaces.IConsentRepository
+	users       interfaces.IUserLifecycleService
 }
 
-func NewAuthService(authRepo interfaces.IAuthRepository) interfaces.IAuthService {
+func NewAuthService(authRepo interfaces.IAuthRepository, rbacRepo interfaces.IRBACRepository, consentRepo interfaces.IConsentRepository, users interfaces.IUserLifecycleService) interfaces.IAuthService {
 	return &AuthService{
-		authRepo: authRepo,
+		authRepo:    authRepo,
+		rbacRepo:    rbacRepo,
+		consentRepo: consentRepo,
+		users:       users,
 	}
 }
 
 // Register handles user registration
 func (s *AuthService) Register(ctx context.Context, req *dto.RegisterRequest) (*dto.AuthResponse, error) {
-	// Check if email already exists
-	existingUser, err := s.authRepo.FindByEmail(ctx, req.Email)
+	tenant, err := tenantFromContext(ctx)
 	if err != nil {
-		return nil, helpers.ErrInternalServer("Failed to check email")
-	}
-	if existingUser != nil {
-		return nil, helpers.ErrConflict("Email already registered")
+		return nil, err
 	}
 
-	// Check if username already exists
-	existingUser, err = s.authRepo.FindByUsername(ctx, req.Username)
+	// The current terms of service and privacy policy must be accepted
+	documents, err := s.consentRepo.FindCurrentDocuments(ctx, tenant.ID)
 	if err != nil {
-		return nil, helpers.ErrInternalServer("Failed to check username")
-	}
-	if existingUser != nil {
-		return nil, helpers.ErrConflict("Username already taken")
+		return nil, helpers.ErrInternalServer("Failed to find legal documents").Wrap(err)
 	}
-
-	// Check if phone already exists
-	existPhone, err := s.authRepo.FindByPhone(ctx, req.PhoneNumber)
+	consents, err := documentConsents(documents, map[string]string{
+		constants.DocumentTermsOfService: req.TermsVersion,
+		constants.DocumentPrivacyPolicy:  req.PrivacyVersion,
+	}, true)
 	if err != nil {
-		return nil, helpers.ErrInternalServer("Failed to check phone")
-	}
-
-	if existPhone != nil {
-		return nil, helpers.ErrConflict("Phone already exist")
-	}
-
-	// Hash password
-	hashedPassword, err := helpers.HashPassword(req.Password)
-	if err != nil {
-		return nil, helpers.ErrInternalServer("Failed to hash password")
+		return nil, err
 	}
+	consents = append(consents, marketingConsents(&req.MarketingEmail, &req.MarketingSMS)...)
 
 	// Parse DOB if provided
 	var dob *time.Time
 	if req.Dob != "" {
 		parsedDob, err := time.Parse("2006-01-02", req.Dob)
 		if err != nil {
-			return nil, helpers.ErrBadRequest("Invalid date format for DOB. Use YYYY-MM-DD")
+			return nil, helpers.NewCodeError(helpers.CodeValidation, "Invalid date format for DOB. Use YYYY-MM-DD").WithField("dob", "must be YYYY-MM-DD")
 		}
 		dob = &parsedDob
 	}
 
 	// Create user
-	user := &models.User{
-		Username:      req.Username,
-		Email:         req.Email,
-		PhoneNumber:   req.PhoneNumber,
-		FullName:      req.FullName,
-		Address:       req.Address,
-		Dob:           dob,
-		Password:      hashedPassword,
-		Role:          "user",
-		EmailVerified: false,
-		IsActive:      true,
-	}
-
-	if err := s.authRepo.CreateUser(ctx, user); err != nil {
-		return nil, helpers.ErrInternalServer("Failed to create user")
+	stampConsents(consents, tenant.ID, 0, req.IPAddress, req.UserAgent)
+	user, err := s.users.Create(ctx, tenant, &dto.CreateUserInput{
+		Username:    req.Username,
+		Email:       req.Email,
+		PhoneNumber: req.PhoneNumber,
+		FullName:    req.FullName,
+		Password:    req.Password,
+		Address:     req.Address,
+		Dob:         dob,
+		Role:        constants.RoleCustomer,
+		Locale:      explicitLocale(ctx),
+		Consents:    consents,
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Generate tokens
-	accessToken, accessExpiry, err := helpers.GenerateAccessToken(user.ID, user.Email, user.Username, user.Role)
+	accessToken, accessExpiry, err := s.generateAccessToken(ctx, user)
 	if err != nil {
-		return nil, helpers.ErrInternalServer("Failed to generate access token")
+		return nil, helpers.ErrInternalServer("Failed to generate access token").Wrap(err)
 	}
 
-	refreshToken, refreshExpiry, err := helpers.GenerateRefreshToken(user.ID)
+	refreshToken, refreshExpiry, err := s.generateRefreshToken(ctx, user)
 	if err != nil {
-		return nil, helpers.ErrInternalServer("Failed to generate refresh token")
+		return nil, helpers.ErrInternalServer("Failed to generate refresh token").Wrap(err)
 	}
 
 	// Save session
 	session := &models.UserSession{
+		TenantID:            user.TenantID,
 		UserID:              user.ID,
 		Token:               accessToken,
 		RefreshToken:        refreshToken,
@@ -106,8 +102,9 @@ func (s *AuthService) Register(ctx context.Context, req *dto.RegisterRequest) (*
 	}
 
 	if err := s.authRepo.CreateSession(ctx, session); err != nil {
-		return nil, helpers.ErrInternalServer("Failed to create session")
+		return nil, helpers.ErrInternalServer("Failed to create session").Wrap(err)
 	}
+	metrics.Registrations.Inc()
 
 	// Prepare response
 	response := &dto.AuthResponse{
@@ -115,11 +112,12 @@ func (s *AuthService) Register(ctx context.Context, req *dto.RegisterRequest) (*
 			ID:          user.ID,
 			Username:    user.Username,
 			Email:       user.Email,
-			PhoneNumber: user.PhoneNumber,
+			PhoneNumber: string(user.PhoneNumber),
 			FullName:    user.FullName,
-			Address:     user.Address,
-			Dob:         user.Dob,
+			Address:     string(user.Address),
+			Dob:         user.Dob.TimePtr(),
 			Role:        user.Role,
+			Locale:      user.Locale,
 			CreatedAt:   user.CreatedAt,
 		},
 		AccessToken:  accessToken,
@@ -132,40 +130,54 @@ func (s *AuthService) Register(ctx context.Context, req *dto.RegisterRequest) (*
 
 // Login handles user login
 func (s *AuthService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.AuthResponse, error) {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Find user by email or username
-	user, err := s.authRepo.FindByEmailOrUsername(ctx, req.EmailOrUsername)
+	user, err := s.authRepo.FindByEmailOrUsername(ctx, tenant.ID, req.EmailOrUsername)
 	if err != nil {
-		return nil, helpers.ErrInternalServer("Failed to find user")
+		return nil, helpers.ErrInternalServer("Failed to find user").Wrap(err)
 	}
 	if user == nil {
-		return nil, helpers.ErrUnauthorized("Invalid credentials")
+		loginFailed(metrics.LoginReasonUnknownUser)
+		return nil, helpers.NewCodeError(helpers.CodeAuthInvalidCredentials, "Invalid credentials")
 	}
 
 	// Check if user is active
 	if !user.IsActive {
-		return nil, helpers.ErrUnauthorized("Account is deactivated")
+		loginFailed(metrics.LoginReasonInactive)
+		return nil, helpers.NewCodeError(helpers.CodeAuthAccountInactive, "Account is deactivated")
 	}
 
 	// Verify password
-	if err := helpers.ComparePassword(user.Password, req.Password); err != nil {
-		return nil, helpers.ErrUnauthorized("Invalid credentials")
+	if err := helpers.ComparePassword(ctx, user.Password, req.Password); err != nil {
+		loginFailed(metrics.LoginReasonInvalidPassword)
+		return nil, helpers.NewCodeError(helpers.CodeAuthInvalidCredentials, "Invalid credentials")
+	}
+
+	// Logging in during the grace period cancels a pending account deletion
+	if err := s.users.CancelDeletion(ctx, user); err != nil {
+		return nil, err
 	}
 
 	// Generate tokens
-	accessToken, accessExpiry, err := helpers.GenerateAccessToken(user.ID, user.Email, user.Username, user.Role)
+	accessToken, accessExpiry, err := s.generateAccessToken(ctx, user)
 	if err != nil {
-		return nil, helpers.ErrInternalServer("Failed to generate access token")
+		return nil, helpers.ErrInternalServer("Failed to generate access token").Wrap(err)
 	}
 
-	refreshToken, refreshExpiry, err := helpers.GenerateRefreshToken(user.ID)
+	refreshToken, refreshExpiry, err := s.generateRefreshToken(ctx, user)
 	if err != nil {
-		return nil, helpers.ErrInternalServer("Failed to generate refresh token")
+		return nil, helpers.ErrInternalServer("Failed to generate refresh token").Wrap(err)
 	}
 
 	// Delete old sessions and create new one
 	_ = s.authRepo.DeleteSessionsByUserID(ctx, user.ID)
 
 	session := &models.UserSession{
+		TenantID:            user.TenantID,
 		UserID:              user.ID,
 		Token:               accessToken,
 		RefreshToken:        refreshToken,
@@ -174,8 +186,9 @@ func (s *AuthService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.Au
 	}
 
 	if err := s.authRepo.CreateSession(ctx, session); err != nil {
-		return nil, helpers.ErrInternalServer("Failed to create session")
+		return nil, helpers.ErrInternalServer("Failed to create session").Wrap(err)
 	}
+	metrics.LoginAttempts.WithLabelValues("success").Inc()
 
 	// Prepare response
 	response := &dto.AuthResponse{
@@ -183,11 +196,12 @@ func (s *AuthService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.Au
 			ID:          user.ID,
 			Username:    user.Username,
 			Email:       user.Email,
-			PhoneNumber: user.PhoneNumber,
+			PhoneNumber: string(user.PhoneNumber),
 			FullName:    user.FullName,
-			Address:     user.Address,
-			Dob:         user.Dob,
+			Address:     string(user.Address),
+			Dob:         user.Dob.TimePtr(),
 			Role:        user.Role,
+			Locale:      user.Locale,
 			CreatedAt:   user.CreatedAt,
 		},
 		AccessToken:  accessToken,
@@ -195,54 +209,85 @@ func (s *AuthService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.Au
 		ExpiresAt:    accessExpiry,
 	}
 
+	// Flag newer terms of service or privacy policy the user has to accept
+	pending, err := s.pendingDocuments(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	response.ReconsentRequired = len(pending) > 0
+	response.PendingDocuments = toLegalDocumentResponses(pending)
+
 	return response, nil
 }
 
+// pendingDocuments returns the current legal documents the user has not accepted
+func (s *AuthService) pendingDocuments(ctx context.Context, user *models.User) ([]models.LegalDocument, error) {
+	documents, err := s.consentRepo.FindCurrentDocuments(ctx, user.TenantID)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to find legal documents").Wrap(err)
+	}
+	consents, err := s.consentRepo.FindLatestConsents(ctx, user.ID)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to find consents").Wrap(err)
+	}
+	return pendingDocuments(documents, consents), nil
+}
+
 // RefreshToken handles token refresh
 func (s *AuthService) RefreshToken(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.AuthResponse, error) {
-	// Validate refresh token
-	claims, err := helpers.ValidateRefreshToken(req.RefreshToken)
+	tenant, err := tenantFromContext(ctx)
 	if err != nil {
-		return nil, helpers.ErrUnauthorized("Invalid refresh token")
+		return nil, err
+	}
+
+	// Validate refresh token
+	claims, err := helpers.ValidateRefreshToken(req.RefreshToken, tenant.JWTSecret)
+	if err != nil || claims.TenantID != tenant.ID {
+		metrics.TokenRefreshes.WithLabelValues("failure").Inc()
+		return nil, helpers.NewCodeError(helpers.CodeAuthRefreshTokenInvalid, "Invalid refresh token")
 	}
 
 	// Find session
 	session, err := s.authRepo.FindSessionByUserID(ctx, claims.UserID)
 
 	if err != nil {
-		return nil, helpers.ErrInternalServer("Failed to find session")
+		return nil, helpers.ErrInternalServer("Failed to find session").Wrap(err)
 	}
 	if session == nil || session.RefreshToken != req.RefreshToken {
-		return nil, helpers.ErrUnauthorized("Invalid refresh token")
+		metrics.TokenRefreshes.WithLabelValues("failure").Inc()
+		return nil, helpers.NewCodeError(helpers.CodeAuthRefreshTokenInvalid, "Invalid refresh token")
 	}
 
 	// Check if refresh token is expired
 	if time.Now().After(session.RefreshTokenExpired) {
-		return nil, helpers.ErrUnauthorized("Refresh token expired")
+		metrics.TokenRefreshes.WithLabelValues("failure").Inc()
+		return nil, helpers.NewCodeError(helpers.CodeAuthRefreshTokenExpired, "Refresh token expired")
 	}
 
 	// Get user details
 	user, err := s.authRepo.FindByID(ctx, claims.UserID)
 	if err != nil {
-		return nil, helpers.ErrInternalServer("Failed to find user")
+		return nil, helpers.ErrInternalServer("Failed to find user").Wrap(err)
 	}
 	if user == nil {
-		return nil, helpers.ErrUnauthorized("User not found")
+		metrics.TokenRefreshes.WithLabelValues("failure").Inc()
+		return nil, helpers.NewCodeError(helpers.CodeAuthRefreshTokenInvalid, "User not found")
 	}
 
 	if !user.IsActive {
-		return nil, helpers.ErrUnauthorized("Account is deactivated")
+		metrics.TokenRefreshes.WithLabelValues("failure").Inc()
+		return nil, helpers.NewCodeError(helpers.CodeAuthAccountInactive, "Account is deactivated")
 	}
 
 	// Generate new tokens
-	accessToken, accessExpiry, err := helpers.GenerateAccessToken(user.ID, user.Email, user.Username, user.Role)
+	accessToken, accessExpiry, err := s.generateAccessToken(ctx, user)
 	if err != nil {
-		return nil, helpers.ErrInternalServer("Failed to generate access token")
+		return nil, helpers.ErrInternalServer("Failed to generate access token").Wrap(err)
 	}
 
-	newRefreshToken, refreshExpiry, err := helpers.GenerateRefreshToken(user.ID)
+	newRefreshToken, refreshExpiry, err := s.generateRefreshToken(ctx, user)
 	if err != nil {
-		return nil, helpers.ErrInternalServer("Failed to generate refresh token")
+		return nil, helpers.ErrInternalServer("Failed to generate refresh token").Wrap(err)
 	}
 
 	// Update session
@@ -252,8 +297,9 @@ func (s *AuthService) RefreshToken(ctx context.Context, req *dto.RefreshTokenReq
 	session.RefreshTokenExpired = refreshExpiry
 
 	if err := s.authRepo.UpdateSession(ctx, session); err != nil {
-		return nil, helpers.ErrInternalServer("Failed to update session")
+		return nil, helpers.ErrInternalServer("Failed to update session").Wrap(err)
 	}
+	metrics.TokenRefreshes.WithLabelValues("success").Inc()
 
 	// Prepare response
 	response := &dto.AuthResponse{
@@ -261,11 +307,12 @@ func (s *AuthService) RefreshToken(ctx context.Context, req *dto.RefreshTokenReq
 			ID:          user.ID,
 			Username:    user.Username,
 			Email:       user.Email,
-			PhoneNumber: user.PhoneNumber,
+			PhoneNumber: string(user.PhoneNumber),
 			FullName:    user.FullName,
-			Address:     user.Address,
-			Dob:         user.Dob,
+			Address:     string(user.Address),
+			Dob:         user.Dob.TimePtr(),
 			Role:        user.Role,
+			Locale:      user.Locale,
 			CreatedAt:   user.CreatedAt,
 		},
 		AccessToken:  accessToken,
@@ -278,10 +325,15 @@ func (s *AuthService) RefreshToken(ctx context.Context, req *dto.RefreshTokenReq
 
 // ForgotPassword handles password reset request
 func (s *AuthService) ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest) error {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
 	// Find user by email
-	user, err := s.authRepo.FindByEmail(ctx, req.Email)
+	user, err := s.authRepo.FindByEmail(ctx, tenant.ID, req.Email)
 	if err != nil {
-		return helpers.ErrInternalServer("Failed to find user")
+		return helpers.ErrInternalServer("Failed to find user").Wrap(err)
 	}
 	if user == nil {
 		// Don't reveal if email exists or not
@@ -291,82 +343,105 @@ func (s *AuthService) ForgotPassword(ctx context.Context, req *dto.ForgotPasswor
 	// Generate reset token
 	resetToken, err := helpers.GenerateRandomToken(32)
 	if err != nil {
-		return helpers.ErrInternalServer("Failed to generate reset token")
+		return helpers.ErrInternalServer("Failed to generate reset token").Wrap(err)
 	}
 
-	// Set expiry time (1 hour)
-	expiry := time.Now().Add(1 * time.Hour).Format(time.RFC3339)
+	expiry := time.Now().Add(helpers.Config.Auth.ResetTokenTTL).Format(time.RFC3339)
 
 	// Save reset token
 	if err := s.authRepo.SaveResetToken(ctx, user.ID, resetToken, expiry); err != nil {
-		return helpers.ErrInternalServer("Failed to save reset token")
+		return helpers.ErrInternalServer("Failed to save reset token").Wrap(err)
 	}
+	metrics.PasswordResets.WithLabelValues("requested").Inc()
 
 	// TODO: Send email with reset token
-	// For now, we'll just log it (in production, use email service)
-	fmt.Printf("Password reset token for %s: %s\n", user.Email, resetToken)
+	// For now, we'll just log it (in production, use email service). The
+	// token is masked unless LOG_REDACT=false.
+	helpers.Log(ctx).WithFields(logrus.Fields{
+		"user_id": user.ID,
+		"token":   resetToken,
+	}).Info("Password reset token issued")
 
 	return nil
 }
 
 // ResetPassword handles password reset
 func (s *AuthService) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := checkPasswordPolicy(tenant, req.NewPassword); err != nil {
+		return err
+	}
+
 	// Find user by reset token
-	user, err := s.authRepo.FindByResetToken(ctx, req.Token)
+	user, err := s.authRepo.FindByResetToken(ctx, tenant.ID, req.Token)
 	if err != nil {
-		return helpers.ErrInternalServer("Failed to verify reset token")
+		return helpers.ErrInternalServer("Failed to verify reset token").Wrap(err)
 	}
 	if user == nil {
-		return helpers.ErrBadRequest("Invalid or expired reset token")
+		return helpers.NewCodeError(helpers.CodeAuthResetTokenInvalid, "Invalid or expired reset token")
 	}
 
 	// Hash new password
-	hashedPassword, err := helpers.HashPassword(req.NewPassword)
+	hashedPassword, err := helpers.HashPassword(ctx, req.NewPassword)
 	if err != nil {
-		return helpers.ErrInternalServer("Failed to hash password")
+		return helpers.ErrInternalServer("Failed to hash password").Wrap(err)
 	}
 
 	// Update password
 	if err := s.authRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
-		return helpers.ErrInternalServer("Failed to update password")
+		return helpers.ErrInternalServer("Failed to update password").Wrap(err)
 	}
 
 	// Clear reset token
 	if err := s.authRepo.ClearResetToken(ctx, user.ID); err != nil {
-		return helpers.ErrInternalServer("Failed to clear reset token")
+		return helpers.ErrInternalServer("Failed to clear reset token").Wrap(err)
 	}
 
 	// Delete all sessions (force re-login)
 	_ = s.authRepo.DeleteSessionsByUserID(ctx, user.ID)
+	metrics.PasswordResets.WithLabelValues("completed").Inc()
 
 	return nil
 }
 
 // ChangePassword handles password change for authenticated user
 func (s *AuthService) ChangePassword(ctx context.Context, userID int, req *dto.ChangePasswordRequest) error {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := checkPasswordPolicy(tenant, req.NewPassword); err != nil {
+		return err
+	}
+
 	// Get user
 	user, err := s.authRepo.FindByID(ctx, userID)
 	if err != nil {
-		return helpers.ErrInternalServer("Failed to find user")
+		return helpers.ErrInternalServer("Failed to find user").Wrap(err)
 	}
 	if user == nil {
-		return helpers.ErrNotFound("User not found")
+		return helpers.NewCodeError(helpers.CodeUserNotFound, "User not found")
 	}
 
 	// Verify old password
-	if err := helpers.ComparePassword(user.Password, req.OldPassword); err != nil {
-		return helpers.ErrBadRequest("Invalid old password")
+	if err := helpers.ComparePassword(ctx, user.Password, req.OldPassword); err != nil {
+		return helpers.NewCodeError(helpers.CodeAuthPasswordIncorrect, "Invalid old password")
 	}
 
 	// Hash new password
-	hashedPassword, err := helpers.HashPassword(req.NewPassword)
+	hashedPassword, err := helpers.HashPassword(ctx, req.NewPassword)
 	if err != nil {
-		return helpers.ErrInternalServer("Failed to hash password")
+		return helpers.ErrInternalServer("Failed to hash password").Wrap(err)
 	}
 
 	// Update password
 	if err := s.authRepo.UpdatePassword(ctx, userID, hashedPassword); err != nil {
-		return helpers.ErrInternalServer("Failed to update password")
+		return helpers.ErrInternalServer("Failed to update password").Wrap(err)
 	}
 
 	// Delete all sessions except current (force re-login on other devices)
@@ -379,7 +454,7 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID int, req *dto.C
 func (s *AuthService) Logout(ctx context.Context, userID int, token string) error {
 	// Delete session by token
 	if err := s.authRepo.DeleteSession(ctx, token); err != nil {
-		return helpers.ErrInternalServer("Failed to logout")
+		return helpers.ErrInternalServer("Failed to logout").Wrap(err)
 	}
 
 	return nil
@@ -389,23 +464,111 @@ func (s *AuthService) Logout(ctx context.Context, userID int, token string) erro
 func (s *AuthService) GetProfile(ctx context.Context, userID int) (*dto.UserResponse, error) {
 	user, err := s.authRepo.FindByID(ctx, userID)
 	if err != nil {
-		return nil, helpers.ErrInternalServer("Failed to find user")
+		return nil, helpers.ErrInternalServer("Failed to find user").Wrap(err)
 	}
 	if user == nil {
-		return nil, helpers.ErrNotFound("User not found")
+		return nil, helpers.NewCodeError(helpers.CodeUserNotFound, "User not found")
 	}
 
 	response := &dto.UserResponse{
 		ID:          user.ID,
 		Username:    user.Username,
 		Email:       user.Email,
-		PhoneNumber: user.PhoneNumber,
+		PhoneNumber: string(user.PhoneNumber),
 		FullName:    user.FullName,
-		Address:     user.Address,
-		Dob:         user.Dob,
+		Address:     string(user.Address),
+		Dob:         user.Dob.TimePtr(),
 		Role:        user.Role,
+		Locale:      user.Locale,
 		CreatedAt:   user.CreatedAt,
 	}
 
 	return response, nil
 }
+
+// UpdateProfile updates the editable profile fields of the user
+func (s *AuthService) UpdateProfile(ctx context.Context, userID int, req *dto.UpdateProfileRequest) (*dto.UserResponse, error) {
+	updates := map[string]interface{}{}
+	if req.FullName != nil {
+		updates["full_name"] = *req.FullName
+	}
+	if req.Address != nil {
+		updates["address"] = pii.EncryptedString(*req.Address)
+	}
+	if req.Dob != nil {
+		if *req.Dob == "" {
+			updates["dob"] = nil
+		} else {
+			parsedDob, err := time.Parse("2006-01-02", *req.Dob)
+			if err != nil {
+				return nil, helpers.NewCodeError(helpers.CodeValidation, "Invalid date format for DOB. Use YYYY-MM-DD").WithField("dob", "must be YYYY-MM-DD")
+			}
+			if parsedDob.After(time.Now()) {
+				return nil, helpers.NewCodeError(helpers.CodeValidation, "DOB cannot be in the future").WithField("dob", "cannot be in the future")
+			}
+			updates["dob"] = pii.EncryptedDate{Time: parsedDob}
+		}
+	}
+
+	if req.Locale != nil {
+		updates["locale"] = *req.Locale
+	}
+
+	if len(updates) == 0 {
+		return nil, helpers.ErrBadRequest("No fields to update")
+	}
+
+	if err := s.authRepo.UpdateProfile(ctx, userID, updates); err != nil {
+		return nil, helpers.ErrInternalServer("Failed to update profile").Wrap(err)
+	}
+
+	return s.GetProfile(ctx, userID)
+}
+
+// generateAccessToken issues an access token carrying the user's roles and permissions
+func (s *AuthService) generateAccessToken(ctx context.Context, user *models.User) (string, time.Time, error) {
+	roles, permissions, err := resolveAccess(ctx, s.rbacRepo, user)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return helpers.GenerateAccessToken(helpers.TokenSubject{
+		UserID:      user.ID,
+		TenantID:    user.TenantID,
+		Email:       user.Email,
+		Username:    user.Username,
+		Role:        user.Role,
+		Locale:      user.Locale,
+		Roles:       roles,
+		Permissions: permissions,
+		SigningKey:  signingKey(ctx),
+	})
+}
+
+// generateRefreshToken issues a refresh token signed with the tenant key
+func (s *AuthService) generateRefreshToken(ctx context.Context, user *models.User) (string, time.Time, error) {
+	return helpers.GenerateRefreshToken(user.ID, user.TenantID, signingKey(ctx))
+}
+
+// signingKey returns the JWT key of the request tenant, empty means JWT_SECRET
+func signingKey(ctx context.Context) string {
+	if tenant := helpers.TenantFromContext(ctx); tenant != nil {
+		return tenant.JWTSecret
+	}
+	return ""
+}
+
+// explicitLocale returns the language the client asked for with
+// Accept-Language, saved as the preference of new accounts
+func explicitLocale(ctx context.Context) string {
+	if !i18n.IsExplicit(ctx) {
+		return ""
+	}
+	return string(i18n.FromContext(ctx))
+}
+
+// loginFailed counts a rejected login by reason
+func loginFailed(reason string) {
+	metrics.LoginAttempts.WithLabelValues("failure").Inc()
+	metrics.LoginFailures.WithLabelValues(reason).Inc()
+}
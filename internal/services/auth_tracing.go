@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/ibnuzaman/auth-simple-ecommerce.git/internal/services")
+
+// TracedAuthService wraps an auth service with a span per method. Request
+// fields are not recorded, they hold credentials and personal data.
+type TracedAuthService struct {
+	next interfaces.IAuthService
+}
+
+func NewTracedAuthService(next interfaces.IAuthService) interfaces.IAuthService {
+	return &TracedAuthService{next: next}
+}
+
+func (s *TracedAuthService) Register(ctx context.Context, req *dto.RegisterRequest) (*dto.AuthResponse, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.Register")
+	defer span.End()
+	response, err := s.next.Register(ctx, req)
+	recordError(span, err)
+	return response, err
+}
+
+func (s *TracedAuthService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.AuthResponse, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.Login")
+	defer span.End()
+	response, err := s.next.Login(ctx, req)
+	recordError(span, err)
+	return response, err
+}
+
+func (s *TracedAuthService) RefreshToken(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.AuthResponse, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.RefreshToken")
+	defer span.End()
+	response, err := s.next.RefreshToken(ctx, req)
+	recordError(span, err)
+	return response, err
+}
+
+func (s *TracedAuthService) ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest) error {
+	ctx, span := tracer.Start(ctx, "AuthService.ForgotPassword")
+	defer span.End()
+	err := s.next.ForgotPassword(ctx, req)
+	recordError(span, err)
+	return err
+}
+
+func (s *TracedAuthService) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error {
+	ctx, span := tracer.Start(ctx, "AuthService.ResetPassword")
+	defer span.End()
+	err := s.next.ResetPassword(ctx, req)
+	recordError(span, err)
+	return err
+}
+
+func (s *TracedAuthService) ChangePassword(ctx context.Context, userID int, req *dto.ChangePasswordRequest) error {
+	ctx, span := tracer.Start(ctx, "AuthService.ChangePassword", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+	err := s.next.ChangePassword(ctx, userID, req)
+	recordError(span, err)
+	return err
+}
+
+func (s *TracedAuthService) Logout(ctx context.Context, userID int, token string) error {
+	ctx, span := tracer.Start(ctx, "AuthService.Logout", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+	err := s.next.Logout(ctx, userID, token)
+	recordError(span, err)
+	return err
+}
+
+func (s *TracedAuthService) GetProfile(ctx context.Context, userID int) (*dto.UserResponse, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.GetProfile", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+	response, err := s.next.GetProfile(ctx, userID)
+	recordError(span, err)
+	return response, err
+}
+
+func (s *TracedAuthService) UpdateProfile(ctx context.Context, userID int, req *dto.UpdateProfileRequest) (*dto.UserResponse, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.UpdateProfile", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+	response, err := s.next.UpdateProfile(ctx, userID, req)
+	recordError(span, err)
+	return response, err
+}
+
+// recordError marks the span failed on server errors. Client errors such as
+// invalid credentials are expected outcomes and only noted as an attribute.
+func recordError(span trace.Span, err error) {
+	var appErr *helpers.AppError
+	if errors.As(err, &appErr) && appErr.Code < http.StatusInternalServerError {
+		span.SetAttributes(attribute.Int("app.error.code", appErr.Code))
+		return
+	}
+	helpers.RecordSpanError(span, err)
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/policy"
+	"github.com/sirupsen/logrus"
+)
+
+type AuthzService struct {
+	engine *policy.Engine
+}
+
+func NewAuthzService(engine *policy.Engine) interfaces.IAuthzService {
+	return &AuthzService{
+		engine: engine,
+	}
+}
+
+// Check evaluates the policies for the token subject and logs the decision
+func (s *AuthzService) Check(ctx context.Context, claims *helpers.JWTClaims, req *dto.AuthzCheckRequest) *dto.AuthzCheckResponse {
+	decision := s.engine.Evaluate(policy.Request{
+		Subject: map[string]any{
+			"user_id":     claims.UserID,
+			"tenant_id":   claims.TenantID,
+			"email":       claims.Email,
+			"username":    claims.Username,
+			"role":        claims.Role,
+			"roles":       claims.Roles,
+			"permissions": claims.Permissions,
+		},
+		Action: req.Action,
+		Resource: policy.Resource{
+			Type:       req.Resource.Type,
+			Attributes: req.Resource.Attributes,
+		},
+		Environment: req.Environment,
+	})
+
+	helpers.Log(ctx).WithFields(logrus.Fields{
+		"user_id":   claims.UserID,
+		"tenant_id": claims.TenantID,
+		"action":    req.Action,
+		"resource":  req.Resource.Type,
+		"effect":    decision.Effect,
+		"rule":      decision.RuleID,
+	}).Info("authorization decision")
+
+	return &dto.AuthzCheckResponse{
+		Allowed: decision.Allowed,
+		Effect:  decision.Effect,
+		Rule:    decision.RuleID,
+		Reason:  decision.Reason,
+	}
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/constants"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/repository"
+)
+
+const defaultMarketingConsentLimit = 100
+
+type ConsentService struct {
+	consentRepo interfaces.IConsentRepository
+}
+
+func NewConsentService(consentRepo interfaces.IConsentRepository) interfaces.IConsentService {
+	return &ConsentService{consentRepo: consentRepo}
+}
+
+// ListCurrentDocuments lists the current version of each legal document
+func (s *ConsentService) ListCurrentDocuments(ctx context.Context) ([]dto.LegalDocumentResponse, error) {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := s.consentRepo.FindCurrentDocuments(ctx, tenant.ID)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to find legal documents").Wrap(err)
+	}
+
+	return toLegalDocumentResponses(documents), nil
+}
+
+// PublishDocument publishes a new version. Users who accepted an older
+// version are asked to accept it on their next login.
+func (s *ConsentService) PublishDocument(ctx context.Context, req *dto.PublishLegalDocumentRequest) (*dto.LegalDocumentResponse, error) {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	document := &models.LegalDocument{
+		TenantID:    tenant.ID,
+		Type:        req.Type,
+		Version:     req.Version,
+		Title:       req.Title,
+		URL:         req.URL,
+		PublishedAt: time.Now(),
+	}
+	if err := s.consentRepo.CreateDocument(ctx, document); err != nil {
+		if errors.Is(err, repository.ErrDocumentVersionExists) {
+			return nil, helpers.ErrConflict("This version is already published")
+		}
+		return nil, helpers.ErrInternalServer("Failed to publish legal document").Wrap(err)
+	}
+
+	response := toLegalDocumentResponse(document)
+	return &response, nil
+}
+
+// GetStatus returns the user's accepted versions and marketing choices
+func (s *ConsentService) GetStatus(ctx context.Context, userID int) (*dto.ConsentStatusResponse, error) {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := s.consentRepo.FindCurrentDocuments(ctx, tenant.ID)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to find legal documents").Wrap(err)
+	}
+
+	consents, err := s.consentRepo.FindLatestConsents(ctx, userID)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to find consents").Wrap(err)
+	}
+
+	pending := pendingDocuments(documents, consents)
+	response := &dto.ConsentStatusResponse{
+		ReconsentRequired: len(pending) > 0,
+		PendingDocuments:  toLegalDocumentResponses(pending),
+		AcceptedVersions:  map[string]string{},
+	}
+	for _, consent := range consents {
+		switch consent.Type {
+		case constants.ConsentMarketingEmail:
+			response.MarketingEmail = consent.Granted
+		case constants.ConsentMarketingSMS:
+			response.MarketingSMS = consent.Granted
+		default:
+			if consent.Granted {
+				response.AcceptedVersions[consent.Type] = consent.DocumentVersion
+			}
+		}
+	}
+
+	return response, nil
+}
+
+// UpdateConsent records acceptance of the current legal documents and
+// changes to marketing consent
+func (s *ConsentService) UpdateConsent(ctx context.Context, userID int, req *dto.UpdateConsentRequest) (*dto.ConsentStatusResponse, error) {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := s.consentRepo.FindCurrentDocuments(ctx, tenant.ID)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to find legal documents").Wrap(err)
+	}
+
+	accepted := map[string]string{}
+	if req.TermsVersion != "" {
+		accepted[constants.DocumentTermsOfService] = req.TermsVersion
+	}
+	if req.PrivacyVersion != "" {
+		accepted[constants.DocumentPrivacyPolicy] = req.PrivacyVersion
+	}
+
+	consents, err := documentConsents(documents, accepted, false)
+	if err != nil {
+		return nil, err
+	}
+	consents = append(consents, marketingConsents(req.MarketingEmail, req.MarketingSMS)...)
+	if len(consents) == 0 {
+		return nil, helpers.ErrBadRequest("Nothing to update")
+	}
+
+	stampConsents(consents, tenant.ID, userID, req.IPAddress, req.UserAgent)
+	if err := s.consentRepo.CreateConsents(ctx, consents); err != nil {
+		return nil, helpers.ErrInternalServer("Failed to save consents").Wrap(err)
+	}
+
+	return s.GetStatus(ctx, userID)
+}
+
+// ListMarketingConsents lists current marketing choices for CRM sync
+func (s *ConsentService) ListMarketingConsents(ctx context.Context, query *dto.MarketingConsentQuery) ([]models.MarketingConsent, error) {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := interfaces.MarketingConsentFilter{
+		Types: []string{constants.ConsentMarketingEmail, constants.ConsentMarketingSMS},
+		Limit: defaultMarketingConsentLimit,
+	}
+	if query.Type != "" {
+		filter.Types = []string{query.Type}
+	}
+	if query.Granted != "" {
+		granted := query.Granted == "true"
+		filter.Granted = &granted
+	}
+	if query.UpdatedSince != "" {
+		since, err := time.Parse(time.RFC3339, query.UpdatedSince)
+		if err != nil {
+			return nil, helpers.ErrBadRequest("updated_since must be an RFC 3339 timestamp")
+		}
+		filter.UpdatedSince = &since
+	}
+	if query.Limit > 0 {
+		filter.Limit = query.Limit
+	}
+	if query.Page > 1 {
+		filter.Offset = (query.Page - 1) * filter.Limit
+	}
+
+	consents, err := s.consentRepo.ListMarketingConsents(ctx, tenant.ID, filter)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to list marketing consents").Wrap(err)
+	}
+	return consents, nil
+}
+
+// documentConsents builds acceptance records for the given document
+// versions. Only the current version can be accepted. With requireAll, every
+// published required document must be accepted.
+func documentConsents(documents []models.LegalDocument, accepted map[string]string, requireAll bool) ([]models.Consent, error) {
+	var consents []models.Consent
+	for _, document := range documents {
+		version, ok := accepted[document.Type]
+		if !ok {
+			if requireAll && isRequiredDocument(document.Type) {
+				return nil, helpers.NewCodeErrorf(helpers.CodeUserConsentRequired, "Please accept the current %s (version %s)", documentName(document.Type), document.Version)
+			}
+			continue
+		}
+		if version != document.Version {
+			return nil, helpers.NewCodeErrorf(helpers.CodeUserConsentRequired, "Please accept the current %s (version %s)", documentName(document.Type), document.Version)
+		}
+
+		documentID := document.ID
+		consents = append(consents, models.Consent{
+			Type:            document.Type,
+			DocumentID:      &documentID,
+			DocumentVersion: document.Version,
+			Granted:         true,
+		})
+	}
+	return consents, nil
+}
+
+// marketingConsents builds records for the marketing choices that were given
+func marketingConsents(email, sms *bool) []models.Consent {
+	var consents []models.Consent
+	if email != nil {
+		consents = append(consents, models.Consent{Type: constants.ConsentMarketingEmail, Granted: *email})
+	}
+	if sms != nil {
+		consents = append(consents, models.Consent{Type: constants.ConsentMarketingSMS, Granted: *sms})
+	}
+	return consents
+}
+
+// stampConsents sets who gave the consents and from where
+func stampConsents(consents []models.Consent, tenantID, userID int, ipAddress, userAgent string) {
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	for i := range consents {
+		consents[i].TenantID = tenantID
+		consents[i].UserID = userID
+		consents[i].IPAddress = ipAddress
+		consents[i].UserAgent = userAgent
+	}
+}
+
+// pendingDocuments returns the required documents whose current version the
+// user has not accepted
+func pendingDocuments(documents []models.LegalDocument, consents []models.Consent) []models.LegalDocument {
+	accepted := map[string]string{}
+	for _, consent := range consents {
+		if consent.Granted {
+			accepted[consent.Type] = consent.DocumentVersion
+		}
+	}
+
+	var pending []models.LegalDocument
+	for _, document := range documents {
+		if isRequiredDocument(document.Type) && accepted[document.Type] != document.Version {
+			pending = append(pending, document)
+		}
+	}
+	return pending
+}
+
+func isRequiredDocument(documentType string) bool {
+	for _, required := range constants.RequiredDocuments {
+		if required == documentType {
+			return true
+		}
+	}
+	return false
+}
+
+func documentName(documentType string) i18n.Message {
+	switch documentType {
+	case constants.DocumentTermsOfService:
+		return i18n.Message("terms of service")
+	case constants.DocumentPrivacyPolicy:
+		return i18n.Message("privacy policy")
+	default:
+		return i18n.Message(documentType)
+	}
+}
+
+func toLegalDocumentResponse(document *models.LegalDocument) dto.LegalDocumentResponse {
+	return dto.LegalDocumentResponse{
+		ID:          document.ID,
+		Type:        document.Type,
+		Version:     document.Version,
+		Title:       document.Title,
+		URL:         document.URL,
+		PublishedAt: document.PublishedAt,
+	}
+}
+
+func toLegalDocumentResponses(documents []models.LegalDocument) []dto.LegalDocumentResponse {
+	response := make([]dto.LegalDocumentResponse, len(documents))
+	for i := range documents {
+		response[i] = toLegalDocumentResponse(&documents[i])
+	}
+	return response
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/export"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/mailer"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/storage"
+)
+
+const (
+	// dataExportRetention is how long a generated archive is kept
+	dataExportRetention = 7 * 24 * time.Hour
+	// dataExportLinkTTL is how long a signed download link is valid
+	dataExportLinkTTL   = 24 * time.Hour
+	dataExportBatchSize = 10
+)
+
+type DataExportService struct {
+	exportRepo interfaces.IDataExportRepository
+	blobStore  interfaces.IBlobStore
+	mailer     interfaces.IMailer
+}
+
+func NewDataExportService(exportRepo interfaces.IDataExportRepository, blobStore interfaces.IBlobStore, mailer interfaces.IMailer) interfaces.IDataExportService {
+	return &DataExportService{
+		exportRepo: exportRepo,
+		blobStore:  blobStore,
+		mailer:     mailer,
+	}
+}
+
+// RequestExport queues a new export. It is generated in the background and
+// the user is emailed when it is ready.
+func (s *DataExportService) RequestExport(ctx context.Context, userID int) (*dto.DataExportResponse, error) {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := s.exportRepo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to check data exports").Wrap(err)
+	}
+	if active != nil {
+		return nil, helpers.ErrConflict("A data export is already being prepared")
+	}
+
+	dataExport := &models.DataExport{
+		TenantID: tenant.ID,
+		UserID:   userID,
+		Status:   models.DataExportPending,
+	}
+	if err := s.exportRepo.Create(ctx, dataExport); err != nil {
+		return nil, helpers.ErrInternalServer("Failed to request data export").Wrap(err)
+	}
+
+	response := toDataExportResponse(dataExport)
+	return &response, nil
+}
+
+// ListExports lists the user's exports with a fresh download link for the
+// ones that are ready
+func (s *DataExportService) ListExports(ctx context.Context, userID int) ([]dto.DataExportResponse, error) {
+	exports, err := s.exportRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to list data exports").Wrap(err)
+	}
+
+	response := make([]dto.DataExportResponse, len(exports))
+	for i := range exports {
+		response[i] = toDataExportResponse(&exports[i])
+	}
+	return response, nil
+}
+
+// Download opens the archive of a signed, unexpired download link
+func (s *DataExportService) Download(ctx context.Context, req *dto.DataExportDownloadRequest) (io.ReadCloser, string, error) {
+	if time.Now().Unix() > req.Expires || !helpers.VerifySignature(downloadSignatureValue(req.ID, req.Expires), req.Signature) {
+		return nil, "", helpers.ErrForbidden("Invalid or expired download link")
+	}
+
+	dataExport, err := s.exportRepo.FindByID(ctx, req.ID)
+	if err != nil {
+		return nil, "", helpers.ErrInternalServer("Failed to find data export").Wrap(err)
+	}
+	if dataExport == nil || dataExport.Status != models.DataExportReady {
+		return nil, "", helpers.ErrNotFound("Data export not found")
+	}
+
+	archive, err := s.blobStore.Get(ctx, dataExport.BlobKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, "", helpers.ErrNotFound("Data export not found")
+		}
+		return nil, "", helpers.ErrInternalServer("Failed to read data export").Wrap(err)
+	}
+
+	fileName := fmt.Sprintf("data-export-%s.zip", dataExport.CreatedAt.Format("20060102"))
+	return archive, fileName, nil
+}
+
+// ProcessPending generates queued exports and returns how many were generated
+func (s *DataExportService) ProcessPending(ctx context.Context) (int, error) {
+	exports, err := s.exportRepo.ClaimPending(ctx, dataExportBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	generated := 0
+	for i := range exports {
+		dataExport := &exports[i]
+		if err := s.generate(ctx, dataExport); err != nil {
+			helpers.Log(ctx).WithError(err).WithField("export_id", dataExport.ID).Error("Failed to generate data export")
+			if err := s.exportRepo.MarkFailed(ctx, dataExport.ID, err.Error()); err != nil {
+				return generated, err
+			}
+			continue
+		}
+		generated++
+	}
+
+	return generated, nil
+}
+
+// PurgeExpired removes archives past their retention and returns how many
+// were removed
+func (s *DataExportService) PurgeExpired(ctx context.Context) (int, error) {
+	exports, err := s.exportRepo.FindExpired(ctx, time.Now(), dataExportBatchSize*10)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, dataExport := range exports {
+		if err := s.blobStore.Delete(ctx, dataExport.BlobKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return i, err
+		}
+		if err := s.exportRepo.MarkExpired(ctx, dataExport.ID); err != nil {
+			return i, err
+		}
+	}
+
+	return len(exports), nil
+}
+
+func (s *DataExportService) generate(ctx context.Context, dataExport *models.DataExport) error {
+	data, err := s.exportRepo.FindUserData(ctx, dataExport.UserID)
+	if err != nil {
+		return fmt.Errorf("load user data: %w", err)
+	}
+
+	now := time.Now()
+	archive, err := export.BuildArchive(data, now)
+	if err != nil {
+		return fmt.Errorf("build archive: %w", err)
+	}
+
+	key := fmt.Sprintf("exports/%d/%d/%d.zip", dataExport.TenantID, dataExport.UserID, dataExport.ID)
+	if err := s.blobStore.Put(ctx, key, bytes.NewReader(archive)); err != nil {
+		return fmt.Errorf("store archive: %w", err)
+	}
+
+	expiresAt := now.Add(dataExportRetention)
+	dataExport.Status = models.DataExportReady
+	dataExport.BlobKey = key
+	dataExport.Size = int64(len(archive))
+	dataExport.CompletedAt = &now
+	dataExport.ExpiresAt = &expiresAt
+	if err := s.exportRepo.MarkReady(ctx, dataExport); err != nil {
+		return fmt.Errorf("mark ready: %w", err)
+	}
+
+	locale := i18n.ForUser(ctx, data.User.Locale)
+	if err := s.mailer.Send(ctx, mailer.Message{
+		To:      data.User.Email,
+		Subject: i18n.Translate(locale, "Your data export is ready"),
+		Body: i18n.Translate(locale, "Hi %s,\n\nThe export of your personal data is ready. Download it within 24 hours from:\n\n%s\n\nAfter that you can get a new link from your account settings until %s.\n",
+			data.User.FullName, dataExportDownloadURL(dataExport), i18n.FormatDate(locale, expiresAt)),
+	}); err != nil {
+		helpers.Log(ctx).WithError(err).WithField("export_id", dataExport.ID).Error("Failed to send data export email")
+	}
+
+	return nil
+}
+
+func toDataExportResponse(dataExport *models.DataExport) dto.DataExportResponse {
+	response := dto.DataExportResponse{
+		ID:          dataExport.ID,
+		Status:      dataExport.Status,
+		Size:        dataExport.Size,
+		CompletedAt: dataExport.CompletedAt,
+		ExpiresAt:   dataExport.ExpiresAt,
+		CreatedAt:   dataExport.CreatedAt,
+	}
+	if dataExport.Status == models.DataExportReady {
+		response.DownloadURL = dataExportDownloadURL(dataExport)
+	}
+	return response
+}
+
+// dataExportDownloadURL builds a signed download link that expires after
+// dataExportLinkTTL or with the archive, whichever is first
+func dataExportDownloadURL(dataExport *models.DataExport) string {
+	expires := time.Now().Add(dataExportLinkTTL)
+	if dataExport.ExpiresAt != nil && dataExport.ExpiresAt.Before(expires) {
+		expires = *dataExport.ExpiresAt
+	}
+
+	base := helpers.Config.App.APIURL
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	query.Set("signature", helpers.Sign(downloadSignatureValue(dataExport.ID, expires.Unix())))
+	return fmt.Sprintf("%s/v1/exports/%d/download?%s", strings.TrimRight(base, "/"), dataExport.ID, query.Encode())
+}
+
+func downloadSignatureValue(id int, expires int64) string {
+	return fmt.Sprintf("data-export:%d:%d", id, expires)
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/mailer"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/repository"
+)
+
+const (
+	emailChangeExpiry = 24 * time.Hour
+	// emailChangeRevertWindow is how long the old address can undo a confirmed change
+	emailChangeRevertWindow = 7 * 24 * time.Hour
+)
+
+type EmailChangeService struct {
+	authRepo        interfaces.IAuthRepository
+	emailChangeRepo interfaces.IEmailChangeRepository
+	mailer          interfaces.IMailer
+}
+
+func NewEmailChangeService(authRepo interfaces.IAuthRepository, emailChangeRepo interfaces.IEmailChangeRepository, mailer interfaces.IMailer) interfaces.IEmailChangeService {
+	return &EmailChangeService{
+		authRepo:        authRepo,
+		emailChangeRepo: emailChangeRepo,
+		mailer:          mailer,
+	}
+}
+
+// RequestChange verifies the password and sends a confirmation link to the new
+// address and a cancel link to the current one
+func (s *EmailChangeService) RequestChange(ctx context.Context, userID int, req *dto.RequestEmailChangeRequest) error {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return helpers.ErrInternalServer("Failed to find user").Wrap(err)
+	}
+	if user == nil {
+		return helpers.NewCodeError(helpers.CodeUserNotFound, "User not found")
+	}
+
+	if err := helpers.ComparePassword(ctx, user.Password, req.Password); err != nil {
+		return helpers.NewCodeError(helpers.CodeAuthPasswordIncorrect, "Invalid password")
+	}
+
+	if helpers.CanonicalEmail(user.Email) == helpers.CanonicalEmail(req.NewEmail) {
+		return helpers.ErrBadRequest("New email must be different from the current email")
+	}
+
+	existingUser, err := s.authRepo.FindByEmail(ctx, tenant.ID, req.NewEmail)
+	if err != nil {
+		return helpers.ErrInternalServer("Failed to check email").Wrap(err)
+	}
+	if existingUser != nil {
+		return helpers.NewCodeError(helpers.CodeUserEmailTaken, "Email already registered").WithField("email", "already in use")
+	}
+
+	confirmToken, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return helpers.ErrInternalServer("Failed to generate token").Wrap(err)
+	}
+	cancelToken, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return helpers.ErrInternalServer("Failed to generate token").Wrap(err)
+	}
+
+	request := &models.EmailChangeRequest{
+		TenantID:         tenant.ID,
+		UserID:           user.ID,
+		OldEmail:         user.Email,
+		NewEmail:         req.NewEmail,
+		ConfirmTokenHash: helpers.HashToken(confirmToken),
+		CancelTokenHash:  helpers.HashToken(cancelToken),
+		Status:           models.EmailChangePending,
+		ExpiresAt:        time.Now().Add(emailChangeExpiry),
+	}
+
+	if err := s.emailChangeRepo.Create(ctx, request); err != nil {
+		return helpers.ErrInternalServer("Failed to save email change request").Wrap(err)
+	}
+
+	locale := i18n.ForUser(ctx, user.Locale)
+	if err := s.mailer.Send(ctx, mailer.Message{
+		To:      request.NewEmail,
+		Subject: i18n.Translate(locale, "Confirm your new email address"),
+		Body: i18n.Translate(locale, "Hi %s,\n\nPlease confirm your new email address by opening the link below within 24 hours:\n\n%s\n\nIf you didn't request this, you can ignore this email.\n",
+			user.FullName, appLink("/account/email-change/confirm", confirmToken)),
+	}); err != nil {
+		return helpers.ErrInternalServer("Failed to send confirmation email").Wrap(err)
+	}
+
+	if err := s.mailer.Send(ctx, mailer.Message{
+		To:      request.OldEmail,
+		Subject: i18n.Translate(locale, "Your email address is being changed"),
+		Body: i18n.Translate(locale, "Hi %s,\n\nA request was made to change the email address of your account to %s.\n\nIf this wasn't you, open the link below to cancel the change and sign out every device:\n\n%s\n",
+			user.FullName, request.NewEmail, appLink("/account/email-change/cancel", cancelToken)),
+	}); err != nil {
+		helpers.Log(ctx).WithError(err).Error("Failed to send email change notice")
+	}
+
+	return nil
+}
+
+// Confirm applies a pending email change
+func (s *EmailChangeService) Confirm(ctx context.Context, req *dto.EmailChangeTokenRequest) error {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	request, err := s.emailChangeRepo.FindByConfirmTokenHash(ctx, tenant.ID, helpers.HashToken(req.Token))
+	if err != nil {
+		return helpers.ErrInternalServer("Failed to find email change request").Wrap(err)
+	}
+	if request == nil || request.Status != models.EmailChangePending || time.Now().After(request.ExpiresAt) {
+		return helpers.NewCodeError(helpers.CodeAuthLinkInvalid, "Invalid or expired email change link")
+	}
+
+	if err := s.emailChangeRepo.Apply(ctx, request); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrEmailTaken):
+			return helpers.NewCodeError(helpers.CodeUserEmailTaken, "Email already registered").WithField("email", "already in use")
+		case errors.Is(err, repository.ErrEmailChangeNotPending):
+			return helpers.NewCodeError(helpers.CodeAuthLinkInvalid, "Invalid or expired email change link")
+		}
+		return helpers.ErrInternalServer("Failed to change email").Wrap(err)
+	}
+
+	return nil
+}
+
+// Cancel cancels a pending change or, within the revert window, restores the
+// old email of a confirmed change
+func (s *EmailChangeService) Cancel(ctx context.Context, req *dto.EmailChangeTokenRequest) error {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	request, err := s.emailChangeRepo.FindByCancelTokenHash(ctx, tenant.ID, helpers.HashToken(req.Token))
+	if err != nil {
+		return helpers.ErrInternalServer("Failed to find email change request").Wrap(err)
+	}
+	if request == nil {
+		return helpers.NewCodeError(helpers.CodeAuthLinkInvalid, "Invalid or expired cancel link")
+	}
+
+	switch request.Status {
+	case models.EmailChangePending:
+		err = s.emailChangeRepo.Cancel(ctx, request)
+	case models.EmailChangeConfirmed:
+		if request.ConfirmedAt == nil || time.Since(*request.ConfirmedAt) > emailChangeRevertWindow {
+			return helpers.NewCodeError(helpers.CodeAuthLinkInvalid, "Invalid or expired cancel link")
+		}
+		err = s.emailChangeRepo.Revert(ctx, request)
+	default:
+		return helpers.NewCodeError(helpers.CodeAuthLinkInvalid, "Invalid or expired cancel link")
+	}
+
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrEmailTaken):
+			return helpers.ErrConflict("The previous email is now used by another account, please contact support")
+		case errors.Is(err, repository.ErrEmailChangeNotPending):
+			return helpers.NewCodeError(helpers.CodeAuthLinkInvalid, "Invalid or expired cancel link")
+		}
+		return helpers.ErrInternalServer("Failed to cancel email change").Wrap(err)
+	}
+
+	return nil
+}
+
+// appLink builds a link to the storefront for email actions
+func appLink(path, token string) string {
+	return strings.TrimRight(helpers.Config.App.URL, "/") + path + "?token=" + url.QueryEscape(token)
+}
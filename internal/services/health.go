@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+)
+
+const (
+	healthStatusUp           = "up"
+	healthStatusDown         = "down"
+	healthStatusDegraded     = "degraded"
+	healthStatusShuttingDown = "shutting_down"
+)
+
+// HealthCheck is a dependency of the service. An optional dependency being
+// down is reported but keeps the service ready, e.g. logins work without SMTP.
+type HealthCheck struct {
+	Name     string
+	Check    func(ctx context.Context) error
+	Optional bool
+}
+
+type HealthService struct {
+	checks       []HealthCheck
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewHealthService(timeout time.Duration, checks ...HealthCheck) interfaces.IHealthService {
+	return &HealthService{
+		checks:  checks,
+		timeout: timeout,
+	}
+}
+
+// Live reports the process is running. It does not look at dependencies, an
+// outage of Postgres must not make Kubernetes restart every replica.
+func (s *HealthService) Live(ctx context.Context) *dto.HealthResponse {
+	return &dto.HealthResponse{Status: healthStatusUp}
+}
+
+// Ready runs every check concurrently, each bounded by the timeout, and
+// reports whether the service can take traffic
+func (s *HealthService) Ready(ctx context.Context) (*dto.HealthResponse, bool) {
+	if s.shuttingDown.Load() {
+		return &dto.HealthResponse{Status: healthStatusShuttingDown}, false
+	}
+
+	components := make(map[string]dto.ComponentHealth, len(s.checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range s.checks {
+		wg.Add(1)
+		go func(check HealthCheck) {
+			defer wg.Done()
+			result := s.run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			components[check.Name] = result
+		}(check)
+	}
+	wg.Wait()
+
+	ready, status := true, healthStatusUp
+	for _, check := range s.checks {
+		if components[check.Name].Status == healthStatusUp {
+			continue
+		}
+		if check.Optional {
+			status = healthStatusDegraded
+			continue
+		}
+		ready = false
+	}
+	if !ready {
+		status = healthStatusDown
+	}
+	return &dto.HealthResponse{Status: status, Components: components}, ready
+}
+
+// SetShuttingDown makes readiness fail so load balancers stop routing new
+// requests before the server stops accepting them
+func (s *HealthService) SetShuttingDown() {
+	s.shuttingDown.Store(true)
+}
+
+func (s *HealthService) run(ctx context.Context, check HealthCheck) dto.ComponentHealth {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Check(ctx)
+	result := dto.ComponentHealth{
+		Status:    healthStatusUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = healthStatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
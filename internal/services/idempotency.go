@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+)
+
+type IdempotencyService struct {
+	idempotencyRepo interfaces.IIdempotencyRepository
+	ttl             time.Duration
+	// lockTimeout is how long a key may stay in progress, a request still
+	// running after it was cut off by the write timeout
+	lockTimeout time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepo interfaces.IIdempotencyRepository, ttl, lockTimeout time.Duration) interfaces.IIdempotencyService {
+	return &IdempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		ttl:             ttl,
+		lockTimeout:     lockTimeout,
+	}
+}
+
+// Begin reserves the key for the request. It returns nil when the request
+// should be processed, or the stored key whose response is to be replayed.
+func (s *IdempotencyService) Begin(ctx context.Context, key *models.IdempotencyKey) (*models.IdempotencyKey, error) {
+	now := time.Now()
+	key.ExpiresAt = now.Add(s.ttl)
+
+	reserved, existing, err := s.idempotencyRepo.Reserve(ctx, key, now.Add(-s.lockTimeout))
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to reserve idempotency key").Wrap(err)
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	switch {
+	case existing == nil || !existing.Completed() && existing.RequestHash == key.RequestHash:
+		return nil, helpers.NewCodeError(helpers.CodeIdempotencyKeyInProgress, "A request with this Idempotency-Key is still in progress")
+	case existing.RequestHash != key.RequestHash:
+		return nil, helpers.NewCodeError(helpers.CodeIdempotencyKeyReused, "This Idempotency-Key was already used with a different request")
+	}
+	return existing, nil
+}
+
+// Complete stores the response to replay for retries of the request
+func (s *IdempotencyService) Complete(ctx context.Context, key *models.IdempotencyKey) error {
+	now := time.Now()
+	key.CompletedAt = &now
+	return s.idempotencyRepo.Complete(ctx, key)
+}
+
+// Release forgets the key so a failed request can be retried with it
+func (s *IdempotencyService) Release(ctx context.Context, key *models.IdempotencyKey) error {
+	return s.idempotencyRepo.Delete(ctx, key.ID)
+}
+
+// PurgeExpired removes keys past their TTL
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) (int, error) {
+	return s.idempotencyRepo.DeleteExpired(ctx, time.Now())
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/pii"
+)
+
+// keyRotationBatchSize limits how many user IDs are looked up at once
+const keyRotationBatchSize = 200
+
+type KeyRotationService struct {
+	rotationRepo interfaces.IKeyRotationRepository
+}
+
+func NewKeyRotationService(rotationRepo interfaces.IKeyRotationRepository) interfaces.IKeyRotationService {
+	return &KeyRotationService{rotationRepo: rotationRepo}
+}
+
+// Rotate re-encrypts personal data sealed with an old master key, or not
+// encrypted yet, until every row uses the active key. Users are rewritten
+// one by one; a user that fails is skipped and reported in the result so
+// one bad row cannot stop the rotation of the others.
+func (s *KeyRotationService) Rotate(ctx context.Context) (*dto.KeyRotationResult, error) {
+	keyring := pii.Default()
+	if keyring == nil {
+		return nil, pii.ErrNoKeyring
+	}
+
+	result := &dto.KeyRotationResult{}
+	afterID := 0
+	for {
+		ids, err := s.rotationRepo.FindUsersToReencrypt(ctx, keyring.ActivePrefix(), afterID, keyRotationBatchSize)
+		if err != nil {
+			return result, err
+		}
+
+		for _, id := range ids {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			rotated, err := s.rotationRepo.ReencryptUser(ctx, keyring.ActivePrefix(), id)
+			if err != nil {
+				result.Failed = append(result.Failed, dto.KeyRotationFailure{UserID: id, Err: err})
+				continue
+			}
+			if rotated {
+				result.Rotated++
+			}
+		}
+
+		if len(ids) < keyRotationBatchSize {
+			return result, nil
+		}
+		afterID = ids[len(ids)-1]
+	}
+}
+
+// RotateAll runs Rotate for the background worker, logging the users that
+// could not be re-encrypted
+func (s *KeyRotationService) RotateAll(ctx context.Context) (int, error) {
+	result, err := s.Rotate(ctx)
+	if result == nil {
+		return 0, err
+	}
+
+	if len(result.Failed) > 0 {
+		userIDs := make([]int, len(result.Failed))
+		for i, failure := range result.Failed {
+			userIDs[i] = failure.UserID
+		}
+		helpers.Log(ctx).WithError(result.Failed[0].Err).WithField("user_ids", userIDs).
+			Error("Failed to re-encrypt users, run `keys rotate` for details")
+	}
+	return result.Rotated, err
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/migrator"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/pii"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/repository"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/testdb"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/migrations"
+	"github.com/sirupsen/logrus"
+)
+
+func testKeyring(t *testing.T, activeID string, ids ...string) *pii.Keyring {
+	t.Helper()
+	keys := map[string][]byte{}
+	for _, id := range ids {
+		keys[id] = bytes.Repeat([]byte(id[len(id)-1:]), 32)
+	}
+	keyring, err := pii.NewKeyring(keys, activeID, bytes.Repeat([]byte{9}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func useKeyring(t *testing.T, keyring *pii.Keyring) {
+	previous := pii.Default()
+	t.Cleanup(func() { pii.SetDefault(previous) })
+	pii.SetDefault(keyring)
+}
+
+// fakeKeyRotationRepo fails to re-encrypt the users in broken
+type fakeKeyRotationRepo struct {
+	pending []int
+	broken  map[int]bool
+}
+
+func (r *fakeKeyRotationRepo) FindUsersToReencrypt(_ context.Context, _ string, afterID, limit int) ([]int, error) {
+	var ids []int
+	for _, id := range r.pending {
+		if id > afterID && len(ids) < limit {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (r *fakeKeyRotationRepo) ReencryptUser(_ context.Context, _ string, id int) (bool, error) {
+	if r.broken[id] {
+		return false, errors.New("cipher: message authentication failed")
+	}
+	for i, pending := range r.pending {
+		if pending == id {
+			r.pending = append(r.pending[:i], r.pending[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestRotateSkipsFailingUsers(t *testing.T) {
+	useKeyring(t, testKeyring(t, "k1", "k1"))
+
+	// More users than a batch, with failures at the start of both batches
+	repo := &fakeKeyRotationRepo{broken: map[int]bool{1: true, keyRotationBatchSize + 1: true}}
+	for id := 1; id <= keyRotationBatchSize+50; id++ {
+		repo.pending = append(repo.pending, id)
+	}
+
+	result, err := NewKeyRotationService(repo).Rotate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Rotated != keyRotationBatchSize+48 {
+		t.Errorf("rotated %d users, want %d", result.Rotated, keyRotationBatchSize+48)
+	}
+	if len(result.Failed) != 2 || result.Failed[0].UserID != 1 || result.Failed[1].UserID != keyRotationBatchSize+1 {
+		t.Errorf("failed = %v, want users 1 and %d", result.Failed, keyRotationBatchSize+1)
+	}
+}
+
+func TestRotateMixedKeys(t *testing.T) {
+	db := testdb.Open(t)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	m, err := migrator.New(db, migrations.FS, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	retired := testKeyring(t, "k0", "k0")
+	old := testKeyring(t, "k1", "k1")
+	active := testKeyring(t, "k2", "k1", "k2")
+
+	create := func(keyring *pii.Keyring, name, phone string) int {
+		t.Helper()
+		useKeyring(t, keyring)
+		user := &models.User{
+			TenantID:          1,
+			Username:          name,
+			Email:             name + "@example.com",
+			EmailCanonical:    name + "@example.com",
+			UsernameCanonical: name,
+			PhoneNumber:       pii.EncryptedString(phone),
+			PhoneNumberIndex:  keyring.BlindIndex(phone),
+			Address:           "Jl. Merdeka 1",
+			FullName:          name,
+			Password:          "hash",
+		}
+		if err := db.Create(user).Error; err != nil {
+			t.Fatal(err)
+		}
+		return user.ID
+	}
+
+	// The undecryptable user comes first, rotation must carry on past it
+	broken := create(retired, "broken", "+6281200000001")
+	oldKey := create(old, "oldkey", "+6281200000002")
+	plaintext := create(old, "plain", "+6281200000003")
+	if err := db.Exec("UPDATE users SET phone_number = '+6281200000003', address = '', phone_number_index = NULL WHERE id = ?", plaintext).Error; err != nil {
+		t.Fatal(err)
+	}
+	current := create(active, "current", "+6281200000004")
+
+	service := NewKeyRotationService(repository.NewKeyRotationRepository(db))
+	result, err := service.Rotate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Rotated != 2 {
+		t.Errorf("rotated %d users, want the old key and the plaintext user", result.Rotated)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].UserID != broken {
+		t.Errorf("failed = %v, want user %d", result.Failed, broken)
+	}
+
+	for _, id := range []int{oldKey, plaintext, current} {
+		var row struct {
+			PhoneNumber      string
+			PhoneNumberIndex *string
+		}
+		if err := db.Table("users").Select("phone_number", "phone_number_index").Where("id = ?", id).Scan(&row).Error; err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(row.PhoneNumber, active.ActivePrefix()) || row.PhoneNumberIndex == nil {
+			t.Errorf("user %d: phone %q, index %v, want sealed with k2 and indexed", id, row.PhoneNumber, row.PhoneNumberIndex)
+		}
+	}
+
+	// A second run only reports the broken user again
+	result, err = service.Rotate(context.Background())
+	if err != nil || result.Rotated != 0 || len(result.Failed) != 1 {
+		t.Errorf("second run = %+v, %v, want only the broken user", result, err)
+	}
+}
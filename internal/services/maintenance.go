@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+)
+
+type MaintenanceService struct {
+	authRepo   interfaces.IAuthRepository
+	tenantRepo interfaces.ITenantRepository
+	users      interfaces.IUserLifecycleService
+}
+
+func NewMaintenanceService(authRepo interfaces.IAuthRepository, tenantRepo interfaces.ITenantRepository, users interfaces.IUserLifecycleService) interfaces.IMaintenanceService {
+	return &MaintenanceService{
+		authRepo:   authRepo,
+		tenantRepo: tenantRepo,
+		users:      users,
+	}
+}
+
+// CreateAdmin creates a verified admin account. It is the way to bootstrap
+// the first administrator of a tenant.
+func (s *MaintenanceService) CreateAdmin(ctx context.Context, req *dto.CreateAdminRequest) (*models.User, error) {
+	if err := helpers.GetValidator().Struct(req); err != nil {
+		return nil, helpers.ErrValidation(err.Error())
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, req.TenantID)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to find tenant").Wrap(err)
+	}
+	if tenant == nil {
+		return nil, helpers.ErrNotFound("Tenant not found")
+	}
+
+	return s.users.Create(ctx, tenant, &dto.CreateUserInput{
+		Username:      req.Username,
+		Email:         req.Email,
+		PhoneNumber:   req.PhoneNumber,
+		FullName:      req.FullName,
+		Password:      req.Password,
+		Role:          req.Role,
+		EmailVerified: true,
+	})
+}
+
+// SetRole replaces every role of the user with the given role
+func (s *MaintenanceService) SetRole(ctx context.Context, tenantID int, login, roleName string) (*models.User, error) {
+	user, err := s.findUser(ctx, tenantID, login)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.SetRole(ctx, user, roleName); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ActivateUser lets a deactivated user log in again
+func (s *MaintenanceService) ActivateUser(ctx context.Context, tenantID int, login string) (*models.User, error) {
+	user, err := s.findUser(ctx, tenantID, login)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.Activate(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeactivateUser blocks the user from logging in and revokes every session
+func (s *MaintenanceService) DeactivateUser(ctx context.Context, tenantID int, login string) (*models.User, error) {
+	user, err := s.findUser(ctx, tenantID, login)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.Deactivate(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// PurgeExpiredSessions deletes sessions that can no longer be refreshed
+func (s *MaintenanceService) PurgeExpiredSessions(ctx context.Context) (int, error) {
+	deleted, err := s.authRepo.DeleteExpiredSessions(ctx, time.Now())
+	if err != nil {
+		return 0, helpers.ErrInternalServer("Failed to purge sessions").Wrap(err)
+	}
+	return int(deleted), nil
+}
+
+// findUser finds a user of the tenant by numeric ID, email or username
+func (s *MaintenanceService) findUser(ctx context.Context, tenantID int, login string) (*models.User, error) {
+	if id, err := strconv.Atoi(login); err == nil {
+		return s.users.Find(ctx, tenantID, id)
+	}
+
+	user, err := s.authRepo.FindByEmailOrUsername(ctx, tenantID, login)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to find user").Wrap(err)
+	}
+	if user == nil || user.AnonymizedAt != nil {
+		return nil, helpers.NewCodeError(helpers.CodeUserNotFound, "User not found")
+	}
+	return user, nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/i18n"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/repository"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/sms"
+)
+
+const (
+	phoneChangeOTPDigits      = 6
+	phoneChangeOTPExpiry      = 10 * time.Minute
+	phoneChangeMaxAttempts    = 5
+	phoneChangeResendCooldown = time.Minute
+)
+
+type PhoneChangeService struct {
+	authRepo        interfaces.IAuthRepository
+	phoneChangeRepo interfaces.IPhoneChangeRepository
+	smsSender       interfaces.ISMSSender
+}
+
+func NewPhoneChangeService(authRepo interfaces.IAuthRepository, phoneChangeRepo interfaces.IPhoneChangeRepository, smsSender interfaces.ISMSSender) interfaces.IPhoneChangeService {
+	return &PhoneChangeService{
+		authRepo:        authRepo,
+		phoneChangeRepo: phoneChangeRepo,
+		smsSender:       smsSender,
+	}
+}
+
+// RequestChange verifies the password and sends an OTP to the new phone number
+func (s *PhoneChangeService) RequestChange(ctx context.Context, userID int, req *dto.RequestPhoneChangeRequest) error {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	newPhone, err := helpers.NormalizePhone(req.NewPhone)
+	if err != nil {
+		return helpers.NewCodeError(helpers.CodeValidation, "Invalid phone number").WithField("new_phone", "must be a mobile number such as 081234567890 or +6281234567890")
+	}
+
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return helpers.ErrInternalServer("Failed to find user").Wrap(err)
+	}
+	if user == nil {
+		return helpers.NewCodeError(helpers.CodeUserNotFound, "User not found")
+	}
+
+	if err := helpers.ComparePassword(ctx, user.Password, req.Password); err != nil {
+		return helpers.NewCodeError(helpers.CodeAuthPasswordIncorrect, "Invalid password")
+	}
+
+	if string(user.PhoneNumber) == newPhone {
+		return helpers.ErrBadRequest("New phone number must be different from the current phone number")
+	}
+
+	existingUser, err := s.authRepo.FindByPhone(ctx, tenant.ID, newPhone)
+	if err != nil {
+		return helpers.ErrInternalServer("Failed to check phone").Wrap(err)
+	}
+	if existingUser != nil {
+		return helpers.NewCodeError(helpers.CodeUserPhoneTaken, "Phone already exist").WithField("phone_number", "already in use")
+	}
+
+	pending, err := s.phoneChangeRepo.FindPendingByUserID(ctx, user.ID)
+	if err != nil {
+		return helpers.ErrInternalServer("Failed to find phone change request").Wrap(err)
+	}
+	if pending != nil && time.Since(pending.CreatedAt) < phoneChangeResendCooldown {
+		return helpers.NewCodeError(helpers.CodeAuthOTPRateLimited, "Please wait a minute before requesting another OTP")
+	}
+
+	otp, err := helpers.GenerateOTP(phoneChangeOTPDigits)
+	if err != nil {
+		return helpers.ErrInternalServer("Failed to generate OTP").Wrap(err)
+	}
+
+	request := &models.PhoneChangeRequest{
+		TenantID:  tenant.ID,
+		UserID:    user.ID,
+		OldPhone:  string(user.PhoneNumber),
+		NewPhone:  newPhone,
+		OTPHash:   helpers.HashToken(otp),
+		Status:    models.PhoneChangePending,
+		ExpiresAt: time.Now().Add(phoneChangeOTPExpiry),
+	}
+
+	if err := s.phoneChangeRepo.Create(ctx, request); err != nil {
+		return helpers.ErrInternalServer("Failed to save phone change request").Wrap(err)
+	}
+
+	if err := s.smsSender.Send(ctx, sms.Message{
+		To:   newPhone,
+		Body: i18n.Translate(i18n.ForUser(ctx, user.Locale), "%s is your verification code to change your phone number. It expires in 10 minutes. Do not share this code with anyone.", otp),
+	}); err != nil {
+		return helpers.ErrInternalServer("Failed to send OTP").Wrap(err)
+	}
+
+	return nil
+}
+
+// Verify checks the OTP and applies the pending phone number change
+func (s *PhoneChangeService) Verify(ctx context.Context, userID int, req *dto.VerifyPhoneChangeRequest) error {
+	request, err := s.phoneChangeRepo.FindPendingByUserID(ctx, userID)
+	if err != nil {
+		return helpers.ErrInternalServer("Failed to find phone change request").Wrap(err)
+	}
+	if request == nil || time.Now().After(request.ExpiresAt) || request.Attempts >= phoneChangeMaxAttempts {
+		return helpers.NewCodeError(helpers.CodeAuthOTPInvalid, "Invalid or expired OTP")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(request.OTPHash), []byte(helpers.HashToken(req.OTP))) != 1 {
+		if err := s.phoneChangeRepo.IncrementAttempts(ctx, request.ID); err != nil {
+			return helpers.ErrInternalServer("Failed to verify OTP").Wrap(err)
+		}
+		return helpers.NewCodeError(helpers.CodeAuthOTPInvalid, "Invalid or expired OTP")
+	}
+
+	if err := s.phoneChangeRepo.Apply(ctx, request); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrPhoneTaken):
+			return helpers.NewCodeError(helpers.CodeUserPhoneTaken, "Phone already exist").WithField("phone_number", "already in use")
+		case errors.Is(err, repository.ErrPhoneChangeNotPending):
+			return helpers.NewCodeError(helpers.CodeAuthOTPInvalid, "Invalid or expired OTP")
+		}
+		return helpers.ErrInternalServer("Failed to change phone number").Wrap(err)
+	}
+
+	return nil
+}
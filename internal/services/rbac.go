@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/constants"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+)
+
+type RBACService struct {
+	rbacRepo interfaces.IRBACRepository
+	users    interfaces.IUserLifecycleService
+}
+
+func NewRBACService(rbacRepo interfaces.IRBACRepository, users interfaces.IUserLifecycleService) interfaces.IRBACService {
+	return &RBACService{
+		rbacRepo: rbacRepo,
+		users:    users,
+	}
+}
+
+// ListRoles lists all roles with their permissions
+func (s *RBACService) ListRoles(ctx context.Context) ([]dto.RoleResponse, error) {
+	roles, err := s.rbacRepo.ListRoles(ctx)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to list roles").Wrap(err)
+	}
+
+	response := make([]dto.RoleResponse, 0, len(roles))
+	for i := range roles {
+		response = append(response, toRoleResponse(&roles[i]))
+	}
+
+	return response, nil
+}
+
+// ListPermissions lists all permissions
+func (s *RBACService) ListPermissions(ctx context.Context) ([]dto.PermissionResponse, error) {
+	permissions, err := s.rbacRepo.ListPermissions(ctx)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to list permissions").Wrap(err)
+	}
+
+	response := make([]dto.PermissionResponse, 0, len(permissions))
+	for _, p := range permissions {
+		response = append(response, dto.PermissionResponse{ID: p.ID, Name: p.Name, Description: p.Description})
+	}
+
+	return response, nil
+}
+
+// CreatePermission creates a new permission
+func (s *RBACService) CreatePermission(ctx context.Context, actor *helpers.JWTClaims, req *dto.CreatePermissionRequest) (*dto.PermissionResponse, error) {
+	if err := checkPlatformAdmin(actor); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.rbacRepo.FindPermissionsByNames(ctx, []string{req.Name})
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to check permission").Wrap(err)
+	}
+	if len(existing) > 0 {
+		return nil, helpers.ErrConflict("Permission already exists")
+	}
+
+	permission := &models.Permission{Name: req.Name, Description: req.Description}
+	if err := s.rbacRepo.CreatePermission(ctx, permission); err != nil {
+		return nil, helpers.ErrInternalServer("Failed to create permission").Wrap(err)
+	}
+
+	return &dto.PermissionResponse{ID: permission.ID, Name: permission.Name, Description: permission.Description}, nil
+}
+
+// SetRolePermissions replaces every permission of a role. The actor must
+// hold every permission the role is given.
+func (s *RBACService) SetRolePermissions(ctx context.Context, actor *helpers.JWTClaims, roleName string, req *dto.SetRolePermissionsRequest) (*dto.RoleResponse, error) {
+	if err := checkPlatformAdmin(actor); err != nil {
+		return nil, err
+	}
+
+	role, err := s.findRole(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkGrant(actor, role.Name, req.Permissions...); err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.rbacRepo.FindPermissionsByNames(ctx, req.Permissions)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to find permissions").Wrap(err)
+	}
+	if len(permissions) != len(uniqueStrings(req.Permissions)) {
+		return nil, helpers.ErrBadRequest("One or more permissions do not exist")
+	}
+
+	if err := s.rbacRepo.ReplaceRolePermissions(ctx, role, permissions); err != nil {
+		return nil, helpers.ErrInternalServer("Failed to update role permissions").Wrap(err)
+	}
+
+	return s.roleResponse(ctx, roleName)
+}
+
+// GrantPermission adds a permission to a role. The actor must hold the
+// permission.
+func (s *RBACService) GrantPermission(ctx context.Context, actor *helpers.JWTClaims, roleName string, req *dto.GrantPermissionRequest) (*dto.RoleResponse, error) {
+	if err := checkPlatformAdmin(actor); err != nil {
+		return nil, err
+	}
+
+	role, err := s.findRole(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkGrant(actor, role.Name, req.Permission); err != nil {
+		return nil, err
+	}
+
+	permission, err := s.findPermission(ctx, req.Permission)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.rbacRepo.AddRolePermission(ctx, role, permission); err != nil {
+		return nil, helpers.ErrInternalServer("Failed to grant permission").Wrap(err)
+	}
+
+	return s.roleResponse(ctx, roleName)
+}
+
+// RevokePermission removes a permission from a role
+func (s *RBACService) RevokePermission(ctx context.Context, actor *helpers.JWTClaims, roleName, permissionName string) (*dto.RoleResponse, error) {
+	if err := checkPlatformAdmin(actor); err != nil {
+		return nil, err
+	}
+
+	role, err := s.findRole(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkGrant(actor, role.Name, permissionName); err != nil {
+		return nil, err
+	}
+
+	permission, err := s.findPermission(ctx, permissionName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.rbacRepo.RemoveRolePermission(ctx, role, permission); err != nil {
+		return nil, helpers.ErrInternalServer("Failed to revoke permission").Wrap(err)
+	}
+
+	return s.roleResponse(ctx, roleName)
+}
+
+// GetUserRoles retrieves the roles and effective permissions of a user
+func (s *RBACService) GetUserRoles(ctx context.Context, userID int) (*dto.UserAccessResponse, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.userAccess(ctx, user)
+}
+
+// AssignUserRole assigns a role to a user. The actor must hold every
+// permission of the role.
+func (s *RBACService) AssignUserRole(ctx context.Context, actor *helpers.JWTClaims, userID int, req *dto.AssignRoleRequest) (*dto.UserAccessResponse, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkRoleGrant(ctx, actor, req.Role); err != nil {
+		return nil, err
+	}
+	if err := s.users.AssignRole(ctx, user, req.Role); err != nil {
+		return nil, err
+	}
+	return s.userAccess(ctx, user)
+}
+
+// RevokeUserRole removes a role from a user. The actor must hold every
+// permission of the role.
+func (s *RBACService) RevokeUserRole(ctx context.Context, actor *helpers.JWTClaims, userID int, roleName string) (*dto.UserAccessResponse, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkRoleGrant(ctx, actor, roleName); err != nil {
+		return nil, err
+	}
+	if err := s.users.RevokeRole(ctx, user, roleName); err != nil {
+		return nil, err
+	}
+	return s.userAccess(ctx, user)
+}
+
+// findUser finds a user of the request tenant
+func (s *RBACService) findUser(ctx context.Context, userID int) (*models.User, error) {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.users.Find(ctx, tenant.ID, userID)
+}
+
+// checkRoleGrant rejects assigning or revoking a role that gives more access
+// than the actor holds
+func (s *RBACService) checkRoleGrant(ctx context.Context, actor *helpers.JWTClaims, roleName string) error {
+	role, err := s.findRole(ctx, roleName)
+	if err != nil {
+		return err
+	}
+
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		permissions = append(permissions, p.Name)
+	}
+	return checkGrant(actor, role.Name, permissions...)
+}
+
+func (s *RBACService) userAccess(ctx context.Context, user *models.User) (*dto.UserAccessResponse, error) {
+	roles, permissions, err := resolveAccess(ctx, s.rbacRepo, user)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to load user roles").Wrap(err)
+	}
+	return &dto.UserAccessResponse{UserID: user.ID, Roles: roles, Permissions: permissions}, nil
+}
+
+func (s *RBACService) findRole(ctx context.Context, name string) (*models.Role, error) {
+	role, err := s.rbacRepo.FindRoleByName(ctx, name)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to find role").Wrap(err)
+	}
+	if role == nil {
+		return nil, helpers.ErrNotFound("Role not found")
+	}
+	return role, nil
+}
+
+func (s *RBACService) findPermission(ctx context.Context, name string) (*models.Permission, error) {
+	permissions, err := s.rbacRepo.FindPermissionsByNames(ctx, []string{name})
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to find permission").Wrap(err)
+	}
+	if len(permissions) == 0 {
+		return nil, helpers.ErrNotFound("Permission not found")
+	}
+	return &permissions[0], nil
+}
+
+func (s *RBACService) roleResponse(ctx context.Context, name string) (*dto.RoleResponse, error) {
+	role, err := s.findRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	response := toRoleResponse(role)
+	return &response, nil
+}
+
+func toRoleResponse(role *models.Role) dto.RoleResponse {
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		permissions = append(permissions, p.Name)
+	}
+	sort.Strings(permissions)
+
+	return dto.RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+	}
+}
+
+// resolveAccess returns the role names and effective permissions of a user.
+// Users without any user_roles row fall back to the legacy users.role column.
+func resolveAccess(ctx context.Context, rbacRepo interfaces.IRBACRepository, user *models.User) ([]string, []string, error) {
+	roles, err := rbacRepo.FindRolesByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(roles) == 0 && user.Role != "" {
+		role, err := rbacRepo.FindRoleByName(ctx, user.Role)
+		if err != nil {
+			return nil, nil, err
+		}
+		if role != nil {
+			roles = append(roles, *role)
+		}
+	}
+
+	roleNames := make([]string, 0, len(roles))
+	var permissions []string
+	for _, role := range roles {
+		roleNames = append(roleNames, role.Name)
+		for _, p := range role.Permissions {
+			permissions = append(permissions, p.Name)
+		}
+	}
+	permissions = uniqueStrings(permissions)
+	sort.Strings(permissions)
+
+	return roleNames, permissions, nil
+}
+
+// checkPlatformAdmin rejects changes to roles and permissions by anyone but
+// a superadmin of the default tenant. Roles are shared by every tenant, so a
+// tenant's own admins must not change what they grant.
+func checkPlatformAdmin(actor *helpers.JWTClaims) error {
+	if actor == nil {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+	if actor.TenantID != constants.DefaultTenantID || !actor.HasRole(constants.RoleSuperAdmin) {
+		return helpers.NewCodeError(helpers.CodeAuthPermissionDenied, "Roles are shared by every tenant, only a platform superadmin can change them")
+	}
+	return nil
+}
+
+// checkGrant rejects changes to a role by an actor who does not hold every
+// permission involved, so nobody can hand out more access than they have.
+// The superadmin role and the "*" permission are reserved for superadmins.
+func checkGrant(actor *helpers.JWTClaims, roleName string, permissions ...string) error {
+	if actor == nil {
+		return helpers.ErrUnauthorized("Unauthorized")
+	}
+
+	superadmin := actor.HasRole(constants.RoleSuperAdmin)
+	if roleName == constants.RoleSuperAdmin && !superadmin {
+		return helpers.NewCodeError(helpers.CodeAuthPermissionDenied, "Only a superadmin can manage the superadmin role")
+	}
+	for _, permission := range permissions {
+		if permission == constants.PermissionAll && !superadmin {
+			return helpers.NewCodeError(helpers.CodeAuthPermissionDenied, "Only a superadmin can manage the * permission")
+		}
+		if !actor.HasPermission(permission) {
+			return helpers.NewCodeErrorf(helpers.CodeAuthPermissionDenied, "You cannot grant the %s permission, you do not hold it", permission)
+		}
+	}
+	return nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
+	}
+	return result
+}
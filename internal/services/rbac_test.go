@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/constants"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+)
+
+func TestCheckPlatformAdmin(t *testing.T) {
+	tests := []struct {
+		name  string
+		actor *helpers.JWTClaims
+		want  helpers.ErrorCode
+	}{
+		{"superadmin of the default tenant", &helpers.JWTClaims{TenantID: constants.DefaultTenantID, Roles: []string{constants.RoleSuperAdmin}}, ""},
+		{"legacy superadmin role", &helpers.JWTClaims{TenantID: constants.DefaultTenantID, Role: constants.RoleSuperAdmin}, ""},
+		{"superadmin of another tenant", &helpers.JWTClaims{TenantID: 2, Roles: []string{constants.RoleSuperAdmin}}, helpers.CodeAuthPermissionDenied},
+		{"admin of the default tenant", &helpers.JWTClaims{TenantID: constants.DefaultTenantID, Roles: []string{constants.RoleAdmin}}, helpers.CodeAuthPermissionDenied},
+		{"anonymous", nil, helpers.CodeUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertErrorCode(t, checkPlatformAdmin(tt.actor), tt.want)
+		})
+	}
+}
+
+func TestCheckGrant(t *testing.T) {
+	admin := &helpers.JWTClaims{Roles: []string{constants.RoleAdmin}, Permissions: []string{"users:read", "users:update"}}
+	superadmin := &helpers.JWTClaims{Roles: []string{constants.RoleSuperAdmin}, Permissions: []string{constants.PermissionAll}}
+
+	tests := []struct {
+		name        string
+		actor       *helpers.JWTClaims
+		role        string
+		permissions []string
+		want        helpers.ErrorCode
+	}{
+		{"grant held", admin, "support", []string{"users:read"}, ""},
+		{"every grant held", admin, "support", []string{"users:read", "users:update"}, ""},
+		{"grant absent", admin, "support", []string{"users:read", "users:delete"}, helpers.CodeAuthPermissionDenied},
+		{"no permissions", admin, "support", nil, ""},
+		{"superadmin role", admin, constants.RoleSuperAdmin, nil, helpers.CodeAuthPermissionDenied},
+		{"wildcard by admin", &helpers.JWTClaims{Permissions: []string{constants.PermissionAll}}, "support", []string{constants.PermissionAll}, helpers.CodeAuthPermissionDenied},
+		{"wildcard by superadmin", superadmin, constants.RoleSuperAdmin, []string{constants.PermissionAll, "users:delete"}, ""},
+		{"anonymous", nil, "support", []string{"users:read"}, helpers.CodeUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertErrorCode(t, checkGrant(tt.actor, tt.role, tt.permissions...), tt.want)
+		})
+	}
+}
+
+// assertErrorCode checks err is nil when want is empty, else an AppError
+// with that code
+func assertErrorCode(t *testing.T, err error, want helpers.ErrorCode) {
+	t.Helper()
+	if want == "" {
+		if err != nil {
+			t.Errorf("error = %v, want nil", err)
+		}
+		return
+	}
+	var appErr *helpers.AppError
+	if !errors.As(err, &appErr) || appErr.ErrorCode != want {
+		t.Errorf("error = %v, want %s", err, want)
+	}
+}
+
+// fakeAccessRepo implements the part of IRBACRepository used by resolveAccess
+type fakeAccessRepo struct {
+	interfaces.IRBACRepository
+	userRoles map[int][]models.Role
+	roles     map[string]*models.Role
+}
+
+func (r *fakeAccessRepo) FindRolesByUserID(_ context.Context, userID int) ([]models.Role, error) {
+	return r.userRoles[userID], nil
+}
+
+func (r *fakeAccessRepo) FindRoleByName(_ context.Context, name string) (*models.Role, error) {
+	return r.roles[name], nil
+}
+
+func TestResolveAccess(t *testing.T) {
+	permissions := func(names ...string) []models.Permission {
+		var result []models.Permission
+		for _, name := range names {
+			result = append(result, models.Permission{Name: name})
+		}
+		return result
+	}
+	customer := models.Role{Name: constants.RoleCustomer, Permissions: permissions("profile:read", "orders:create")}
+	seller := models.Role{Name: constants.RoleSeller, Permissions: permissions("stores:update", "profile:read")}
+	repo := &fakeAccessRepo{
+		userRoles: map[int][]models.Role{1: {customer, seller}},
+		roles:     map[string]*models.Role{constants.RoleCustomer: &customer},
+	}
+
+	tests := []struct {
+		name            string
+		user            *models.User
+		wantRoles       []string
+		wantPermissions []string
+	}{
+		{"granted roles", &models.User{ID: 1, Role: constants.RoleAdmin}, []string{"customer", "seller"}, []string{"orders:create", "profile:read", "stores:update"}},
+		{"legacy users.role", &models.User{ID: 2, Role: constants.RoleCustomer}, []string{"customer"}, []string{"orders:create", "profile:read"}},
+		{"unknown legacy role", &models.User{ID: 3, Role: "vendor"}, []string{}, []string{}},
+		{"no role", &models.User{ID: 4}, []string{}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roles, permissions, err := resolveAccess(context.Background(), repo, tt.user)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(roles, tt.wantRoles) || !reflect.DeepEqual(permissions, tt.wantPermissions) {
+				t.Errorf("resolveAccess() = %v, %v, want %v, %v", roles, permissions, tt.wantRoles, tt.wantPermissions)
+			}
+		})
+	}
+}
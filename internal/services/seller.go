@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/constants"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/events"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/repository"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/storage"
+)
+
+const maxDocumentSize = 5 << 20 // 5 MB
+
+// documentCleanupBatchSize limits how many files one cleanup run removes
+const documentCleanupBatchSize = 100
+
+// KYC document types required for a seller application
+var requiredDocuments = []string{"ktp", "npwp"}
+
+var allowedDocumentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+type SellerService struct {
+	sellerRepo interfaces.ISellerRepository
+	rbacRepo   interfaces.IRBACRepository
+	users      interfaces.IUserLifecycleService
+	blobStore  interfaces.IBlobStore
+	publisher  interfaces.IEventPublisher
+}
+
+func NewSellerService(sellerRepo interfaces.ISellerRepository, rbacRepo interfaces.IRBACRepository, users interfaces.IUserLifecycleService, blobStore interfaces.IBlobStore, publisher interfaces.IEventPublisher) interfaces.ISellerService {
+	return &SellerService{
+		sellerRepo: sellerRepo,
+		rbacRepo:   rbacRepo,
+		users:      users,
+		blobStore:  blobStore,
+		publisher:  publisher,
+	}
+}
+
+// Apply submits a seller application with its KYC documents
+func (s *SellerService) Apply(ctx context.Context, userID int, req *dto.SellerApplicationRequest, documents []dto.DocumentUpload) (*dto.SellerApplicationResponse, error) {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the user isn't a seller already
+	roles, err := s.rbacRepo.FindRolesByUserID(ctx, userID)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to load user roles").Wrap(err)
+	}
+	for _, role := range roles {
+		if role.Name == constants.RoleSeller {
+			return nil, helpers.ErrConflict("User is already a seller")
+		}
+	}
+
+	// Only one application may be under review
+	latest, err := s.sellerRepo.FindLatestApplicationByUserID(ctx, userID)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to check existing application").Wrap(err)
+	}
+	if latest != nil && latest.Status == models.SellerApplicationPending {
+		return nil, helpers.ErrConflict("An application is already pending review")
+	}
+
+	uploads := make(map[string]dto.DocumentUpload, len(documents))
+	for _, doc := range documents {
+		uploads[doc.Type] = doc
+	}
+	for _, docType := range requiredDocuments {
+		if _, ok := uploads[docType]; !ok {
+			return nil, helpers.ErrBadRequestf("Document %s is required", docType)
+		}
+	}
+
+	application := &models.SellerApplication{
+		TenantID:  tenant.ID,
+		UserID:    userID,
+		StoreName: req.StoreName,
+		NIK:       req.NIK,
+		NPWP:      req.NPWP,
+		Status:    models.SellerApplicationPending,
+	}
+
+	var stored []string
+	cleanup := func() {
+		for _, key := range stored {
+			_ = s.blobStore.Delete(ctx, key)
+		}
+	}
+
+	for _, docType := range requiredDocuments {
+		doc, err := s.storeDocument(ctx, tenant.ID, userID, uploads[docType])
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		stored = append(stored, doc.BlobKey)
+		application.Documents = append(application.Documents, *doc)
+	}
+
+	if err := s.sellerRepo.CreateApplication(ctx, application); err != nil {
+		cleanup()
+		return nil, helpers.ErrInternalServer("Failed to create application").Wrap(err)
+	}
+
+	response := toSellerApplicationResponse(application, false)
+	return &response, nil
+}
+
+// GetMyApplication retrieves the latest application of the user
+func (s *SellerService) GetMyApplication(ctx context.Context, userID int) (*dto.SellerApplicationResponse, error) {
+	application, err := s.sellerRepo.FindLatestApplicationByUserID(ctx, userID)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to find application").Wrap(err)
+	}
+	if application == nil {
+		return nil, helpers.ErrNotFound("Application not found")
+	}
+
+	response := toSellerApplicationResponse(application, false)
+	return &response, nil
+}
+
+// ListApplications lists applications for reviewers
+func (s *SellerService) ListApplications(ctx context.Context, status string) ([]dto.SellerApplicationResponse, error) {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case "", models.SellerApplicationPending, models.SellerApplicationApproved, models.SellerApplicationRejected:
+	default:
+		return nil, helpers.ErrBadRequest("Invalid status filter")
+	}
+
+	applications, err := s.sellerRepo.ListApplications(ctx, tenant.ID, status)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to list applications").Wrap(err)
+	}
+
+	response := make([]dto.SellerApplicationResponse, 0, len(applications))
+	for i := range applications {
+		response = append(response, toSellerApplicationResponse(&applications[i], true))
+	}
+
+	return response, nil
+}
+
+// GetApplication retrieves an application for reviewers
+func (s *SellerService) GetApplication(ctx context.Context, id int) (*dto.SellerApplicationResponse, error) {
+	application, err := s.findApplication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toSellerApplicationResponse(application, true)
+	return &response, nil
+}
+
+// Approve approves a pending application and grants the seller role in the
+// same transaction, so an application is never approved without the role
+func (s *SellerService) Approve(ctx context.Context, reviewerID, id int, req *dto.ReviewSellerApplicationRequest) (*dto.SellerApplicationResponse, error) {
+	application, err := s.findApplication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if application.Status != models.SellerApplicationPending {
+		return nil, helpers.ErrConflict("Application has already been reviewed")
+	}
+
+	user, err := s.users.Find(ctx, application.TenantID, application.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	decide(application, reviewerID, models.SellerApplicationApproved, req.Notes)
+	err = s.users.AssignRoleWith(ctx, user, constants.RoleSeller, func(ctx context.Context, roleID int) error {
+		return s.storeReview(ctx, application, roleID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publishReview(ctx, application, constants.EventSellerApproved)
+
+	response := toSellerApplicationResponse(application, true)
+	return &response, nil
+}
+
+// Reject rejects a pending application, notes are required
+func (s *SellerService) Reject(ctx context.Context, reviewerID, id int, req *dto.ReviewSellerApplicationRequest) (*dto.SellerApplicationResponse, error) {
+	if strings.TrimSpace(req.Notes) == "" {
+		return nil, helpers.ErrBadRequest("Notes are required when rejecting an application")
+	}
+
+	application, err := s.findApplication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	decide(application, reviewerID, models.SellerApplicationRejected, req.Notes)
+	if err := s.storeReview(ctx, application, 0); err != nil {
+		return nil, err
+	}
+	s.publishReview(ctx, application, constants.EventSellerRejected)
+
+	response := toSellerApplicationResponse(application, true)
+	return &response, nil
+}
+
+// OpenDocument opens an uploaded document for reviewers
+func (s *SellerService) OpenDocument(ctx context.Context, applicationID, documentID int) (*models.SellerDocument, io.ReadCloser, error) {
+	application, err := s.findApplication(ctx, applicationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range application.Documents {
+		doc := &application.Documents[i]
+		if doc.ID != documentID {
+			continue
+		}
+
+		reader, err := s.blobStore.Get(ctx, doc.BlobKey)
+		if err != nil {
+			return nil, nil, helpers.ErrInternalServer("Failed to open document").Wrap(err)
+		}
+		return doc, reader, nil
+	}
+
+	return nil, nil, helpers.ErrNotFound("Document not found")
+}
+
+// decide records the review decision on the application
+func decide(application *models.SellerApplication, reviewerID int, status, notes string) {
+	now := time.Now()
+	application.Status = status
+	application.ReviewerID = &reviewerID
+	application.ReviewerNotes = notes
+	application.ReviewedAt = &now
+}
+
+// storeReview stores the decision on a pending application, granting
+// grantRoleID to the applicant unless it is 0
+func (s *SellerService) storeReview(ctx context.Context, application *models.SellerApplication, grantRoleID int) error {
+	if err := s.sellerRepo.ReviewApplication(ctx, application, grantRoleID); err != nil {
+		if errors.Is(err, repository.ErrApplicationNotPending) {
+			return helpers.ErrConflict("Application has already been reviewed")
+		}
+		return helpers.ErrInternalServer("Failed to review application").Wrap(err)
+	}
+	return nil
+}
+
+// publishReview publishes eventType once the decision is stored, a failure
+// is only logged
+func (s *SellerService) publishReview(ctx context.Context, application *models.SellerApplication, eventType string) {
+	event := events.New(eventType, application.TenantID, map[string]interface{}{
+		"application_id": application.ID,
+		"user_id":        application.UserID,
+		"store_name":     application.StoreName,
+		"reviewer_id":    *application.ReviewerID,
+	})
+	if err := s.publisher.Publish(ctx, event); err != nil {
+		helpers.Log(ctx).WithError(err).WithField("event_type", eventType).Error("Failed to publish event")
+	}
+}
+
+// PurgeDeletedDocuments removes the files of documents that belonged to
+// deleted accounts and returns how many were removed
+func (s *SellerService) PurgeDeletedDocuments(ctx context.Context) (int, error) {
+	documents, err := s.sellerRepo.FindDeletedDocuments(ctx, documentCleanupBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, document := range documents {
+		if err := s.blobStore.Delete(ctx, document.BlobKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return i, err
+		}
+		if err := s.sellerRepo.DeleteDocument(ctx, document.ID); err != nil {
+			return i, err
+		}
+	}
+
+	return len(documents), nil
+}
+
+func (s *SellerService) findApplication(ctx context.Context, id int) (*models.SellerApplication, error) {
+	tenant, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	application, err := s.sellerRepo.FindApplicationByID(ctx, tenant.ID, id)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to find application").Wrap(err)
+	}
+	if application == nil {
+		return nil, helpers.ErrNotFound("Application not found")
+	}
+	return application, nil
+}
+
+// storeDocument validates an upload and writes it to the blob store
+func (s *SellerService) storeDocument(ctx context.Context, tenantID, userID int, upload dto.DocumentUpload) (*models.SellerDocument, error) {
+	if upload.File.Size > maxDocumentSize {
+		return nil, helpers.ErrBadRequestf("Document %s exceeds 5 MB", upload.Type)
+	}
+
+	file, err := upload.File.Open()
+	if err != nil {
+		return nil, helpers.ErrBadRequestf("Failed to read document %s", upload.Type)
+	}
+	defer file.Close()
+
+	// Detect the content type from the file itself, not the client header
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, helpers.ErrBadRequestf("Failed to read document %s", upload.Type)
+	}
+	contentType := http.DetectContentType(head[:n])
+	ext, ok := allowedDocumentTypes[contentType]
+	if !ok {
+		return nil, helpers.ErrBadRequestf("Document %s must be a JPEG, PNG or PDF file", upload.Type)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, helpers.ErrInternalServer("Failed to read document").Wrap(err)
+	}
+
+	random, err := helpers.GenerateRandomToken(16)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to store document").Wrap(err)
+	}
+	key := filepath.ToSlash(filepath.Join("kyc", strconv.Itoa(tenantID), strconv.Itoa(userID), upload.Type+"-"+random+ext))
+
+	if err := s.blobStore.Put(ctx, key, file); err != nil {
+		helpers.Log(ctx).WithError(err).Error("Failed to store KYC document")
+		return nil, helpers.ErrInternalServer("Failed to store document").Wrap(err)
+	}
+
+	return &models.SellerDocument{
+		Type:        upload.Type,
+		BlobKey:     key,
+		FileName:    filepath.Base(upload.File.Filename),
+		ContentType: contentType,
+		Size:        upload.File.Size,
+	}, nil
+}
+
+func toSellerApplicationResponse(application *models.SellerApplication, reviewer bool) dto.SellerApplicationResponse {
+	documents := make([]dto.SellerDocumentResponse, 0, len(application.Documents))
+	for _, doc := range application.Documents {
+		documents = append(documents, dto.SellerDocumentResponse{
+			ID:          doc.ID,
+			Type:        doc.Type,
+			FileName:    doc.FileName,
+			ContentType: doc.ContentType,
+			Size:        doc.Size,
+			CreatedAt:   doc.CreatedAt,
+		})
+	}
+
+	nik, npwp := application.NIK, application.NPWP
+	if !reviewer {
+		nik, npwp = maskDigits(nik), maskDigits(npwp)
+	}
+
+	return dto.SellerApplicationResponse{
+		ID:            application.ID,
+		UserID:        application.UserID,
+		StoreName:     application.StoreName,
+		NIK:           nik,
+		NPWP:          npwp,
+		Status:        application.Status,
+		ReviewerID:    application.ReviewerID,
+		ReviewerNotes: application.ReviewerNotes,
+		ReviewedAt:    application.ReviewedAt,
+		Documents:     documents,
+		CreatedAt:     application.CreatedAt,
+	}
+}
+
+// maskDigits keeps only the last four characters visible
+func maskDigits(value string) string {
+	if len(value) <= 4 {
+		return value
+	}
+	return strings.Repeat("*", len(value)-4) + value[len(value)-4:]
+}
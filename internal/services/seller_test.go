@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/constants"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/repository"
+)
+
+// fakeSellerRepo keeps one application in memory
+type fakeSellerRepo struct {
+	interfaces.ISellerRepository
+	application models.SellerApplication
+	reviewErr   error
+	reviews     int
+	grantedRole int
+}
+
+func (r *fakeSellerRepo) FindApplicationByID(_ context.Context, tenantID, id int) (*models.SellerApplication, error) {
+	if r.application.TenantID != tenantID || r.application.ID != id {
+		return nil, nil
+	}
+	application := r.application
+	return &application, nil
+}
+
+func (r *fakeSellerRepo) ReviewApplication(_ context.Context, application *models.SellerApplication, grantRoleID int) error {
+	r.reviews++
+	if r.reviewErr != nil {
+		return r.reviewErr
+	}
+	if r.application.Status != models.SellerApplicationPending {
+		return repository.ErrApplicationNotPending
+	}
+	r.application.Status = application.Status
+	r.grantedRole = grantRoleID
+	return nil
+}
+
+func newApproveTest(status string) (*fakeSellerRepo, *fakePublisher, interfaces.ISellerService, context.Context) {
+	tenant := &models.Tenant{ID: 1}
+	authRepo := &fakeUserAuthRepo{registered: []*models.User{{ID: 1, TenantID: tenant.ID, Role: constants.RoleCustomer}}}
+	sellerRepo := &fakeSellerRepo{application: models.SellerApplication{ID: 5, TenantID: tenant.ID, UserID: 1, Status: status}}
+	publisher := &fakePublisher{}
+	users := NewUserLifecycleService(authRepo, fakeRoleRepo{}, nil, publisher)
+	service := NewSellerService(sellerRepo, nil, users, nil, publisher)
+	return sellerRepo, publisher, service, helpers.WithTenant(context.Background(), tenant)
+}
+
+func TestApproveGrantsRoleWithTheReview(t *testing.T) {
+	sellerRepo, publisher, service, ctx := newApproveTest(models.SellerApplicationPending)
+
+	response, err := service.Approve(ctx, 9, 5, &dto.ReviewSellerApplicationRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Status != models.SellerApplicationApproved || sellerRepo.grantedRole == 0 {
+		t.Errorf("Approve() = %s with role %d, want approved with the seller role", response.Status, sellerRepo.grantedRole)
+	}
+
+	var types []string
+	for _, event := range publisher.published {
+		types = append(types, event.Type)
+	}
+	if len(types) != 2 || types[0] != constants.EventUserRoleAssigned || types[1] != constants.EventSellerApproved {
+		t.Errorf("published %v, want the role assignment and the approval", types)
+	}
+}
+
+func TestApproveRejectsReviewedApplications(t *testing.T) {
+	for _, status := range []string{models.SellerApplicationApproved, models.SellerApplicationRejected} {
+		sellerRepo, publisher, service, ctx := newApproveTest(status)
+
+		_, err := service.Approve(ctx, 9, 5, &dto.ReviewSellerApplicationRequest{})
+		var appErr *helpers.AppError
+		if !errors.As(err, &appErr) || appErr.Code != http.StatusConflict {
+			t.Errorf("approving a %s application: error = %v, want a conflict", status, err)
+		}
+		if sellerRepo.reviews != 0 || len(publisher.published) != 0 {
+			t.Errorf("approving a %s application stored or published a review", status)
+		}
+	}
+}
+
+func TestApprovePublishesNothingWhenStoringFails(t *testing.T) {
+	sellerRepo, publisher, service, ctx := newApproveTest(models.SellerApplicationPending)
+	sellerRepo.reviewErr = errors.New("connection reset")
+
+	_, err := service.Approve(ctx, 9, 5, &dto.ReviewSellerApplicationRequest{})
+	var appErr *helpers.AppError
+	if !errors.As(err, &appErr) || appErr.Code != http.StatusInternalServerError {
+		t.Errorf("Approve() error = %v, want an internal error", err)
+	}
+	if len(publisher.published) != 0 {
+		t.Errorf("published %d events for a failed approval", len(publisher.published))
+	}
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/metrics"
+)
+
+type SessionMetricsService struct {
+	authRepo interfaces.IAuthRepository
+}
+
+func NewSessionMetricsService(authRepo interfaces.IAuthRepository) interfaces.ISessionMetricsService {
+	return &SessionMetricsService{authRepo: authRepo}
+}
+
+// Collect updates the active sessions gauge. It runs as a background job so
+// scrapes never query the database; it reports no processed items to keep
+// the job log quiet.
+func (s *SessionMetricsService) Collect(ctx context.Context) (int, error) {
+	count, err := s.authRepo.CountActiveSessions(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	metrics.ActiveSessions.Set(float64(count))
+	return 0, nil
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/constants"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+)
+
+const (
+	tenantCacheTTL = time.Minute
+	// tenantCacheSize caps the cache, the least recently used entry is
+	// evicted first
+	tenantCacheSize = 256
+)
+
+type cachedTenant struct {
+	key       string
+	tenant    *models.Tenant
+	expiresAt time.Time
+}
+
+type TenantService struct {
+	tenantRepo interfaces.ITenantRepository
+
+	mu      sync.Mutex
+	cache   map[string]*list.Element
+	recency *list.List
+}
+
+func NewTenantService(tenantRepo interfaces.ITenantRepository) interfaces.ITenantService {
+	return &TenantService{
+		tenantRepo: tenantRepo,
+		cache:      make(map[string]*list.Element),
+		recency:    list.New(),
+	}
+}
+
+// Resolve resolves the tenant from the X-Tenant-ID header (slug or ID) or,
+// when the header is absent, from the request host. Hosts that don't belong
+// to any tenant fall back to the default tenant.
+func (s *TenantService) Resolve(ctx context.Context, tenantHeader, host string) (*models.Tenant, error) {
+	if tenantHeader != "" {
+		tenant, err := s.lookup(ctx, "header:"+tenantHeader, func() (*models.Tenant, error) {
+			if id, err := strconv.Atoi(tenantHeader); err == nil {
+				return s.tenantRepo.FindByID(ctx, id)
+			}
+			return s.tenantRepo.FindBySlug(ctx, tenantHeader)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if tenant == nil {
+			return nil, helpers.NewCodeError(helpers.CodeTenantUnknown, "Unknown tenant")
+		}
+		return checkTenantActive(tenant)
+	}
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+
+	if host != "" {
+		tenant, err := s.lookup(ctx, "host:"+host, func() (*models.Tenant, error) {
+			return s.tenantRepo.FindByDomain(ctx, host)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if tenant != nil {
+			return checkTenantActive(tenant)
+		}
+	}
+
+	tenant, err := s.lookup(ctx, "default", func() (*models.Tenant, error) {
+		return s.tenantRepo.FindBySlug(ctx, constants.DefaultTenantSlug)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		return nil, helpers.ErrInternalServer("Default tenant is not configured")
+	}
+	return checkTenantActive(tenant)
+}
+
+// lookup serves tenants from a short lived, size capped cache. Misses are
+// not cached: keys come from request headers, so caching them would let
+// clients fill the cache with made up values.
+func (s *TenantService) lookup(ctx context.Context, key string, find func() (*models.Tenant, error)) (*models.Tenant, error) {
+	if tenant, ok := s.cached(key); ok {
+		return tenant, nil
+	}
+
+	tenant, err := find()
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to resolve tenant").Wrap(err)
+	}
+	if tenant != nil {
+		s.store(key, tenant)
+	}
+	return tenant, nil
+}
+
+func (s *TenantService) cached(key string) (*models.Tenant, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.cache[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cachedTenant)
+	if time.Now().After(entry.expiresAt) {
+		s.recency.Remove(element)
+		delete(s.cache, key)
+		return nil, false
+	}
+	s.recency.MoveToFront(element)
+	return entry.tenant, true
+}
+
+func (s *TenantService) store(key string, tenant *models.Tenant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := &cachedTenant{key: key, tenant: tenant, expiresAt: time.Now().Add(tenantCacheTTL)}
+	if element, ok := s.cache[key]; ok {
+		element.Value = entry
+		s.recency.MoveToFront(element)
+		return
+	}
+
+	s.cache[key] = s.recency.PushFront(entry)
+	for s.recency.Len() > tenantCacheSize {
+		oldest := s.recency.Back()
+		s.recency.Remove(oldest)
+		delete(s.cache, oldest.Value.(*cachedTenant).key)
+	}
+}
+
+func checkTenantActive(tenant *models.Tenant) (*models.Tenant, error) {
+	if !tenant.IsActive {
+		return nil, helpers.NewCodeError(helpers.CodeTenantDisabled, "Tenant is disabled")
+	}
+	return tenant, nil
+}
+
+// tenantFromContext returns the tenant resolved by TenantMiddleware
+func tenantFromContext(ctx context.Context) (*models.Tenant, error) {
+	tenant := helpers.TenantFromContext(ctx)
+	if tenant == nil {
+		return nil, helpers.NewCodeError(helpers.CodeTenantUnknown, "Unknown tenant")
+	}
+	return tenant, nil
+}
+
+// checkPasswordPolicy validates a new password against the tenant password policy
+func checkPasswordPolicy(tenant *models.Tenant, password string) error {
+	if len(password) < tenant.PasswordMinLength {
+		return helpers.NewCodeErrorf(helpers.CodeAuthPasswordPolicy, "Password must be at least %d characters", tenant.PasswordMinLength)
+	}
+
+	if tenant.PasswordRequireMixed {
+		hasLetter := strings.IndexFunc(password, func(r rune) bool { return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') }) >= 0
+		hasDigit := strings.IndexFunc(password, func(r rune) bool { return r >= '0' && r <= '9' }) >= 0
+		if !hasLetter || !hasDigit {
+			return helpers.NewCodeError(helpers.CodeAuthPasswordPolicy, "Password must contain both letters and digits")
+		}
+	}
+
+	return nil
+}
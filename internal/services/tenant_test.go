@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+)
+
+type fakeTenantRepo struct {
+	tenants []*models.Tenant
+	calls   int
+	err     error
+}
+
+func (r *fakeTenantRepo) find(match func(*models.Tenant) bool) (*models.Tenant, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	for _, tenant := range r.tenants {
+		if match(tenant) {
+			return tenant, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeTenantRepo) FindByID(_ context.Context, id int) (*models.Tenant, error) {
+	return r.find(func(t *models.Tenant) bool { return t.ID == id })
+}
+
+func (r *fakeTenantRepo) FindBySlug(_ context.Context, slug string) (*models.Tenant, error) {
+	return r.find(func(t *models.Tenant) bool { return t.Slug == slug })
+}
+
+func (r *fakeTenantRepo) FindByDomain(_ context.Context, domain string) (*models.Tenant, error) {
+	return r.find(func(t *models.Tenant) bool { return t.Domain != nil && *t.Domain == domain })
+}
+
+func newFakeTenantRepo() *fakeTenantRepo {
+	shop := "shop.example.com"
+	return &fakeTenantRepo{tenants: []*models.Tenant{
+		{ID: 1, Slug: "default", IsActive: true},
+		{ID: 2, Slug: "shop", Domain: &shop, IsActive: true},
+		{ID: 3, Slug: "closed", IsActive: false},
+	}}
+}
+
+func errorCode(err error) helpers.ErrorCode {
+	var appErr *helpers.AppError
+	if errors.As(err, &appErr) {
+		return appErr.ErrorCode
+	}
+	return ""
+}
+
+func TestTenantResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		host     string
+		wantID   int
+		wantCode helpers.ErrorCode
+	}{
+		{name: "header slug", header: "shop", wantID: 2},
+		{name: "header id", header: "2", wantID: 2},
+		{name: "header wins over host", header: "default", host: "shop.example.com", wantID: 1},
+		{name: "unknown header", header: "nope", wantCode: helpers.CodeTenantUnknown},
+		{name: "disabled tenant", header: "closed", wantCode: helpers.CodeTenantDisabled},
+		{name: "host", host: "shop.example.com", wantID: 2},
+		{name: "host with port and case", host: "SHOP.example.com:8443", wantID: 2},
+		{name: "unknown host falls back to default", host: "api.example.com", wantID: 1},
+		{name: "no header or host", wantID: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewTenantService(newFakeTenantRepo())
+
+			tenant, err := s.Resolve(context.Background(), tt.header, tt.host)
+			if tt.wantCode != "" {
+				if code := errorCode(err); code != tt.wantCode {
+					t.Fatalf("Resolve() error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tenant.ID != tt.wantID {
+				t.Errorf("Resolve() = tenant %d, want %d", tenant.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestTenantResolveMissingDefault(t *testing.T) {
+	s := NewTenantService(&fakeTenantRepo{})
+	if _, err := s.Resolve(context.Background(), "", "api.example.com"); errorCode(err) != helpers.CodeInternal {
+		t.Fatalf("Resolve() error = %v, want an internal error", err)
+	}
+}
+
+func TestTenantResolveRepositoryError(t *testing.T) {
+	s := NewTenantService(&fakeTenantRepo{err: errors.New("connection refused")})
+	if _, err := s.Resolve(context.Background(), "shop", ""); errorCode(err) != helpers.CodeInternal {
+		t.Fatalf("Resolve() error = %v, want an internal error", err)
+	}
+}
+
+func TestTenantCacheServesHitsOnly(t *testing.T) {
+	repo := newFakeTenantRepo()
+	s := NewTenantService(repo)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.Resolve(ctx, "shop", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if repo.calls != 1 {
+		t.Errorf("found tenant looked up %d times, want it cached after the first", repo.calls)
+	}
+
+	repo.calls = 0
+	for i := 0; i < 3; i++ {
+		s.Resolve(ctx, "nope", "")
+	}
+	if repo.calls != 3 {
+		t.Errorf("unknown tenant looked up %d times, misses must not be cached", repo.calls)
+	}
+}
+
+func TestTenantCacheIsBounded(t *testing.T) {
+	repo := &fakeTenantRepo{}
+	for i := 1; i <= tenantCacheSize+10; i++ {
+		repo.tenants = append(repo.tenants, &models.Tenant{ID: i, Slug: fmt.Sprintf("tenant-%d", i), IsActive: true})
+	}
+	s := NewTenantService(repo).(*TenantService)
+	ctx := context.Background()
+
+	for _, tenant := range repo.tenants {
+		if _, err := s.Resolve(ctx, tenant.Slug, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(s.cache) != tenantCacheSize || s.recency.Len() != tenantCacheSize {
+		t.Fatalf("cache holds %d entries, want %d", len(s.cache), tenantCacheSize)
+	}
+
+	// The oldest entry was evicted, the newest is still served from the cache
+	repo.calls = 0
+	s.Resolve(ctx, "tenant-1", "")
+	s.Resolve(ctx, repo.tenants[len(repo.tenants)-1].Slug, "")
+	if repo.calls != 1 {
+		t.Errorf("got %d lookups, want only the evicted tenant looked up again", repo.calls)
+	}
+}
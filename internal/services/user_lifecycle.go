@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/constants"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/events"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/pii"
+)
+
+type UserLifecycleService struct {
+	authRepo     interfaces.IAuthRepository
+	rbacRepo     interfaces.IRBACRepository
+	deletionRepo interfaces.IAccountDeletionRepository
+	publisher    interfaces.IEventPublisher
+	hooks        []interfaces.IUserHook
+}
+
+func NewUserLifecycleService(authRepo interfaces.IAuthRepository, rbacRepo interfaces.IRBACRepository, deletionRepo interfaces.IAccountDeletionRepository, publisher interfaces.IEventPublisher, hooks ...interfaces.IUserHook) interfaces.IUserLifecycleService {
+	return &UserLifecycleService{
+		authRepo:     authRepo,
+		rbacRepo:     rbacRepo,
+		deletionRepo: deletionRepo,
+		publisher:    publisher,
+		hooks:        hooks,
+	}
+}
+
+// Create validates and stores a new user of the tenant with its role and
+// consents, then publishes user.created. The BeforeCreate hooks run in order
+// before the user is stored and the first error aborts the creation; the
+// AfterCreate hooks run once it is committed.
+func (s *UserLifecycleService) Create(ctx context.Context, tenant *models.Tenant, input *dto.CreateUserInput) (*models.User, error) {
+	if err := checkPasswordPolicy(tenant, input.Password); err != nil {
+		return nil, err
+	}
+
+	phoneNumber, err := helpers.NormalizePhone(input.PhoneNumber)
+	if err != nil {
+		return nil, helpers.NewCodeError(helpers.CodeValidation, "Invalid phone number").WithField("phone_number", "must be a mobile number such as 081234567890 or +6281234567890")
+	}
+
+	role, err := s.findRole(ctx, input.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		TenantID:      tenant.ID,
+		Username:      input.Username,
+		Email:         input.Email,
+		PhoneNumber:   pii.EncryptedString(phoneNumber),
+		FullName:      input.FullName,
+		Address:       pii.EncryptedString(input.Address),
+		Dob:           pii.NewEncryptedDate(input.Dob),
+		Role:          role.Name,
+		Locale:        input.Locale,
+		EmailVerified: input.EmailVerified,
+		IsActive:      true,
+	}
+
+	for _, hook := range s.hooks {
+		if err := hook.BeforeCreate(ctx, user, input); err != nil {
+			return nil, err
+		}
+	}
+
+	// Hashing is slow, so it runs once the cheap checks passed
+	user.Password, err = helpers.HashPassword(ctx, input.Password)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to hash password").Wrap(err)
+	}
+
+	// The unique indexes reject a taken email, username or phone number,
+	// also when two sign-ups race
+	if err := s.authRepo.RegisterUser(ctx, user, role.ID, input.Consents); err != nil {
+		var conflict *constants.ConflictError
+		if errors.As(err, &conflict) {
+			return nil, helpers.ErrAlreadyInUse(conflict.Fields...)
+		}
+		return nil, helpers.ErrInternalServer("Failed to create user").Wrap(err)
+	}
+
+	s.publish(ctx, constants.EventUserCreated, user, map[string]interface{}{"role": user.Role})
+	for _, hook := range s.hooks {
+		hook.AfterCreate(ctx, user)
+	}
+
+	return user, nil
+}
+
+// Find returns a user of the tenant that has not been deleted
+func (s *UserLifecycleService) Find(ctx context.Context, tenantID, userID int) (*models.User, error) {
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to find user").Wrap(err)
+	}
+	if user == nil || user.TenantID != tenantID || user.AnonymizedAt != nil {
+		return nil, helpers.NewCodeError(helpers.CodeUserNotFound, "User not found")
+	}
+	return user, nil
+}
+
+// Activate lets a deactivated user log in again
+func (s *UserLifecycleService) Activate(ctx context.Context, user *models.User) error {
+	if user.IsActive {
+		return nil
+	}
+	if err := s.authRepo.UpdateProfile(ctx, user.ID, map[string]interface{}{"is_active": true}); err != nil {
+		return helpers.ErrInternalServer("Failed to activate user").Wrap(err)
+	}
+	user.IsActive = true
+
+	s.publish(ctx, constants.EventUserActivated, user, nil)
+	return nil
+}
+
+// Deactivate blocks the user from logging in and revokes every session
+func (s *UserLifecycleService) Deactivate(ctx context.Context, user *models.User) error {
+	if err := s.authRepo.UpdateProfile(ctx, user.ID, map[string]interface{}{"is_active": false}); err != nil {
+		return helpers.ErrInternalServer("Failed to deactivate user").Wrap(err)
+	}
+	if err := s.authRepo.DeleteSessionsByUserID(ctx, user.ID); err != nil {
+		return helpers.ErrInternalServer("Failed to revoke sessions").Wrap(err)
+	}
+	wasActive := user.IsActive
+	user.IsActive = false
+
+	if wasActive {
+		s.publish(ctx, constants.EventUserDeactivated, user, nil)
+	}
+	return nil
+}
+
+// AssignRole adds a role to the user. It takes effect on the user's next
+// login or token refresh.
+func (s *UserLifecycleService) AssignRole(ctx context.Context, user *models.User, roleName string) error {
+	role, err := s.findRole(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	// The repository also keeps the legacy role column in sync for older
+	// token consumers
+	if err := s.rbacRepo.AssignRole(ctx, user.ID, role.ID); err != nil {
+		return helpers.ErrInternalServer("Failed to assign role").Wrap(err)
+	}
+	user.Role = role.Name
+
+	s.publish(ctx, constants.EventUserRoleAssigned, user, map[string]interface{}{"role": role.Name})
+	return nil
+}
+
+// AssignRoleWith adds a role to the user through grant, which stores the
+// assignment in one transaction with another change, e.g. approving a seller
+// application. Errors of grant are returned unchanged; user.role_assigned is
+// only published once grant succeeded.
+func (s *UserLifecycleService) AssignRoleWith(ctx context.Context, user *models.User, roleName string, grant func(ctx context.Context, roleID int) error) error {
+	role, err := s.findRole(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	if err := grant(ctx, role.ID); err != nil {
+		return err
+	}
+	user.Role = role.Name
+
+	s.publish(ctx, constants.EventUserRoleAssigned, user, map[string]interface{}{"role": role.Name})
+	return nil
+}
+
+// RevokeRole removes a role from the user
+func (s *UserLifecycleService) RevokeRole(ctx context.Context, user *models.User, roleName string) error {
+	role, err := s.findRole(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	if err := s.rbacRepo.RemoveRole(ctx, user.ID, role.ID); err != nil {
+		return helpers.ErrInternalServer("Failed to revoke role").Wrap(err)
+	}
+
+	s.publish(ctx, constants.EventUserRoleRevoked, user, map[string]interface{}{"role": role.Name})
+	return nil
+}
+
+// SetRole replaces every role of the user with the given role in one
+// transaction
+func (s *UserLifecycleService) SetRole(ctx context.Context, user *models.User, roleName string) error {
+	role, err := s.findRole(ctx, roleName)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.rbacRepo.SetRole(ctx, user.ID, role.ID)
+	if err != nil {
+		return helpers.ErrInternalServer("Failed to set role").Wrap(err)
+	}
+	user.Role = role.Name
+
+	for _, r := range removed {
+		s.publish(ctx, constants.EventUserRoleRevoked, user, map[string]interface{}{"role": r.Name})
+	}
+	s.publish(ctx, constants.EventUserRoleAssigned, user, map[string]interface{}{"role": role.Name})
+	return nil
+}
+
+// ScheduleDeletion marks the account for deletion at the given time
+func (s *UserLifecycleService) ScheduleDeletion(ctx context.Context, user *models.User, at time.Time) error {
+	if err := s.deletionRepo.ScheduleDeletion(ctx, user.ID, at); err != nil {
+		return helpers.ErrInternalServer("Failed to schedule account deletion").Wrap(err)
+	}
+	user.DeletionScheduledAt = &at
+
+	s.publish(ctx, constants.EventUserDeletionScheduled, user, map[string]interface{}{"scheduled_at": at})
+	return nil
+}
+
+// CancelDeletion keeps an account that was scheduled for deletion
+func (s *UserLifecycleService) CancelDeletion(ctx context.Context, user *models.User) error {
+	if user.DeletionScheduledAt == nil {
+		return nil
+	}
+	if err := s.authRepo.CancelDeletion(ctx, user.ID); err != nil {
+		return helpers.ErrInternalServer("Failed to cancel account deletion").Wrap(err)
+	}
+	user.DeletionScheduledAt = nil
+
+	s.publish(ctx, constants.EventUserDeletionCancelled, user, nil)
+	return nil
+}
+
+// Delete anonymizes the account and publishes user.deleted. It fails with
+// repository.ErrDeletionCancelled when the deletion was cancelled meanwhile.
+func (s *UserLifecycleService) Delete(ctx context.Context, user *models.User) error {
+	if err := s.deletionRepo.Anonymize(ctx, user); err != nil {
+		return helpers.ErrInternalServer("Failed to delete user").Wrap(err)
+	}
+
+	s.publish(ctx, constants.EventUserDeleted, user, map[string]interface{}{
+		"pseudonymous_id": pseudonymousID(user.TenantID, user.ID),
+		"deleted_at":      user.AnonymizedAt,
+	})
+	return nil
+}
+
+func (s *UserLifecycleService) findRole(ctx context.Context, name string) (*models.Role, error) {
+	role, err := s.rbacRepo.FindRoleByName(ctx, name)
+	if err != nil {
+		return nil, helpers.ErrInternalServer("Failed to find role").Wrap(err)
+	}
+	if role == nil {
+		return nil, helpers.ErrNotFound("Role not found")
+	}
+	return role, nil
+}
+
+// publish sends a user event. The change is already stored, so a failure is
+// only logged.
+func (s *UserLifecycleService) publish(ctx context.Context, eventType string, user *models.User, payload map[string]interface{}) {
+	if payload == nil {
+		payload = map[string]interface{}{}
+	}
+	payload["user_id"] = user.ID
+
+	if err := s.publisher.Publish(ctx, events.New(eventType, user.TenantID, payload)); err != nil {
+		helpers.Log(ctx).WithError(err).WithField("user_id", user.ID).Errorf("Failed to publish %s event", eventType)
+	}
+}
+
+// pseudonymousID derives a stable identifier other services can keep in
+// place of the user ID, e.g. on order history
+func pseudonymousID(tenantID, userID int) string {
+	return helpers.Sign(strconv.Itoa(tenantID) + ":" + strconv.Itoa(userID))
+}
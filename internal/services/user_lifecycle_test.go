@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/events"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/interfaces"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models"
+	"github.com/ibnuzaman/auth-simple-ecommerce.git/internal/models/dto"
+)
+
+// fakeUserAuthRepo implements the part of IAuthRepository used by Create
+type fakeUserAuthRepo struct {
+	interfaces.IAuthRepository
+	registered []*models.User
+}
+
+func (r *fakeUserAuthRepo) FindByID(_ context.Context, id int) (*models.User, error) {
+	if id < 1 || id > len(r.registered) {
+		return nil, nil
+	}
+	return r.registered[id-1], nil
+}
+
+func (r *fakeUserAuthRepo) RegisterUser(_ context.Context, user *models.User, _ int, _ []models.Consent) error {
+	user.ID = len(r.registered) + 1
+	r.registered = append(r.registered, user)
+	return nil
+}
+
+// fakeRoleRepo implements the part of IRBACRepository used by Create
+type fakeRoleRepo struct {
+	interfaces.IRBACRepository
+}
+
+func (fakeRoleRepo) FindRoleByName(_ context.Context, name string) (*models.Role, error) {
+	return &models.Role{ID: 1, Name: name}, nil
+}
+
+type fakePublisher struct {
+	published []events.Event
+}
+
+func (p *fakePublisher) Publish(_ context.Context, event events.Event) error {
+	p.published = append(p.published, event)
+	return nil
+}
+
+// blockDomainHook rejects sign-ups from one email domain and records the
+// users it saw created
+type blockDomainHook struct {
+	domain  string
+	created []*models.User
+}
+
+func (h *blockDomainHook) BeforeCreate(_ context.Context, user *models.User, _ *dto.CreateUserInput) error {
+	if strings.HasSuffix(user.Email, h.domain) {
+		return helpers.ErrBadRequest("Email domain is not allowed")
+	}
+	return nil
+}
+
+func (h *blockDomainHook) AfterCreate(_ context.Context, user *models.User) {
+	h.created = append(h.created, user)
+}
+
+func newCreateInput(email string) *dto.CreateUserInput {
+	return &dto.CreateUserInput{
+		Username:    "budi",
+		Email:       email,
+		PhoneNumber: "081234567890",
+		FullName:    "Budi",
+		Password:    "secret123",
+		Role:        "customer",
+	}
+}
+
+func TestCreateRunsHooks(t *testing.T) {
+	authRepo := &fakeUserAuthRepo{}
+	publisher := &fakePublisher{}
+	hook := &blockDomainHook{domain: "@blocked.example"}
+	lifecycle := NewUserLifecycleService(authRepo, fakeRoleRepo{}, nil, publisher, hook)
+	tenant := &models.Tenant{ID: 1, PasswordMinLength: 8}
+
+	_, err := lifecycle.Create(context.Background(), tenant, newCreateInput("budi@blocked.example"))
+	var appErr *helpers.AppError
+	if !errors.As(err, &appErr) || appErr.Message != "Email domain is not allowed" {
+		t.Fatalf("Create() error = %v, want the hook's error", err)
+	}
+	if len(authRepo.registered) != 0 || len(publisher.published) != 0 || len(hook.created) != 0 {
+		t.Fatal("user rejected by BeforeCreate was stored or announced")
+	}
+
+	user, err := lifecycle.Create(context.Background(), tenant, newCreateInput("budi@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(authRepo.registered) != 1 || len(hook.created) != 1 || hook.created[0] != user || user.ID == 0 {
+		t.Errorf("AfterCreate got %v, want the stored user", hook.created)
+	}
+}
@@ -0,0 +1,32 @@
+package sms
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Message is a text message to a phone number in E.164 format
+type Message struct {
+	To   string
+	Body string
+}
+
+// LogSender writes text messages to the log instead of sending them. It is
+// used until an SMS gateway is configured, e.g. in local development.
+type LogSender struct {
+	logger *logrus.Logger
+}
+
+func NewLogSender(logger *logrus.Logger) *LogSender {
+	return &LogSender{logger: logger}
+}
+
+// Send logs the message
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"to":   msg.To,
+		"body": msg.Body,
+	}).Info("sms not sent, no SMS gateway is configured")
+	return nil
+}
@@ -0,0 +1,56 @@
+// Package worker runs background jobs such as account deletion and data
+// export generation.
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// JobFunc processes one batch of work and reports how many items it handled
+type JobFunc func(ctx context.Context) (int, error)
+
+// Worker runs a job every interval
+type Worker struct {
+	name     string
+	interval time.Duration
+	job      JobFunc
+	logger   *logrus.Logger
+}
+
+func New(name string, interval time.Duration, job JobFunc, logger *logrus.Logger) *Worker {
+	return &Worker{
+		name:     name,
+		interval: interval,
+		job:      job,
+		logger:   logger,
+	}
+}
+
+// Run runs the job immediately and then every interval until ctx is cancelled
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) runOnce(ctx context.Context) {
+	processed, err := w.job(ctx)
+	if err != nil {
+		w.logger.WithError(err).WithField("worker", w.name).Error("Background job failed")
+	}
+	if processed > 0 {
+		w.logger.WithFields(logrus.Fields{"worker": w.name, "count": processed}).Info("Background job processed items")
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/cmd"
 	"github.com/ibnuzaman/auth-simple-ecommerce.git/helpers"
 )
@@ -25,11 +27,7 @@ import (
 // @description Type "Bearer" followed by a space and JWT token.
 
 func main() {
-	helpers.SetupUpConfig()
-
 	helpers.SetupLogger()
 
-	helpers.SetupPostgreSQL()
-
-	cmd.ServeHTTP()
+	os.Exit(cmd.Execute(os.Args[1:]))
 }
@@ -0,0 +1,30 @@
+-- Migration: Users and sessions
+-- Created: 2025-10-28
+
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR(20) NOT NULL,
+    email VARCHAR(100) NOT NULL,
+    phone_number VARCHAR(15) NOT NULL,
+    full_name VARCHAR(100) NOT NULL,
+    address TEXT,
+    dob DATE,
+    password VARCHAR(255) NOT NULL,
+    role VARCHAR(10) NOT NULL DEFAULT 'user',
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_users_username ON users(username);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_users_email ON users(email);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_users_phone ON users(phone_number);
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    user_id INT,
+    token TEXT,
+    refresh_token TEXT,
+    token_expired TIMESTAMPTZ,
+    refresh_token_expired TIMESTAMPTZ
+);
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_user_sessions_token_expired;
+DROP INDEX IF EXISTS idx_user_sessions_refresh_token;
+DROP INDEX IF EXISTS idx_user_sessions_token;
+DROP INDEX IF EXISTS idx_user_sessions_user_id;
+
+DROP INDEX IF EXISTS idx_users_is_active;
+DROP INDEX IF EXISTS idx_users_email_verified;
+DROP INDEX IF EXISTS idx_users_reset_token;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS is_active,
+DROP COLUMN IF EXISTS email_verified,
+DROP COLUMN IF EXISTS email_verification_token,
+DROP COLUMN IF EXISTS reset_password_expiry,
+DROP COLUMN IF EXISTS reset_password_token;
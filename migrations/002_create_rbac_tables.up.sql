@@ -0,0 +1,84 @@
+-- Migration: Roles, permissions and user role assignments
+-- Created: 2025-11-03
+
+CREATE TABLE IF NOT EXISTS roles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(30) NOT NULL,
+    description VARCHAR(255),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_roles_name ON roles(name);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(60) NOT NULL,
+    description VARCHAR(255),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_permissions_name ON permissions(name);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id INT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id INT NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id INT NOT NULL,
+    role_id INT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (user_id, role_id)
+);
+CREATE INDEX IF NOT EXISTS idx_user_roles_role_id ON user_roles(role_id);
+
+-- Widen the legacy role column and normalize old role names
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(30);
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'customer';
+UPDATE users SET role = 'customer' WHERE role IN ('user', 'Customer');
+
+-- Seed roles
+INSERT INTO roles (name, description) VALUES
+    ('customer', 'Marketplace buyer'),
+    ('seller', 'Store owner'),
+    ('support', 'Customer support agent'),
+    ('admin', 'Platform administrator'),
+    ('superadmin', 'Unrestricted access')
+ON CONFLICT (name) DO NOTHING;
+
+-- Seed permissions
+INSERT INTO permissions (name) VALUES
+    ('*'),
+    ('profile:read'), ('profile:write'),
+    ('users:read'), ('users:write'), ('users:delete'),
+    ('roles:manage'),
+    ('orders:read'), ('orders:write'), ('orders:refund'),
+    ('stores:manage'), ('products:write'),
+    ('pii:read'), ('pii:export')
+ON CONFLICT (name) DO NOTHING;
+
+-- Seed role permission mappings
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON (r.name, p.name) IN (
+    ('customer', 'profile:read'), ('customer', 'profile:write'),
+    ('customer', 'orders:read'), ('customer', 'orders:write'),
+    ('seller', 'profile:read'), ('seller', 'profile:write'),
+    ('seller', 'orders:read'), ('seller', 'orders:write'),
+    ('seller', 'stores:manage'), ('seller', 'products:write'),
+    ('support', 'profile:read'), ('support', 'users:read'),
+    ('support', 'orders:read'), ('support', 'orders:refund'), ('support', 'pii:read'),
+    ('admin', 'profile:read'), ('admin', 'profile:write'),
+    ('admin', 'users:read'), ('admin', 'users:write'), ('admin', 'users:delete'),
+    ('admin', 'roles:manage'),
+    ('admin', 'orders:read'), ('admin', 'orders:write'), ('admin', 'orders:refund'),
+    ('admin', 'stores:manage'), ('admin', 'products:write'),
+    ('admin', 'pii:read'), ('admin', 'pii:export'),
+    ('superadmin', '*')
+)
+ON CONFLICT DO NOTHING;
+
+-- Backfill user_roles from the legacy role column
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id FROM users u JOIN roles r ON r.name = u.role
+ON CONFLICT DO NOTHING;
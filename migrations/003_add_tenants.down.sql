@@ -0,0 +1,14 @@
+-- Fails if two tenants have users with the same email, username or phone
+DROP INDEX IF EXISTS idx_user_sessions_tenant_user;
+
+DROP INDEX IF EXISTS ux_users_phone;
+DROP INDEX IF EXISTS ux_users_username;
+DROP INDEX IF EXISTS ux_users_email;
+CREATE UNIQUE INDEX ux_users_email ON users(email);
+CREATE UNIQUE INDEX ux_users_username ON users(username);
+CREATE UNIQUE INDEX ux_users_phone ON users(phone_number);
+
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,35 @@
+-- Migration: Multi-tenant storefronts
+-- Created: 2025-11-05
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    domain VARCHAR(255),
+    jwt_secret VARCHAR(255),
+    password_min_length INT NOT NULL DEFAULT 8,
+    password_require_mixed BOOLEAN DEFAULT FALSE,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_tenants_slug ON tenants(slug);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_tenants_domain ON tenants(domain);
+
+INSERT INTO tenants (id, slug, name) VALUES (1, 'default', 'Default')
+ON CONFLICT DO NOTHING;
+SELECT setval(pg_get_serial_sequence('tenants', 'id'), GREATEST((SELECT MAX(id) FROM tenants), 1));
+
+-- Existing users and sessions belong to the default tenant
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id INT NOT NULL DEFAULT 1;
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS tenant_id INT NOT NULL DEFAULT 1;
+
+-- Uniqueness is now per tenant
+DROP INDEX IF EXISTS ux_users_email;
+DROP INDEX IF EXISTS ux_users_username;
+DROP INDEX IF EXISTS ux_users_phone;
+CREATE UNIQUE INDEX ux_users_email ON users(tenant_id, email);
+CREATE UNIQUE INDEX ux_users_username ON users(tenant_id, username);
+CREATE UNIQUE INDEX ux_users_phone ON users(tenant_id, phone_number);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_tenant_user ON user_sessions(tenant_id, user_id);
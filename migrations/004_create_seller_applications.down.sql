@@ -0,0 +1,5 @@
+DELETE FROM role_permissions WHERE permission_id IN (SELECT id FROM permissions WHERE name = 'sellers:review');
+DELETE FROM permissions WHERE name = 'sellers:review';
+
+DROP TABLE IF EXISTS seller_documents;
+DROP TABLE IF EXISTS seller_applications;
@@ -0,0 +1,36 @@
+-- Migration: Seller onboarding with KYC documents
+-- Created: 2025-11-07
+
+CREATE TABLE IF NOT EXISTS seller_applications (
+    id SERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL,
+    user_id INT NOT NULL,
+    store_name VARCHAR(100) NOT NULL,
+    nik VARCHAR(16) NOT NULL,
+    npwp VARCHAR(16) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reviewer_id INT,
+    reviewer_notes TEXT,
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_seller_applications_tenant_status ON seller_applications(tenant_id, status);
+CREATE INDEX IF NOT EXISTS idx_seller_applications_user_id ON seller_applications(user_id);
+
+CREATE TABLE IF NOT EXISTS seller_documents (
+    id SERIAL PRIMARY KEY,
+    application_id INT NOT NULL REFERENCES seller_applications(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    blob_key VARCHAR(255) NOT NULL,
+    file_name VARCHAR(255),
+    content_type VARCHAR(100),
+    size BIGINT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_seller_documents_application_id ON seller_documents(application_id);
+
+INSERT INTO permissions (name) VALUES ('sellers:review') ON CONFLICT (name) DO NOTHING;
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p WHERE r.name = 'admin' AND p.name = 'sellers:review'
+ON CONFLICT DO NOTHING;
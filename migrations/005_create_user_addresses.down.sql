@@ -0,0 +1,2 @@
+-- users.address still holds the legacy free-text address
+DROP TABLE IF EXISTS user_addresses;
@@ -0,0 +1,30 @@
+-- Migration: Structured address book
+-- Created: 2025-11-10
+
+CREATE TABLE IF NOT EXISTS user_addresses (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    label VARCHAR(50) NOT NULL,
+    recipient_name VARCHAR(100) NOT NULL,
+    phone_number VARCHAR(20) NOT NULL,
+    address_line TEXT NOT NULL,
+    province VARCHAR(100),
+    city VARCHAR(100),
+    district VARCHAR(100),
+    postal_code VARCHAR(10),
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    is_default_shipping BOOLEAN DEFAULT FALSE,
+    is_default_billing BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_user_addresses_user_id ON user_addresses(user_id);
+
+-- Copy the legacy free-text address into the address book. Province, city,
+-- district and postal code are unknown and must be completed by the user.
+INSERT INTO user_addresses (user_id, label, recipient_name, phone_number, address_line, is_default_shipping, is_default_billing)
+SELECT u.id, 'Alamat', u.full_name, u.phone_number, u.address, TRUE, TRUE
+FROM users u
+WHERE COALESCE(TRIM(u.address), '') <> ''
+  AND NOT EXISTS (SELECT 1 FROM user_addresses a WHERE a.user_id = u.id);
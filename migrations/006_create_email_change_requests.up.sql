@@ -0,0 +1,20 @@
+-- Migration: Email change requests
+-- Created: 2025-11-12
+
+CREATE TABLE IF NOT EXISTS email_change_requests (
+    id SERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL,
+    user_id INT NOT NULL,
+    old_email VARCHAR(100) NOT NULL,
+    new_email VARCHAR(100) NOT NULL,
+    confirm_token_hash VARCHAR(64) NOT NULL,
+    cancel_token_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMPTZ NOT NULL,
+    confirmed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_email_change_requests_user_id ON email_change_requests(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_email_change_requests_confirm ON email_change_requests(confirm_token_hash);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_email_change_requests_cancel ON email_change_requests(cancel_token_hash);
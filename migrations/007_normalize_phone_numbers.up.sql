@@ -0,0 +1,76 @@
+-- Migration: Normalize phone numbers to E.164
+-- Created: 2025-11-14
+--
+-- 0812..., 62812... and +62812... used to be stored as different numbers.
+-- Rows are rewritten to +62812... unless that would make two accounts of the
+-- same tenant share a number. Those collisions are left untouched and listed in
+-- phone_normalization_conflicts for manual review:
+--
+--   SELECT * FROM phone_normalization_conflicts WHERE resolved_at IS NULL;
+
+ALTER TABLE users ALTER COLUMN phone_number TYPE VARCHAR(16);
+
+CREATE TABLE IF NOT EXISTS phone_normalization_conflicts (
+    id SERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL,
+    normalized_phone VARCHAR(16) NOT NULL,
+    user_ids INT[] NOT NULL,
+    phone_numbers TEXT[] NOT NULL,
+    detected_at TIMESTAMPTZ DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE OR REPLACE FUNCTION normalize_phone(phone TEXT) RETURNS TEXT AS $$
+DECLARE
+    p TEXT := regexp_replace(COALESCE(phone, ''), '[\s\-\.\(\)]', '', 'g');
+BEGIN
+    IF p LIKE '+%' THEN
+        RETURN p;
+    ELSIF p LIKE '00%' THEN
+        RETURN '+' || substr(p, 3);
+    ELSIF p LIKE '0%' THEN
+        RETURN '+62' || substr(p, 2);
+    ELSIF p LIKE '62%' THEN
+        RETURN '+' || p;
+    END IF;
+    RETURN p;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- Report numbers that normalize to the same value within a tenant
+INSERT INTO phone_normalization_conflicts (tenant_id, normalized_phone, user_ids, phone_numbers)
+SELECT tenant_id, normalize_phone(phone_number), array_agg(id ORDER BY id), array_agg(phone_number ORDER BY id)
+FROM users
+GROUP BY tenant_id, normalize_phone(phone_number)
+HAVING COUNT(*) > 1
+   AND NOT EXISTS (
+       SELECT 1 FROM phone_normalization_conflicts c
+       WHERE c.tenant_id = users.tenant_id AND c.normalized_phone = normalize_phone(users.phone_number)
+   );
+
+-- Normalize every number that does not collide
+UPDATE users u
+SET phone_number = normalize_phone(u.phone_number)
+WHERE u.phone_number <> normalize_phone(u.phone_number)
+  AND length(normalize_phone(u.phone_number)) <= 16
+  AND NOT EXISTS (
+      SELECT 1 FROM phone_normalization_conflicts c
+      WHERE c.tenant_id = u.tenant_id AND c.normalized_phone = normalize_phone(u.phone_number)
+  );
+
+UPDATE user_addresses
+SET phone_number = normalize_phone(phone_number)
+WHERE phone_number <> normalize_phone(phone_number)
+  AND length(normalize_phone(phone_number)) <= 20;
+
+DO $$
+DECLARE
+    conflicts INT;
+BEGIN
+    SELECT COUNT(*) INTO conflicts FROM phone_normalization_conflicts WHERE resolved_at IS NULL;
+    IF conflicts > 0 THEN
+        RAISE NOTICE '% phone numbers collide after normalization, see phone_normalization_conflicts', conflicts;
+    END IF;
+END $$;
+
+DROP FUNCTION normalize_phone(TEXT);
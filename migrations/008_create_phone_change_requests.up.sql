@@ -0,0 +1,18 @@
+-- Migration: Phone number change requests
+-- Created: 2025-11-14
+
+CREATE TABLE IF NOT EXISTS phone_change_requests (
+    id SERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL,
+    user_id INT NOT NULL,
+    old_phone VARCHAR(16) NOT NULL,
+    new_phone VARCHAR(16) NOT NULL,
+    otp_hash VARCHAR(64) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMPTZ NOT NULL,
+    confirmed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_phone_change_requests_user_id ON phone_change_requests(user_id);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS identity_collisions;
+
+DROP INDEX IF EXISTS ux_users_username_canonical;
+DROP INDEX IF EXISTS ux_users_email_canonical;
+
+ALTER TABLE users DROP COLUMN IF EXISTS username_canonical;
+ALTER TABLE users DROP COLUMN IF EXISTS email_canonical;
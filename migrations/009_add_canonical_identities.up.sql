@@ -0,0 +1,30 @@
+-- Migration: Case-insensitive email and username uniqueness
+-- Created: 2025-11-17
+--
+-- email_canonical is the NFKC, case folded email and username_canonical the
+-- confusable-safe skeleton of the username. Both are computed by the
+-- application (helpers.CanonicalEmail / helpers.CanonicalUsername). Existing
+-- rows are backfilled by `user backfill-identities`, the server refuses to
+-- start until it ran. Rows that collide with an older account are left NULL
+-- and reported:
+--
+--   SELECT * FROM identity_collisions WHERE resolved_at IS NULL;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_canonical VARCHAR(100);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS username_canonical VARCHAR(80);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_users_email_canonical ON users(tenant_id, email_canonical);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_users_username_canonical ON users(tenant_id, username_canonical);
+
+CREATE TABLE IF NOT EXISTS identity_collisions (
+    id SERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL,
+    field VARCHAR(20) NOT NULL,
+    canonical_value VARCHAR(100) NOT NULL,
+    user_id INT NOT NULL,
+    conflicting_user_id INT NOT NULL,
+    detected_at TIMESTAMPTZ DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_identity_collisions_user_field ON identity_collisions(user_id, field);
+
@@ -0,0 +1,2 @@
+-- Archives in the blob store are not removed
+DROP TABLE IF EXISTS data_exports;
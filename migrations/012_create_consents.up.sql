@@ -0,0 +1,38 @@
+-- Migration: Legal documents and user consents
+-- Created: 2025-11-22
+
+CREATE TABLE IF NOT EXISTS legal_documents (
+    id SERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL,
+    type VARCHAR(30) NOT NULL,
+    version VARCHAR(20) NOT NULL,
+    title VARCHAR(150) NOT NULL,
+    url VARCHAR(255) NOT NULL,
+    published_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_legal_documents_version ON legal_documents(tenant_id, type, version);
+
+CREATE TABLE IF NOT EXISTS consents (
+    id SERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL,
+    user_id INT NOT NULL,
+    type VARCHAR(30) NOT NULL,
+    document_id INT REFERENCES legal_documents(id),
+    document_version VARCHAR(20),
+    granted BOOLEAN NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(255),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_consents_user_type ON consents(user_id, type);
+CREATE INDEX IF NOT EXISTS idx_consents_created_at ON consents(created_at);
+
+INSERT INTO legal_documents (tenant_id, type, version, title, url, published_at)
+SELECT t.id, d.type, '1.0', d.title, d.url, NOW()
+FROM tenants t
+CROSS JOIN (VALUES
+    ('terms_of_service', 'Terms of Service', 'http://localhost:3000/legal/terms'),
+    ('privacy_policy', 'Privacy Policy', 'http://localhost:3000/legal/privacy')
+) AS d(type, title, url)
+ON CONFLICT (tenant_id, type, version) DO NOTHING;
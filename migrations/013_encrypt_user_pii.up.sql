@@ -0,0 +1,16 @@
+-- Migration: Encrypt user personal data
+-- Created: 2025-11-23
+--
+-- phone_number, address and dob hold AES-GCM ciphertext written by the
+-- application. Existing plaintext values stay readable and are encrypted by
+-- the pii_key_rotation worker, which also fills phone_number_index.
+-- ux_users_phone is kept until every row has a blind index, see
+-- 017_drop_plaintext_phone_index.
+
+ALTER TABLE users ALTER COLUMN phone_number TYPE TEXT;
+ALTER TABLE users ALTER COLUMN address TYPE TEXT;
+ALTER TABLE users ALTER COLUMN dob TYPE TEXT USING to_char(dob, 'YYYY-MM-DD');
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_number_index VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_users_phone_number_index ON users(tenant_id, phone_number_index);
@@ -0,0 +1,7 @@
+-- Migration: Add the preferred language of users
+-- Created: 2025-11-30
+--
+-- NULL or empty means no preference, messages then follow Accept-Language
+-- or the default locale.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(10);
@@ -0,0 +1,22 @@
+-- Migration: Idempotency keys of state-changing requests
+-- Created: 2025-12-02
+--
+-- A key is scoped to the tenant, the request path and a hash of the
+-- Authorization header. response_body is encrypted, it may carry tokens.
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id SERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT 0,
+    key VARCHAR(255) NOT NULL,
+    scope VARCHAR(512) NOT NULL,
+    principal VARCHAR(64) NOT NULL DEFAULT '',
+    request_hash CHAR(64) NOT NULL,
+    status_code INT,
+    content_type VARCHAR(255),
+    response_body TEXT,
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_idempotency_keys_key ON idempotency_keys(tenant_id, scope, principal, key);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,10 @@
+-- Migration: Permissions for legal documents and consent exports
+-- Created: 2025-12-03
+--
+-- Default role permissions are seeded by migrations only, so mappings changed
+-- through the admin API are never restored.
+
+INSERT INTO permissions (name) VALUES ('legal:manage'), ('consents:read') ON CONFLICT (name) DO NOTHING;
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p WHERE r.name = 'admin' AND p.name IN ('legal:manage', 'consents:read')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1 @@
+CREATE UNIQUE INDEX IF NOT EXISTS ux_users_phone ON users(tenant_id, phone_number);
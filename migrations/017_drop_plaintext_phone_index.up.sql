@@ -0,0 +1,22 @@
+-- Migration: Drop the plaintext phone number index
+-- Created: 2025-12-04
+--
+-- ux_users_phone guarded phone numbers until phone_number_index was filled.
+-- Users from before 013 get their blind index from `keys rotate`, this
+-- migration refuses to run until it has. Upgrade order:
+--
+--   go run main.go migrate up     # applies up to 016, then fails here
+--   go run main.go keys rotate
+--   go run main.go migrate up
+
+DO $$
+DECLARE
+    missing BIGINT;
+BEGIN
+    SELECT COUNT(*) INTO missing FROM users WHERE phone_number_index IS NULL;
+    IF missing > 0 THEN
+        RAISE EXCEPTION '% users have no phone_number_index, run `go run main.go keys rotate` with the server''s PII keys, then migrate again', missing;
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS ux_users_phone;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_seller_documents_deleted_at;
+ALTER TABLE seller_documents DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,8 @@
+-- Migration: Mark seller documents of deleted accounts for removal
+-- Created: 2025-12-05
+--
+-- Account deletion sets deleted_at, the seller_document_cleanup worker then
+-- removes the file from the blob store and the row.
+
+ALTER TABLE seller_documents ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_seller_documents_deleted_at ON seller_documents(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,4 @@
+-- The previous skeleton cannot be computed in SQL, clear it so
+-- `user backfill-identities` of the previous release recomputes it
+UPDATE users SET username_canonical = NULL;
+DELETE FROM identity_collisions WHERE field = 'username' AND resolved_at IS NULL;
@@ -0,0 +1,20 @@
+-- Migration: Only fold 0/o and 1/l in canonical usernames
+-- Created: 2025-12-08
+--
+-- helpers.CanonicalUsername no longer folds i/l, rn/m, vv/w and non-Latin
+-- look-alikes. For ASCII usernames the new skeleton is the lower case
+-- username with 0 and 1 read as o and l, so it is recomputed here. The new
+-- skeleton is finer than the old one, so recomputed values stay unique.
+--
+-- Other usernames and accounts with an open username collision are cleared
+-- and handed back to `user backfill-identities`, which re-reports the ones
+-- that still collide. The server refuses to start until it ran.
+
+UPDATE users
+SET username_canonical = translate(lower(username), '01', 'ol')
+WHERE username_canonical IS NOT NULL AND username ~ '^[A-Za-z0-9_]+$';
+
+UPDATE users SET username_canonical = NULL
+WHERE username_canonical IS NOT NULL AND username !~ '^[A-Za-z0-9_]+$';
+
+DELETE FROM identity_collisions WHERE field = 'username' AND resolved_at IS NULL;
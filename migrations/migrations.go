@@ -0,0 +1,9 @@
+// Package migrations embeds the versioned SQL migrations. Each version has a
+// NNN_name.up.sql file and, unless it is irreversible, a NNN_name.down.sql
+// file.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS